@@ -3,6 +3,7 @@ package fastly
 import (
 	"fmt"
 	"sort"
+	"strconv"
 	"time"
 )
 
@@ -34,6 +35,46 @@ func (s entriesById) Less(i, j int) bool {
 type ListACLEntriesInput struct {
 	ServiceID string
 	ACLID     string
+
+	// Optional fields
+
+	// Page is the page index for pagination. When zero, all entries are
+	// returned in a single response.
+	Page int
+
+	// PerPage is the number of entries per page.
+	PerPage int
+
+	// Sort is the field to sort by, e.g. "created". When empty and Page is
+	// zero, the results are sorted by ID.
+	Sort string
+
+	// Direction is the sort direction, either "ascend" or "descend".
+	Direction string
+}
+
+// formatFilters converts user input into query parameters for filtering.
+func (i *ListACLEntriesInput) formatFilters() map[string]string {
+	result := map[string]string{}
+	pairings := map[string]interface{}{
+		"page":      i.Page,
+		"per_page":  i.PerPage,
+		"sort":      i.Sort,
+		"direction": i.Direction,
+	}
+	for key, value := range pairings {
+		switch v := value.(type) {
+		case int:
+			if v != 0 {
+				result[key] = strconv.Itoa(v)
+			}
+		case string:
+			if v != "" {
+				result[key] = v
+			}
+		}
+	}
+	return result
 }
 
 // ListACLEntries return a list of entries for an ACL
@@ -48,7 +89,9 @@ func (c *Client) ListACLEntries(i *ListACLEntriesInput) ([]*ACLEntry, error) {
 
 	path := fmt.Sprintf("/service/%s/acl/%s/entries", i.ServiceID, i.ACLID)
 
-	resp, err := c.Get(path, nil)
+	resp, err := c.Get(path, &RequestOptions{
+		Params: i.formatFilters(),
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -58,7 +101,9 @@ func (c *Client) ListACLEntries(i *ListACLEntriesInput) ([]*ACLEntry, error) {
 		return nil, err
 	}
 
-	sort.Stable(entriesById(es))
+	if i.Page == 0 && i.Sort == "" {
+		sort.Stable(entriesById(es))
+	}
 
 	return es, nil
 }
@@ -241,29 +286,42 @@ type BatchACLEntry struct {
 	Comment   *string        `json:"comment,omitempty"`
 }
 
+// BatchModifyACLEntries applies a batch of operations to the entries of an
+// ACL.
 func (c *Client) BatchModifyACLEntries(i *BatchModifyACLEntriesInput) error {
+	_, err := c.BatchModifyACLEntriesWithResult(i)
+	return err
+}
+
+// BatchModifyACLEntriesWithResult is like BatchModifyACLEntries, but also
+// returns the result of the batch. When the API rejects the batch, the result
+// is returned along with the error, listing the failed operations it
+// reported.
+func (c *Client) BatchModifyACLEntriesWithResult(i *BatchModifyACLEntriesInput) (*BatchModifyResult, error) {
 	if i.ServiceID == "" {
-		return ErrMissingServiceID
+		return nil, ErrMissingServiceID
 	}
 
 	if i.ACLID == "" {
-		return ErrMissingACLID
+		return nil, ErrMissingACLID
 	}
 
 	if len(i.Entries) > BatchModifyMaximumOperations {
-		return ErrMaxExceededEntries
+		return nil, ErrMaxExceededEntries
 	}
 
 	path := fmt.Sprintf("/service/%s/acl/%s/entries", i.ServiceID, i.ACLID)
 	resp, err := c.PatchJSON(path, i, nil)
 	if err != nil {
-		return err
+		return batchModifyErrorResult(err), err
 	}
 
-	var batchModifyResult map[string]string
-	if err := decodeBodyMap(resp.Body, &batchModifyResult); err != nil {
-		return err
+	r, err := decodeBatchModifyResult(resp.Body)
+	if err != nil {
+		return nil, err
 	}
-
-	return nil
+	if !r.OK() {
+		return r, batchModifyError(r, len(i.Entries))
+	}
+	return r, nil
 }
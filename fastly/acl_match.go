@@ -0,0 +1,229 @@
+package fastly
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ACLMatcher evaluates addresses against a list of ACL entries in memory,
+// with the semantics of a Varnish ACL: the most specific entry containing the
+// address decides, and the address matches unless that entry is negated.
+type ACLMatcher struct {
+	entries []*aclMatcherEntry
+}
+
+type aclMatcherEntry struct {
+	network *net.IPNet
+	ones    int
+	entry   *ACLEntry
+}
+
+// NewACLMatcher returns a matcher for the given entries, usually the result of
+// ListACLEntries.
+func NewACLMatcher(entries []*ACLEntry) (*ACLMatcher, error) {
+	m := &ACLMatcher{}
+	for _, e := range entries {
+		n, err := ACLEntryNetwork(e)
+		if err != nil {
+			return nil, fmt.Errorf("ACL entry %q: %s", e.ID, err)
+		}
+		ones, _ := n.Mask.Size()
+		m.entries = append(m.entries, &aclMatcherEntry{network: n, ones: ones, entry: e})
+	}
+
+	// Most specific first. Among entries of the same prefix, the first
+	// listed wins.
+	sort.SliceStable(m.entries, func(a, b int) bool {
+		return m.entries[a].ones > m.entries[b].ones
+	})
+	return m, nil
+}
+
+// Match reports whether ip matches the ACL. It also returns the entry which
+// decided, or nil when no entry contains ip.
+func (m *ACLMatcher) Match(ip net.IP) (bool, *ACLEntry) {
+	if v4 := ip.To4(); v4 != nil {
+		ip = v4
+	}
+	for _, e := range m.entries {
+		if len(e.network.IP) == len(ip) && e.network.Contains(ip) {
+			return !e.entry.Negated, e.entry
+		}
+	}
+	return false, nil
+}
+
+// MatchString is like Match for an address in string form.
+func (m *ACLMatcher) MatchString(s string) (bool, *ACLEntry, error) {
+	ip := net.ParseIP(strings.TrimSpace(s))
+	if ip == nil {
+		return false, nil, fmt.Errorf("invalid IP address %q", s)
+	}
+	matched, e := m.Match(ip)
+	return matched, e, nil
+}
+
+// ACLFormat is a format ACL entries are exported to and imported from.
+type ACLFormat string
+
+const (
+	// ACLFormatText is one entry per line in CIDR notation, prefixed with
+	// "!" when negated and optionally followed by "# comment". Blank lines
+	// and lines starting with "#" are ignored on import.
+	ACLFormatText ACLFormat = "text"
+
+	// ACLFormatCSV is CSV with an "ip,subnet,negated,comment" header.
+	ACLFormatCSV ACLFormat = "csv"
+
+	// ACLFormatJSON is a JSON array of objects with "ip", "subnet",
+	// "negated" and "comment" fields, like the API's.
+	ACLFormatJSON ACLFormat = "json"
+)
+
+// aclCSVHeader is the header row of the CSV format.
+var aclCSVHeader = []string{"ip", "subnet", "negated", "comment"}
+
+// aclJSONEntry is an entry in the JSON format.
+type aclJSONEntry struct {
+	IP      string `json:"ip"`
+	Subnet  *int   `json:"subnet,omitempty"`
+	Negated bool   `json:"negated"`
+	Comment string `json:"comment,omitempty"`
+}
+
+// ExportACLEntries writes entries to w in the given format.
+func ExportACLEntries(w io.Writer, entries []*ACLEntry, format ACLFormat) error {
+	switch format {
+	case ACLFormatText:
+		bw := bufio.NewWriter(w)
+		for _, e := range entries {
+			if e.Negated {
+				bw.WriteString("!")
+			}
+			bw.WriteString(e.IP)
+			if e.Subnet != "" {
+				bw.WriteString("/" + e.Subnet)
+			}
+			if e.Comment != "" {
+				bw.WriteString(" # " + strings.ReplaceAll(e.Comment, "\n", " "))
+			}
+			bw.WriteString("\n")
+		}
+		return bw.Flush()
+
+	case ACLFormatCSV:
+		cw := csv.NewWriter(w)
+		cw.Write(aclCSVHeader)
+		for _, e := range entries {
+			cw.Write([]string{e.IP, e.Subnet, strconv.FormatBool(e.Negated), e.Comment})
+		}
+		cw.Flush()
+		return cw.Error()
+
+	case ACLFormatJSON:
+		out := make([]*aclJSONEntry, len(entries))
+		for n, e := range entries {
+			je := &aclJSONEntry{IP: e.IP, Negated: e.Negated, Comment: e.Comment}
+			if e.Subnet != "" {
+				s, err := strconv.Atoi(e.Subnet)
+				if err != nil {
+					return fmt.Errorf("ACL entry %q: invalid subnet %q", e.ID, e.Subnet)
+				}
+				je.Subnet = &s
+			}
+			out[n] = je
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+	}
+	return fmt.Errorf("unknown ACL format %q", format)
+}
+
+// ImportACLEntries reads entries from r in the given format. Every entry is
+// validated as an address or network, but not normalized.
+func ImportACLEntries(r io.Reader, format ACLFormat) ([]*ACLEntry, error) {
+	var entries []*ACLEntry
+
+	switch format {
+	case ACLFormatText:
+		s := bufio.NewScanner(r)
+		for line := 1; s.Scan(); line++ {
+			text := strings.TrimSpace(s.Text())
+			if text == "" || strings.HasPrefix(text, "#") {
+				continue
+			}
+			e := &ACLEntry{}
+			if n := strings.Index(text, "#"); n >= 0 {
+				e.Comment = strings.TrimSpace(text[n+1:])
+				text = strings.TrimSpace(text[:n])
+			}
+			if strings.HasPrefix(text, "!") {
+				e.Negated = true
+				text = strings.TrimSpace(text[1:])
+			}
+			e.IP = text
+			if n := strings.Index(text, "/"); n >= 0 {
+				e.IP, e.Subnet = text[:n], text[n+1:]
+			}
+			if _, err := ACLEntryNetwork(e); err != nil {
+				return nil, fmt.Errorf("line %d: %s", line, err)
+			}
+			entries = append(entries, e)
+		}
+		if err := s.Err(); err != nil {
+			return nil, err
+		}
+
+	case ACLFormatCSV:
+		records, err := csv.NewReader(r).ReadAll()
+		if err != nil {
+			return nil, err
+		}
+		for n, rec := range records {
+			if n == 0 && len(rec) > 0 && strings.EqualFold(rec[0], aclCSVHeader[0]) {
+				continue
+			}
+			if len(rec) != len(aclCSVHeader) {
+				return nil, fmt.Errorf("line %d: expected %d fields, got %d", n+1, len(aclCSVHeader), len(rec))
+			}
+			e := &ACLEntry{IP: rec[0], Subnet: rec[1], Comment: rec[3]}
+			if rec[2] != "" {
+				if e.Negated, err = strconv.ParseBool(rec[2]); err != nil {
+					return nil, fmt.Errorf("line %d: invalid negated value %q", n+1, rec[2])
+				}
+			}
+			if _, err := ACLEntryNetwork(e); err != nil {
+				return nil, fmt.Errorf("line %d: %s", n+1, err)
+			}
+			entries = append(entries, e)
+		}
+
+	case ACLFormatJSON:
+		var in []*aclJSONEntry
+		if err := json.NewDecoder(r).Decode(&in); err != nil {
+			return nil, err
+		}
+		for n, je := range in {
+			e := &ACLEntry{IP: je.IP, Negated: je.Negated, Comment: je.Comment}
+			if je.Subnet != nil {
+				e.Subnet = strconv.Itoa(*je.Subnet)
+			}
+			if _, err := ACLEntryNetwork(e); err != nil {
+				return nil, fmt.Errorf("entry %d: %s", n, err)
+			}
+			entries = append(entries, e)
+		}
+
+	default:
+		return nil, fmt.Errorf("unknown ACL format %q", format)
+	}
+	return entries, nil
+}
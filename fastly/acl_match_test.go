@@ -0,0 +1,111 @@
+package fastly
+
+import (
+	"bytes"
+	"net"
+	"strings"
+	"testing"
+)
+
+var testACLEntries = []*ACLEntry{
+	{ID: "a", IP: "10.0.0.0", Subnet: "8", Comment: "internal"},
+	{ID: "b", IP: "10.1.0.0", Subnet: "16", Negated: true, Comment: "partners"},
+	{ID: "c", IP: "10.1.2.3"},
+	{ID: "d", IP: "2001:db8::", Subnet: "32"},
+}
+
+func TestACLMatcher(t *testing.T) {
+	t.Parallel()
+
+	m, err := NewACLMatcher(testACLEntries)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		ip      string
+		matched bool
+		entry   string
+	}{
+		{"10.2.3.4", true, "a"},
+		{"10.1.9.9", false, "b"},
+		{"10.1.2.3", true, "c"},
+		{"::ffff:10.2.3.4", true, "a"},
+		{"2001:db8:1::1", true, "d"},
+		{"192.0.2.1", false, ""},
+		{"2001:db9::1", false, ""},
+	}
+	for _, c := range cases {
+		matched, e, err := m.MatchString(c.ip)
+		if err != nil {
+			t.Errorf("%s: %s", c.ip, err)
+			continue
+		}
+		id := ""
+		if e != nil {
+			id = e.ID
+		}
+		if matched != c.matched || id != c.entry {
+			t.Errorf("%s: got %v %q, want %v %q", c.ip, matched, id, c.matched, c.entry)
+		}
+	}
+
+	if _, _, err := m.MatchString("nope"); err == nil {
+		t.Error("expected an error for an invalid address")
+	}
+	if matched, _ := m.Match(net.ParseIP("10.0.0.1")); !matched {
+		t.Error("expected 10.0.0.1 to match")
+	}
+
+	if _, err := NewACLMatcher([]*ACLEntry{{ID: "x", IP: "bad"}}); err == nil {
+		t.Error("expected an error for an invalid entry")
+	}
+}
+
+func TestExportImportACLEntries(t *testing.T) {
+	t.Parallel()
+
+	for _, format := range []ACLFormat{ACLFormatText, ACLFormatCSV, ACLFormatJSON} {
+		var buf bytes.Buffer
+		if err := ExportACLEntries(&buf, testACLEntries, format); err != nil {
+			t.Fatalf("%s: %s", format, err)
+		}
+		got, err := ImportACLEntries(&buf, format)
+		if err != nil {
+			t.Fatalf("%s: %s", format, err)
+		}
+		if len(got) != len(testACLEntries) {
+			t.Fatalf("%s: bad entries: %d", format, len(got))
+		}
+		for n, e := range testACLEntries {
+			g := got[n]
+			if g.IP != e.IP || g.Subnet != e.Subnet || g.Negated != e.Negated || g.Comment != e.Comment {
+				t.Errorf("%s: bad entry %d: %#v", format, n, g)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := ExportACLEntries(&buf, testACLEntries[:2], ACLFormatText); err != nil {
+		t.Fatal(err)
+	}
+	if want := "10.0.0.0/8 # internal\n!10.1.0.0/16 # partners\n"; buf.String() != want {
+		t.Errorf("bad text: %q", buf.String())
+	}
+
+	text := "# blocklist\n\n192.0.2.1\n  ! 198.51.100.0/24  \n"
+	got, err := ImportACLEntries(strings.NewReader(text), ACLFormatText)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].IP != "192.0.2.1" || got[0].Subnet != "" || !got[1].Negated || got[1].Subnet != "24" {
+		t.Errorf("bad entries: %#v, %#v", got[0], got[1])
+	}
+
+	if _, err := ImportACLEntries(strings.NewReader("192.0.2.1\n10.0.0.0/40\n"), ACLFormatText); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("bad error: %v", err)
+	}
+	if _, err := ImportACLEntries(strings.NewReader(""), "xml"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,263 @@
+package fastly
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SyncACLInput is used as input to the SyncACL function.
+type SyncACLInput struct {
+	// ServiceID is the ID of the service (required).
+	ServiceID string
+
+	// ACLID is the ID of the ACL to synchronize (required).
+	ACLID string
+
+	// CIDRs are the desired entries as CIDR strings, such as "192.0.2.0/24"
+	// or "2001:db8::/32". Bare addresses are treated as single hosts.
+	CIDRs []string
+
+	// Networks are desired entries in addition to CIDRs.
+	Networks []*net.IPNet
+
+	// Comment is set on created entries.
+	Comment string
+
+	// DryRun computes the operations without applying them.
+	DryRun bool
+
+	// Progress, if set, is called after each batch is applied with the number
+	// of operations processed so far and the total.
+	Progress func(done, total int)
+}
+
+// SyncACLResult reports the operations computed and applied by SyncACL.
+type SyncACLResult struct {
+	// Networks are the desired entries after normalization.
+	Networks []*net.IPNet
+
+	// Covered are the desired entries dropped because a broader entry
+	// already includes them.
+	Covered []*net.IPNet
+
+	// Operations are the computed operations, in the order they are applied:
+	// deletes, then updates, then creates.
+	Operations []*BatchACLEntry
+
+	// Batches are the operations grouped as sent to the API.
+	Batches [][]*BatchACLEntry
+
+	// Applied is the number of operations successfully applied.
+	Applied int
+
+	// Failed lists the batches which could not be applied.
+	Failed []*SyncBatchError
+}
+
+// SyncACL makes the entries of an ACL match a list of networks. The networks
+// are normalized to their network address, IPv4-mapped IPv6 addresses are
+// treated as IPv4 and networks contained in others are dropped. The current
+// entries are then diffed against the result and the operations applied in
+// batches of at most BatchModifyMaximumOperations.
+//
+// Negated entries matching a desired network are updated to no longer be
+// negated, and all other existing entries are deleted.
+//
+// A failing batch does not stop the remaining ones from being applied. The
+// failures are reported in the result and an error is returned along with it.
+func (c *Client) SyncACL(i *SyncACLInput) (*SyncACLResult, error) {
+	if i.ServiceID == "" {
+		return nil, ErrMissingServiceID
+	}
+
+	if i.ACLID == "" {
+		return nil, ErrMissingACLID
+	}
+
+	nets := make([]*net.IPNet, 0, len(i.CIDRs)+len(i.Networks))
+	for _, s := range i.CIDRs {
+		n, err := ParseACLNetwork(s)
+		if err != nil {
+			return nil, NewFieldError("CIDRs").Message(err.Error())
+		}
+		nets = append(nets, n)
+	}
+	nets = append(nets, i.Networks...)
+
+	result := &SyncACLResult{}
+	result.Networks, result.Covered = normalizeNetworks(nets)
+	if len(result.Networks) > MaximumACLSize {
+		return nil, NewFieldError("CIDRs").Message(fmt.Sprintf("%d entries exceed the maximum ACL size of %d", len(result.Networks), MaximumACLSize))
+	}
+
+	current, err := c.ListACLEntries(&ListACLEntriesInput{
+		ServiceID: i.ServiceID,
+		ACLID:     i.ACLID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ops := diffACLEntries(current, result.Networks, i.Comment)
+	ranges := batchRanges(len(ops), BatchModifyMaximumOperations)
+	result.Operations = ops
+	for _, r := range ranges {
+		result.Batches = append(result.Batches, ops[r.start:r.end:r.end])
+	}
+	if i.DryRun {
+		return result, nil
+	}
+
+	result.Applied, result.Failed = applyBatches(ranges, i.Progress, func(n int) error {
+		return c.BatchModifyACLEntries(&BatchModifyACLEntriesInput{
+			ServiceID: i.ServiceID,
+			ACLID:     i.ACLID,
+			Entries:   result.Batches[n],
+		})
+	})
+
+	if len(result.Failed) > 0 {
+		return result, fmt.Errorf("%d of %d ACL batches failed, first error: %s", len(result.Failed), len(result.Batches), result.Failed[0])
+	}
+	return result, nil
+}
+
+// ParseACLNetwork parses a CIDR string or a bare IP address, which is treated
+// as a single host, into a network normalized to its network address.
+func ParseACLNetwork(s string) (*net.IPNet, error) {
+	s = strings.TrimSpace(s)
+	if !strings.Contains(s, "/") {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid IP address %q", s)
+		}
+		return hostNetwork(ip), nil
+	}
+
+	_, n, err := net.ParseCIDR(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CIDR %q", s)
+	}
+	return normalizeNetwork(n), nil
+}
+
+// ACLEntryNetwork returns the network matched by an ACL entry.
+func ACLEntryNetwork(e *ACLEntry) (*net.IPNet, error) {
+	if e.Subnet == "" {
+		return ParseACLNetwork(e.IP)
+	}
+	return ParseACLNetwork(e.IP + "/" + e.Subnet)
+}
+
+// hostNetwork returns the single host network of ip.
+func hostNetwork(ip net.IP) *net.IPNet {
+	if v4 := ip.To4(); v4 != nil {
+		return &net.IPNet{IP: v4, Mask: net.CIDRMask(32, 32)}
+	}
+	return &net.IPNet{IP: ip.To16(), Mask: net.CIDRMask(128, 128)}
+}
+
+// normalizeNetwork masks n to its network address and turns IPv4-mapped IPv6
+// networks into IPv4 ones.
+func normalizeNetwork(n *net.IPNet) *net.IPNet {
+	ones, bits := n.Mask.Size()
+	if v4 := n.IP.To4(); v4 != nil {
+		if bits == 128 {
+			ones -= 96
+		}
+		if ones < 0 {
+			ones = 0
+		}
+		mask := net.CIDRMask(ones, 32)
+		return &net.IPNet{IP: v4.Mask(mask), Mask: mask}
+	}
+	mask := net.CIDRMask(ones, 128)
+	return &net.IPNet{IP: n.IP.To16().Mask(mask), Mask: mask}
+}
+
+// normalizeNetworks normalizes nets, sorts them by family and address and
+// drops duplicates and networks contained in another one, which are returned
+// separately.
+func normalizeNetworks(nets []*net.IPNet) (kept, covered []*net.IPNet) {
+	ns := make([]*net.IPNet, len(nets))
+	for n, in := range nets {
+		ns[n] = normalizeNetwork(in)
+	}
+	sortNetworks(ns)
+
+	// Networks are either nested or disjoint, and a network sorts before the
+	// ones it contains, so only the last kept network needs checking.
+	for _, n := range ns {
+		if len(kept) > 0 {
+			last := kept[len(kept)-1]
+			if len(last.IP) == len(n.IP) && last.Contains(n.IP) {
+				covered = append(covered, n)
+				continue
+			}
+		}
+		kept = append(kept, n)
+	}
+	return kept, covered
+}
+
+// sortNetworks sorts IPv4 before IPv6 networks, then by address, then
+// broadest first.
+func sortNetworks(ns []*net.IPNet) {
+	sort.SliceStable(ns, func(a, b int) bool {
+		if len(ns[a].IP) != len(ns[b].IP) {
+			return len(ns[a].IP) < len(ns[b].IP)
+		}
+		if c := bytes.Compare(ns[a].IP, ns[b].IP); c != 0 {
+			return c < 0
+		}
+		oa, _ := ns[a].Mask.Size()
+		ob, _ := ns[b].Mask.Size()
+		return oa < ob
+	})
+}
+
+// diffACLEntries returns the operations turning current into the desired
+// networks.
+func diffACLEntries(current []*ACLEntry, desired []*net.IPNet, comment string) []*BatchACLEntry {
+	want := make(map[string]*net.IPNet, len(desired))
+	for _, n := range desired {
+		want[n.String()] = n
+	}
+
+	var deletes, updates, creates []*BatchACLEntry
+	seen := make(map[string]bool, len(current))
+	for _, e := range current {
+		n, err := ACLEntryNetwork(e)
+		key := ""
+		if err == nil {
+			key = n.String()
+		}
+		if _, ok := want[key]; !ok || seen[key] {
+			deletes = append(deletes, &BatchACLEntry{Operation: DeleteBatchOperation, ID: String(e.ID)})
+			continue
+		}
+		seen[key] = true
+		if e.Negated {
+			updates = append(updates, &BatchACLEntry{Operation: UpdateBatchOperation, ID: String(e.ID), Negated: Bool(false)})
+		}
+	}
+
+	for _, n := range desired {
+		if seen[n.String()] {
+			continue
+		}
+		op := &BatchACLEntry{Operation: CreateBatchOperation, IP: String(n.IP.String())}
+		if ones, bits := n.Mask.Size(); ones != bits {
+			op.Subnet = String(strconv.Itoa(ones))
+		}
+		if comment != "" {
+			op.Comment = String(comment)
+		}
+		creates = append(creates, op)
+	}
+	return append(append(deletes, updates...), creates...)
+}
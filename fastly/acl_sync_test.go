@@ -0,0 +1,127 @@
+package fastly
+
+import (
+	"net"
+	"testing"
+)
+
+func TestClient_SyncACL(t *testing.T) {
+	t.Parallel()
+
+	_, extra, _ := net.ParseCIDR("2001:db8:1::/48")
+
+	var err error
+	var r *SyncACLResult
+	record(t, "acl_entries_batch/sync", func(c *Client) {
+		r, err = c.SyncACL(&SyncACLInput{
+			ServiceID: testServiceID,
+			ACLID:     "2mI5c4hlwXTRJHkpBi5XZc",
+			CIDRs: []string{
+				"10.1.2.3/8",
+				"10.20.0.0/16",
+				"192.0.2.0/24",
+				"203.0.113.7",
+				"2001:db8::/32",
+				"10.0.0.0/8",
+			},
+			Networks: []*net.IPNet{extra},
+			Comment:  "blocklist",
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, n := range r.Networks {
+		got = append(got, n.String())
+	}
+	want := []string{"10.0.0.0/8", "192.0.2.0/24", "203.0.113.7/32", "2001:db8::/32"}
+	if len(got) != len(want) {
+		t.Fatalf("bad networks: %v", got)
+	}
+	for n := range want {
+		if got[n] != want[n] {
+			t.Errorf("bad networks: %v", got)
+		}
+	}
+	if len(r.Covered) != 3 {
+		t.Errorf("bad covered: %v", r.Covered)
+	}
+
+	if len(r.Operations) != 4 {
+		t.Fatalf("bad operations: %d", len(r.Operations))
+	}
+	if op := r.Operations[0]; op.Operation != DeleteBatchOperation || *op.ID != "e3" {
+		t.Errorf("bad delete: %#v", op)
+	}
+	if op := r.Operations[1]; op.Operation != UpdateBatchOperation || *op.ID != "e2" || *op.Negated {
+		t.Errorf("bad update: %#v", op)
+	}
+	if op := r.Operations[2]; op.Operation != CreateBatchOperation || *op.IP != "203.0.113.7" || op.Subnet != nil || *op.Comment != "blocklist" {
+		t.Errorf("bad create: %#v", op)
+	}
+	if op := r.Operations[3]; op.Operation != CreateBatchOperation || *op.IP != "2001:db8::" || *op.Subnet != "32" {
+		t.Errorf("bad create: %#v", op)
+	}
+	if r.Applied != 4 || len(r.Failed) != 0 {
+		t.Errorf("bad result: %d applied, %d failed", r.Applied, len(r.Failed))
+	}
+}
+
+func TestClient_SyncACL_validation(t *testing.T) {
+	var err error
+	_, err = testClient.SyncACL(&SyncACLInput{
+		ServiceID: "",
+	})
+	if err != ErrMissingServiceID {
+		t.Errorf("bad error: %s", err)
+	}
+
+	_, err = testClient.SyncACL(&SyncACLInput{
+		ServiceID: "foo",
+		ACLID:     "",
+	})
+	if err != ErrMissingACLID {
+		t.Errorf("bad error: %s", err)
+	}
+
+	_, err = testClient.SyncACL(&SyncACLInput{
+		ServiceID: "foo",
+		ACLID:     "bar",
+		CIDRs:     []string{"10.0.0.0/33"},
+	})
+	if err == nil {
+		t.Error("expected an error for an invalid CIDR")
+	}
+}
+
+func TestParseACLNetwork(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"192.0.2.1":              "192.0.2.1/32",
+		" 192.0.2.77/24 ":        "192.0.2.0/24",
+		"::ffff:192.0.2.1":       "192.0.2.1/32",
+		"::ffff:192.0.2.1/120":   "192.0.2.0/24",
+		"2001:db8::1":            "2001:db8::1/128",
+		"2001:db8:abcd::1234/36": "2001:db8:a000::/36",
+		"0.0.0.0/0":              "0.0.0.0/0",
+	}
+	for in, want := range cases {
+		n, err := ParseACLNetwork(in)
+		if err != nil {
+			t.Errorf("%q: %s", in, err)
+			continue
+		}
+		if n.String() != want {
+			t.Errorf("%q: got %s, want %s", in, n, want)
+		}
+	}
+
+	for _, in := range []string{"", "300.0.0.1", "10.0.0.0/33", "example.com"} {
+		if _, err := ParseACLNetwork(in); err == nil {
+			t.Errorf("%q: expected an error", in)
+		}
+	}
+}
@@ -0,0 +1,322 @@
+package fastly
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// tlsVersions maps the TLS versions accepted by the API to their order.
+var tlsVersions = map[string]uint16{
+	"1":   tls.VersionTLS10,
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// BackendTLSErrors is the list of problems found by ValidateCreateBackendTLS
+// and ValidateUpdateBackendTLS.
+type BackendTLSErrors []*FieldError
+
+// Error fulfills the error interface.
+func (e BackendTLSErrors) Error() string {
+	msgs := make([]string, len(e))
+	for n, err := range e {
+		msgs[n] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// backendTLSConfig holds the TLS related fields of a backend, whichever input
+// they come from.
+type backendTLSConfig struct {
+	Address         string
+	UseSSL          bool
+	SSLCheckCert    bool
+	SSLCACert       string
+	SSLClientCert   string
+	SSLClientKey    string
+	SSLCertHostname string
+	SSLSNIHostname  string
+	MinTLSVersion   string
+	MaxTLSVersion   string
+	SSLCiphers      []string
+
+	// SkipClientCert skips the checks of the client certificate and key,
+	// e.g. when an update leaves them alone and the key, which the API never
+	// returns, is unknown.
+	SkipClientCert bool
+}
+
+// ValidateCreateBackendTLS checks the TLS settings of a CreateBackendInput
+// without calling the API. It parses the PEM encoded certificates and key,
+// checks that the client certificate matches its key, that the CA bundle forms
+// valid chains, that no certificate has expired, that pinned origin
+// certificates cover SSLCertHostname and that MinTLSVersion does not exceed
+// MaxTLSVersion.
+//
+// It returns nil or a BackendTLSErrors listing every problem found.
+func ValidateCreateBackendTLS(i *CreateBackendInput) error {
+	return validateBackendTLS(&backendTLSConfig{
+		Address:         i.Address,
+		UseSSL:          bool(i.UseSSL),
+		SSLCheckCert:    bool(i.SSLCheckCert),
+		SSLCACert:       i.SSLCACert,
+		SSLClientCert:   i.SSLClientCert,
+		SSLClientKey:    i.SSLClientKey,
+		SSLCertHostname: i.SSLCertHostname,
+		SSLSNIHostname:  i.SSLSNIHostname,
+		MinTLSVersion:   i.MinTLSVersion,
+		MaxTLSVersion:   i.MaxTLSVersion,
+		SSLCiphers:      i.SSLCiphers,
+	}, time.Now())
+}
+
+// ValidateUpdateBackendTLS is like ValidateCreateBackendTLS for an
+// UpdateBackendInput. The fields it sets are applied on top of the current
+// backend, which may be nil, so that settings depending on each other are
+// checked together. The client certificate and key are only checked when the
+// update sets one of them, since the API does not return the current key.
+func ValidateUpdateBackendTLS(b *Backend, i *UpdateBackendInput) error {
+	cfg := &backendTLSConfig{}
+	if b != nil {
+		cfg = &backendTLSConfig{
+			Address:         b.Address,
+			UseSSL:          b.UseSSL,
+			SSLCheckCert:    b.SSLCheckCert,
+			SSLCACert:       b.SSLCACert,
+			SSLClientCert:   b.SSLClientCert,
+			SSLClientKey:    b.SSLClientKey,
+			SSLCertHostname: b.SSLCertHostname,
+			SSLSNIHostname:  b.SSLSNIHostname,
+			MinTLSVersion:   b.MinTLSVersion,
+			MaxTLSVersion:   b.MaxTLSVersion,
+			SSLCiphers:      b.SSLCiphers,
+		}
+	}
+
+	setString := func(dst *string, src *string) {
+		if src != nil {
+			*dst = *src
+		}
+	}
+	setBool := func(dst *bool, src *Compatibool) {
+		if src != nil {
+			*dst = bool(*src)
+		}
+	}
+	setString(&cfg.Address, i.Address)
+	setBool(&cfg.UseSSL, i.UseSSL)
+	setBool(&cfg.SSLCheckCert, i.SSLCheckCert)
+	setString(&cfg.SSLCACert, i.SSLCACert)
+	setString(&cfg.SSLClientCert, i.SSLClientCert)
+	setString(&cfg.SSLClientKey, i.SSLClientKey)
+	setString(&cfg.SSLCertHostname, i.SSLCertHostname)
+	setString(&cfg.SSLSNIHostname, i.SSLSNIHostname)
+	setString(&cfg.MinTLSVersion, i.MinTLSVersion)
+	setString(&cfg.MaxTLSVersion, i.MaxTLSVersion)
+	if i.SSLCiphers != nil {
+		cfg.SSLCiphers = i.SSLCiphers
+	}
+	cfg.SkipClientCert = i.SSLClientCert == nil && i.SSLClientKey == nil
+
+	return validateBackendTLS(cfg, time.Now())
+}
+
+// validateBackendTLS runs every check against cfg at the given time.
+func validateBackendTLS(cfg *backendTLSConfig, now time.Time) error {
+	var errs BackendTLSErrors
+	fail := func(field, format string, args ...interface{}) {
+		errs = append(errs, NewFieldError(field).Message(fmt.Sprintf(format, args...)))
+	}
+
+	if !cfg.UseSSL {
+		for _, f := range []struct {
+			field string
+			set   bool
+		}{
+			{"SSLCACert", cfg.SSLCACert != ""},
+			{"SSLClientCert", cfg.SSLClientCert != ""},
+			{"SSLClientKey", cfg.SSLClientKey != ""},
+			{"SSLCertHostname", cfg.SSLCertHostname != ""},
+			{"SSLSNIHostname", cfg.SSLSNIHostname != ""},
+			{"MinTLSVersion", cfg.MinTLSVersion != ""},
+			{"MaxTLSVersion", cfg.MaxTLSVersion != ""},
+			{"SSLCiphers", len(cfg.SSLCiphers) > 0},
+		} {
+			if f.set {
+				fail(f.field, "has no effect unless UseSSL is enabled")
+			}
+		}
+	}
+
+	// TLS versions.
+	minVersion, minOK := tlsVersions[cfg.MinTLSVersion]
+	if cfg.MinTLSVersion != "" && !minOK {
+		fail("MinTLSVersion", "unknown TLS version %q, expected one of 1.0, 1.1, 1.2 or 1.3", cfg.MinTLSVersion)
+	}
+	maxVersion, maxOK := tlsVersions[cfg.MaxTLSVersion]
+	if cfg.MaxTLSVersion != "" && !maxOK {
+		fail("MaxTLSVersion", "unknown TLS version %q, expected one of 1.0, 1.1, 1.2 or 1.3", cfg.MaxTLSVersion)
+	}
+	if minOK && maxOK && minVersion > maxVersion {
+		fail("MinTLSVersion", "%s is greater than MaxTLSVersion %s", cfg.MinTLSVersion, cfg.MaxTLSVersion)
+	}
+
+	for _, c := range cfg.SSLCiphers {
+		if strings.TrimSpace(c) == "" || strings.ContainsAny(c, " \t") {
+			fail("SSLCiphers", "invalid cipher %q", c)
+		}
+	}
+
+	// Hostnames.
+	if cfg.SSLCertHostname != "" && !validHostname(cfg.SSLCertHostname) {
+		fail("SSLCertHostname", "%q is not a valid hostname", cfg.SSLCertHostname)
+	}
+	if cfg.SSLSNIHostname != "" && !validHostname(cfg.SSLSNIHostname) {
+		fail("SSLSNIHostname", "%q is not a valid hostname", cfg.SSLSNIHostname)
+	}
+	if cfg.UseSSL && cfg.SSLCheckCert && cfg.SSLCertHostname == "" && net.ParseIP(cfg.Address) != nil {
+		fail("SSLCertHostname", "is required to verify the certificate of an origin addressed by IP (%s)", cfg.Address)
+	}
+
+	// CA bundle.
+	if cfg.SSLCACert != "" {
+		certs, err := parseCertificates(cfg.SSLCACert)
+		if err != nil {
+			fail("SSLCACert", "%s", err)
+		}
+		for _, c := range certs {
+			if msg := certificateValidity(c, now); msg != "" {
+				fail("SSLCACert", "%s", msg)
+			}
+			if c.IsCA {
+				if issuer := findIssuer(c, certs); issuer != nil {
+					if err := c.CheckSignatureFrom(issuer); err != nil {
+						fail("SSLCACert", "certificate %q is not validly signed by %q: %s", c.Subject.CommonName, issuer.Subject.CommonName, err)
+					}
+				}
+				continue
+			}
+			// A non CA certificate pins the origin's own certificate, which
+			// must then be valid for the name being checked.
+			if cfg.SSLCertHostname != "" {
+				if err := c.VerifyHostname(cfg.SSLCertHostname); err != nil {
+					fail("SSLCertHostname", "not covered by pinned certificate %q: %s", c.Subject.CommonName, err)
+				}
+			}
+		}
+	}
+
+	// Client certificate and key.
+	switch {
+	case cfg.SkipClientCert:
+	case cfg.SSLClientCert != "" && cfg.SSLClientKey == "":
+		fail("SSLClientKey", "is required when SSLClientCert is set")
+	case cfg.SSLClientCert == "" && cfg.SSLClientKey != "":
+		fail("SSLClientCert", "is required when SSLClientKey is set")
+	case cfg.SSLClientCert != "":
+		certs, err := parseCertificates(cfg.SSLClientCert)
+		if err != nil {
+			fail("SSLClientCert", "%s", err)
+		}
+		keyOK := true
+		if block, _ := pem.Decode([]byte(cfg.SSLClientKey)); block == nil || !strings.HasSuffix(block.Type, "PRIVATE KEY") {
+			fail("SSLClientKey", "no PEM encoded private key found")
+			keyOK = false
+		}
+		if len(certs) > 0 {
+			leaf := certs[0]
+			if msg := certificateValidity(leaf, now); msg != "" {
+				fail("SSLClientCert", "%s", msg)
+			}
+			for n, c := range certs[1:] {
+				if err := certs[n].CheckSignatureFrom(c); err != nil {
+					fail("SSLClientCert", "certificate %q is not signed by the next certificate in the chain, %q: %s", certs[n].Subject.CommonName, c.Subject.CommonName, err)
+				}
+			}
+			if keyOK {
+				if _, err := tls.X509KeyPair([]byte(cfg.SSLClientCert), []byte(cfg.SSLClientKey)); err != nil {
+					fail("SSLClientKey", "does not match SSLClientCert: %s", err)
+				}
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// parseCertificates parses every PEM encoded certificate in s.
+func parseCertificates(s string) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	rest := []byte(s)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			return certs, fmt.Errorf("unexpected PEM block %q, expected CERTIFICATE", block.Type)
+		}
+		c, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return certs, fmt.Errorf("invalid certificate: %s", err)
+		}
+		certs = append(certs, c)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no PEM encoded certificate found")
+	}
+	return certs, nil
+}
+
+// certificateValidity returns a message when c is not valid at now.
+func certificateValidity(c *x509.Certificate, now time.Time) string {
+	switch {
+	case now.After(c.NotAfter):
+		return fmt.Sprintf("certificate %q expired on %s", c.Subject.CommonName, c.NotAfter.Format(time.RFC3339))
+	case now.Before(c.NotBefore):
+		return fmt.Sprintf("certificate %q is not valid before %s", c.Subject.CommonName, c.NotBefore.Format(time.RFC3339))
+	}
+	return ""
+}
+
+// findIssuer returns the certificate among certs that issued c, or nil when
+// c is self-signed or its issuer is not included.
+func findIssuer(c *x509.Certificate, certs []*x509.Certificate) *x509.Certificate {
+	for _, o := range certs {
+		if o != c && o.IsCA && string(o.RawSubject) == string(c.RawIssuer) {
+			return o
+		}
+	}
+	return nil
+}
+
+// validHostname reports whether name is a syntactically valid DNS name,
+// optionally with a leading wildcard label.
+func validHostname(name string) bool {
+	name = strings.TrimSuffix(strings.TrimPrefix(name, "*."), ".")
+	if name == "" || len(name) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(name, ".") {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,198 @@
+package fastly
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testTLSCert is a generated certificate and its key, PEM encoded.
+type testTLSCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	PEM  string
+	Key  string
+}
+
+// newTestTLSCert generates a certificate valid between notBefore and
+// notAfter, signed by parent or self-signed when parent is nil.
+func newTestTLSCert(t *testing.T, cn string, isCA bool, parent *testTLSCert, notBefore, notAfter time.Time, dnsNames ...string) *testTLSCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		DNSNames:              dnsNames,
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+	}
+	if isCA {
+		tmpl.KeyUsage = x509.KeyUsageCertSign
+	}
+	signer, signerKey := tmpl, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testTLSCert{
+		cert: cert,
+		key:  key,
+		PEM:  string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		Key:  string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})),
+	}
+}
+
+func TestValidateCreateBackendTLS(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	from, until := now.Add(-time.Hour), now.Add(24*time.Hour)
+
+	root := newTestTLSCert(t, "root", true, nil, from, until)
+	intermediate := newTestTLSCert(t, "intermediate", true, root, from, until)
+	client := newTestTLSCert(t, "client", false, intermediate, from, until)
+	other := newTestTLSCert(t, "other", false, nil, from, until)
+	expired := newTestTLSCert(t, "expired", false, nil, now.Add(-48*time.Hour), now.Add(-24*time.Hour))
+	pinned := newTestTLSCert(t, "origin", false, nil, from, until, "origin.example.com")
+
+	valid := func() *CreateBackendInput {
+		return &CreateBackendInput{
+			Address:         "192.0.2.1",
+			UseSSL:          true,
+			SSLCheckCert:    true,
+			SSLCACert:       root.PEM + intermediate.PEM,
+			SSLClientCert:   client.PEM + intermediate.PEM,
+			SSLClientKey:    client.Key,
+			SSLCertHostname: "origin.example.com",
+			SSLSNIHostname:  "origin.example.com",
+			MinTLSVersion:   "1.2",
+			MaxTLSVersion:   "1.3",
+		}
+	}
+
+	if err := ValidateCreateBackendTLS(valid()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	cases := []struct {
+		name   string
+		modify func(i *CreateBackendInput)
+		field  string
+		msg    string
+	}{
+		{"ssl disabled", func(i *CreateBackendInput) { i.UseSSL = false }, "SSLCACert", "has no effect unless UseSSL"},
+		{"unknown version", func(i *CreateBackendInput) { i.MaxTLSVersion = "1.4" }, "MaxTLSVersion", "unknown TLS version"},
+		{"version order", func(i *CreateBackendInput) { i.MinTLSVersion, i.MaxTLSVersion = "1.3", "1.1" }, "MinTLSVersion", "greater than"},
+		{"invalid cipher", func(i *CreateBackendInput) { i.SSLCiphers = []string{"ECDHE RSA"} }, "SSLCiphers", "invalid cipher"},
+		{"invalid hostname", func(i *CreateBackendInput) { i.SSLSNIHostname = "bad host" }, "SSLSNIHostname", "not a valid hostname"},
+		{"ip without hostname", func(i *CreateBackendInput) { i.SSLCertHostname = "" }, "SSLCertHostname", "origin addressed by IP"},
+		{"bad ca pem", func(i *CreateBackendInput) { i.SSLCACert = "junk" }, "SSLCACert", "no PEM encoded certificate"},
+		{"broken ca chain", func(i *CreateBackendInput) {
+			i.SSLCACert = newTestTLSCert(t, "root", true, nil, from, until).PEM + intermediate.PEM
+		}, "SSLCACert", "not validly signed"},
+		{"expired ca", func(i *CreateBackendInput) { i.SSLCACert = expired.PEM }, "SSLCACert", "expired on"},
+		{"pinned hostname", func(i *CreateBackendInput) {
+			i.SSLCACert = pinned.PEM
+			i.SSLCertHostname = "other.example.com"
+		}, "SSLCertHostname", "not covered by pinned certificate"},
+		{"missing key", func(i *CreateBackendInput) { i.SSLClientKey = "" }, "SSLClientKey", "is required when SSLClientCert"},
+		{"missing cert", func(i *CreateBackendInput) { i.SSLClientCert = "" }, "SSLClientCert", "is required when SSLClientKey"},
+		{"bad key pem", func(i *CreateBackendInput) { i.SSLClientKey = client.PEM }, "SSLClientKey", "no PEM encoded private key"},
+		{"key mismatch", func(i *CreateBackendInput) { i.SSLClientKey = other.Key }, "SSLClientKey", "does not match"},
+		{"broken client chain", func(i *CreateBackendInput) { i.SSLClientCert = client.PEM + root.PEM }, "SSLClientCert", "not signed by the next"},
+		{"expired client", func(i *CreateBackendInput) {
+			i.SSLClientCert = expired.PEM
+			i.SSLClientKey = expired.Key
+		}, "SSLClientCert", "expired on"},
+	}
+
+	for _, c := range cases {
+		i := valid()
+		c.modify(i)
+		err := ValidateCreateBackendTLS(i)
+		errs, ok := err.(BackendTLSErrors)
+		if !ok {
+			t.Errorf("%s: bad error: %v", c.name, err)
+			continue
+		}
+		found := false
+		for _, e := range errs {
+			if e.kind == c.field && strings.Contains(e.message, c.msg) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("%s: expected %s error %q, got %s", c.name, c.field, c.msg, err)
+		}
+	}
+}
+
+func TestValidateUpdateBackendTLS(t *testing.T) {
+	t.Parallel()
+
+	b := &Backend{
+		Address:         "origin.example.com",
+		UseSSL:          true,
+		SSLCheckCert:    true,
+		SSLCertHostname: "origin.example.com",
+		MinTLSVersion:   "1.2",
+	}
+
+	if err := ValidateUpdateBackendTLS(b, &UpdateBackendInput{MaxTLSVersion: String("1.3")}); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	// The current minimum version is checked against the new maximum.
+	err := ValidateUpdateBackendTLS(b, &UpdateBackendInput{MaxTLSVersion: String("1.1")})
+	if err == nil || !strings.Contains(err.Error(), "greater than MaxTLSVersion") {
+		t.Errorf("bad error: %v", err)
+	}
+
+	// Disabling TLS leaves the existing settings without effect.
+	err = ValidateUpdateBackendTLS(b, &UpdateBackendInput{UseSSL: CBool(false)})
+	if err == nil || !strings.Contains(err.Error(), "has no effect unless UseSSL") {
+		t.Errorf("bad error: %v", err)
+	}
+
+	if err := ValidateUpdateBackendTLS(nil, &UpdateBackendInput{}); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	// The API does not return the client key of a backend, so updating
+	// other fields does not require it.
+	now := time.Now()
+	client := newTestTLSCert(t, "client", false, nil, now.Add(-time.Hour), now.Add(time.Hour))
+	b.SSLClientCert = client.PEM
+	if err := ValidateUpdateBackendTLS(b, &UpdateBackendInput{Weight: Uint(50)}); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if err := ValidateUpdateBackendTLS(b, &UpdateBackendInput{SSLClientKey: String(client.Key)}); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	err = ValidateUpdateBackendTLS(b, &UpdateBackendInput{SSLClientCert: String(client.PEM)})
+	if err == nil || !strings.Contains(err.Error(), "SSLClientKey': is required when SSLClientCert is set") {
+		t.Errorf("bad error: %v", err)
+	}
+}
@@ -0,0 +1,324 @@
+package fastly
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// BatchModifyResult is the result of a batch modification of dictionary items
+// or ACL entries.
+type BatchModifyResult struct {
+	// Status is the status reported by the API, "ok" on success.
+	Status string
+
+	// Message is the message reported by the API, if any.
+	Message string
+
+	// Errors are the failures of individual operations, when the API
+	// reports them.
+	Errors []*BatchOperationError
+}
+
+// OK reports whether every operation of the batch was applied.
+func (r *BatchModifyResult) OK() bool {
+	return r.Status == "ok" && len(r.Errors) == 0
+}
+
+// BatchOperationError is the failure of a single operation of a batch.
+type BatchOperationError struct {
+	// Index is the index of the operation in the batch.
+	Index int
+
+	// Message describes the failure.
+	Message string
+}
+
+// Error fulfills the error interface.
+func (e *BatchOperationError) Error() string {
+	return fmt.Sprintf("operation %d: %s", e.Index, e.Message)
+}
+
+// batchModifyResp is the response body of a batch modification.
+type batchModifyResp struct {
+	Status string                   `mapstructure:"status"`
+	Msg    string                   `mapstructure:"msg"`
+	Detail string                   `mapstructure:"detail"`
+	Errors []map[string]interface{} `mapstructure:"errors"`
+}
+
+// decodeBatchModifyResult decodes a batch modification response body.
+func decodeBatchModifyResult(body io.ReadCloser) (*BatchModifyResult, error) {
+	var resp *batchModifyResp
+	if err := decodeBodyMap(body, &resp); err != nil {
+		return nil, err
+	}
+	if resp == nil {
+		return &BatchModifyResult{}, nil
+	}
+
+	r := &BatchModifyResult{Status: resp.Status, Message: resp.Msg}
+	if r.Message == "" {
+		r.Message = resp.Detail
+	}
+	for _, e := range resp.Errors {
+		if oe := newBatchOperationError(e, nil); oe != nil {
+			r.Errors = append(r.Errors, oe)
+		}
+	}
+	return r, nil
+}
+
+// batchModifyErrorResult builds a result from the error of a rejected batch,
+// keeping the errors which identify an operation.
+func batchModifyErrorResult(err error) *BatchModifyResult {
+	r := &BatchModifyResult{Status: "error", Message: err.Error()}
+	if he, ok := err.(*HTTPError); ok {
+		for _, e := range he.Errors {
+			var meta map[string]interface{}
+			if e.Meta != nil {
+				meta = *e.Meta
+			}
+			msg := e.Detail
+			if msg == "" {
+				msg = e.Title
+			}
+			if oe := newBatchOperationError(meta, &msg); oe != nil {
+				r.Errors = append(r.Errors, oe)
+			}
+		}
+	}
+	return r
+}
+
+// newBatchOperationError returns the operation error described by m, or nil
+// when m does not identify an operation. msg overrides the message in m.
+func newBatchOperationError(m map[string]interface{}, msg *string) *BatchOperationError {
+	var index int
+	switch v := m["index"].(type) {
+	case float64:
+		index = int(v)
+	case int:
+		index = v
+	default:
+		return nil
+	}
+
+	e := &BatchOperationError{Index: index}
+	if msg != nil {
+		e.Message = *msg
+	}
+	for _, k := range []string{"detail", "title", "msg", "message"} {
+		if e.Message != "" {
+			break
+		}
+		if s, ok := m[k].(string); ok {
+			e.Message = s
+		}
+	}
+	return e
+}
+
+// batchModifyError returns the error to return alongside a result whose
+// operations did not all succeed.
+func batchModifyError(r *BatchModifyResult, total int) error {
+	if len(r.Errors) > 0 {
+		return fmt.Errorf("%d of %d batch operations failed, first error: %s", len(r.Errors), total, r.Errors[0])
+	}
+	return fmt.Errorf("batch modification failed with status %q: %s", r.Status, r.Message)
+}
+
+// batchRange is the range of operations, from start included to end
+// excluded, sent in one batch.
+type batchRange struct {
+	start, end int
+}
+
+// batchRanges splits n operations into batches of at most size operations.
+func batchRanges(n, size int) []batchRange {
+	var ranges []batchRange
+	for start := 0; start < n; start += size {
+		end := start + size
+		if end > n {
+			end = n
+		}
+		ranges = append(ranges, batchRange{start, end})
+	}
+	return ranges
+}
+
+// applyBatches applies the batches of a synchronization in order, calling
+// apply with the index of each batch. A failing batch does not stop the
+// following ones from being applied. It returns the number of operations
+// applied and the batches which failed. Progress, if set, is called after each
+// batch with the number of operations processed so far and the total.
+func applyBatches(ranges []batchRange, progress func(done, total int), apply func(n int) error) (int, []*SyncBatchError) {
+	total := 0
+	if len(ranges) > 0 {
+		total = ranges[len(ranges)-1].end
+	}
+
+	applied := 0
+	var failed []*SyncBatchError
+	for n, r := range ranges {
+		size := r.end - r.start
+		if err := apply(n); err != nil {
+			failed = append(failed, &SyncBatchError{Batch: n, Operations: size, Err: err})
+		} else {
+			applied += size
+		}
+		if progress != nil {
+			progress(r.end, total)
+		}
+	}
+	return applied, failed
+}
+
+// BatchRetryOptions configures the batch retry helpers.
+type BatchRetryOptions struct {
+	// Attempts is the maximum number of attempts, including the first.
+	// Defaults to 3.
+	Attempts int
+
+	// Delay is the time waited before the first retry. It doubles for each
+	// following retry.
+	Delay time.Duration
+
+	// Transient reports whether a failed operation may succeed when sent
+	// again. Defaults to TransientBatchOperationError.
+	Transient func(e *BatchOperationError) bool
+}
+
+// transientBatchMessages are parts of the messages of operation failures
+// which may not happen again.
+var transientBatchMessages = []string{
+	"timeout",
+	"timed out",
+	"temporarily",
+	"try again",
+	"rate limit",
+	"internal server error",
+	"service unavailable",
+}
+
+// TransientBatchOperationError reports whether the failure of an operation
+// is known to be transient, e.g. a timeout. Other failures, such as an entry
+// which already exists or a value which is too large, fail the same way every
+// time and are not retried.
+func TransientBatchOperationError(e *BatchOperationError) bool {
+	msg := strings.ToLower(e.Message)
+	for _, m := range transientBatchMessages {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// retryBatch sends ops with send, then resends the operations that may
+// succeed on another attempt, until none remain or the attempts are
+// exhausted. A batch rejected with a 5xx or 429 status is resent as a whole,
+// while of the operations reported as failed only the transient ones are. The
+// indexes of the returned result refer to the original operations, and its
+// errors include those of every attempt which were not retried.
+func retryBatch(total int, o *BatchRetryOptions, send func(indexes []int) (*BatchModifyResult, error)) (*BatchModifyResult, error) {
+	attempts, delay, transient := 3, time.Duration(0), TransientBatchOperationError
+	if o != nil {
+		if o.Attempts > 0 {
+			attempts = o.Attempts
+		}
+		delay = o.Delay
+		if o.Transient != nil {
+			transient = o.Transient
+		}
+	}
+
+	indexes := make([]int, total)
+	for n := range indexes {
+		indexes[n] = n
+	}
+
+	var failed []*BatchOperationError
+	var r *BatchModifyResult
+	var err error
+	for attempt := 1; ; attempt++ {
+		r, err = send(indexes)
+		if r == nil {
+			// The input was rejected before being sent.
+			return nil, err
+		}
+
+		// Map the indexes of the attempt back to the original operations.
+		for _, e := range r.Errors {
+			if e.Index >= 0 && e.Index < len(indexes) {
+				e.Index = indexes[e.Index]
+			}
+		}
+		if err == nil || attempt == attempts {
+			break
+		}
+
+		var retry []int
+		var permanent []*BatchOperationError
+		if he, ok := err.(*HTTPError); ok && (he.StatusCode >= 500 || he.StatusCode == http.StatusTooManyRequests) {
+			retry = indexes
+		} else {
+			for _, e := range r.Errors {
+				if transient(e) {
+					retry = append(retry, e.Index)
+				} else {
+					permanent = append(permanent, e)
+				}
+			}
+		}
+		if len(retry) == 0 {
+			break
+		}
+		failed = append(failed, permanent...)
+		indexes = retry
+
+		time.Sleep(delay)
+		delay *= 2
+	}
+
+	if len(failed) > 0 {
+		r.Errors = append(failed, r.Errors...)
+		sort.Slice(r.Errors, func(a, b int) bool {
+			return r.Errors[a].Index < r.Errors[b].Index
+		})
+		if err == nil {
+			err = batchModifyError(r, total)
+		}
+	}
+	return r, err
+}
+
+// RetryBatchModifyDictionaryItems is like
+// BatchModifyDictionaryItemsWithResult, but retries the operations which
+// failed.
+func (c *Client) RetryBatchModifyDictionaryItems(i *BatchModifyDictionaryItemsInput, o *BatchRetryOptions) (*BatchModifyResult, error) {
+	return retryBatch(len(i.Items), o, func(indexes []int) (*BatchModifyResult, error) {
+		in := *i
+		in.Items = make([]*BatchDictionaryItem, len(indexes))
+		for n, idx := range indexes {
+			in.Items[n] = i.Items[idx]
+		}
+		return c.BatchModifyDictionaryItemsWithResult(&in)
+	})
+}
+
+// RetryBatchModifyACLEntries is like BatchModifyACLEntriesWithResult, but
+// retries the operations which failed.
+func (c *Client) RetryBatchModifyACLEntries(i *BatchModifyACLEntriesInput, o *BatchRetryOptions) (*BatchModifyResult, error) {
+	return retryBatch(len(i.Entries), o, func(indexes []int) (*BatchModifyResult, error) {
+		in := *i
+		in.Entries = make([]*BatchACLEntry, len(indexes))
+		for n, idx := range indexes {
+			in.Entries[n] = i.Entries[idx]
+		}
+		return c.BatchModifyACLEntriesWithResult(&in)
+	})
+}
@@ -0,0 +1,186 @@
+package fastly
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestClient_RetryBatchModifyDictionaryItems(t *testing.T) {
+	t.Parallel()
+
+	var err error
+	var r *BatchModifyResult
+	capture := &requestCapture{}
+	record(t, "dictionary_items_batch/retry", func(c *Client) {
+		capture.next = c.HTTPClient.Transport
+		c.HTTPClient.Transport = capture
+		r, err = c.RetryBatchModifyDictionaryItems(&BatchModifyDictionaryItemsInput{
+			ServiceID:    testServiceID,
+			DictionaryID: "4s2r3X4mVt8kQ8ps50jz7w",
+			Items: []*BatchDictionaryItem{
+				{Operation: CreateBatchOperation, ItemKey: "key1", ItemValue: "val1"},
+				{Operation: CreateBatchOperation, ItemKey: "key2", ItemValue: "val2"},
+				{Operation: CreateBatchOperation, ItemKey: "key3", ItemValue: "val3"},
+			},
+		}, &BatchRetryOptions{Attempts: 2})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.OK() {
+		t.Errorf("bad result: %#v", r)
+	}
+	// The first attempt was rejected with a 503, so the whole batch was sent
+	// again.
+	if len(capture.requests) != 2 || capture.requests[0] != capture.requests[1] {
+		t.Errorf("bad requests: %q", capture.requests)
+	}
+}
+
+func TestClient_RetryBatchModifyACLEntries(t *testing.T) {
+	t.Parallel()
+
+	var err error
+	var r *BatchModifyResult
+	capture := &requestCapture{}
+	record(t, "acl_entries_batch/retry", func(c *Client) {
+		capture.next = c.HTTPClient.Transport
+		c.HTTPClient.Transport = capture
+		r, err = c.RetryBatchModifyACLEntries(&BatchModifyACLEntriesInput{
+			ServiceID: testServiceID,
+			ACLID:     "2mI5c4hlwXTRJHkpBi5XZc",
+			Entries: []*BatchACLEntry{
+				{Operation: CreateBatchOperation, IP: String("192.0.2.2")},
+				{Operation: CreateBatchOperation, IP: String("192.0.2.1")},
+			},
+		}, &BatchRetryOptions{Attempts: 3})
+	})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if r.OK() || len(r.Errors) != 1 {
+		t.Fatalf("bad result: %#v", r)
+	}
+	if e := r.Errors[0]; e.Index != 1 || e.Message != "Entry already exists" {
+		t.Errorf("bad operation error: %#v", e)
+	}
+	// An entry which already exists fails the same way every time, so it is
+	// not sent again.
+	if len(capture.requests) != 1 {
+		t.Errorf("bad requests: %q", capture.requests)
+	}
+}
+
+func TestRetryBatch(t *testing.T) {
+	t.Parallel()
+
+	var sent [][]int
+	r, err := retryBatch(4, &BatchRetryOptions{Attempts: 3}, func(indexes []int) (*BatchModifyResult, error) {
+		sent = append(sent, append([]int(nil), indexes...))
+		switch len(sent) {
+		case 1:
+			r := &BatchModifyResult{Status: "error", Errors: []*BatchOperationError{
+				{Index: 1, Message: "Request timed out"},
+				{Index: 2, Message: "Item value too large"},
+				{Index: 3, Message: "Service temporarily unavailable"},
+			}}
+			return r, batchModifyError(r, len(indexes))
+		case 2:
+			r := &BatchModifyResult{Status: "error", Errors: []*BatchOperationError{{Index: 0, Message: "Request timed out"}}}
+			return r, batchModifyError(r, len(indexes))
+		}
+		return &BatchModifyResult{Status: "ok"}, nil
+	})
+	if len(sent) != 3 || len(sent[1]) != 2 || sent[1][0] != 1 || sent[1][1] != 3 || len(sent[2]) != 1 || sent[2][0] != 1 {
+		t.Errorf("bad attempts: %v", sent)
+	}
+	// The permanent failure of the first attempt is still reported.
+	if err == nil || r.OK() || len(r.Errors) != 1 || r.Errors[0].Index != 2 || r.Errors[0].Message != "Item value too large" {
+		t.Fatalf("bad result: %#v, %v", r, err)
+	}
+
+	// A batch rejected by a server error is sent again as a whole.
+	sent = nil
+	r, err = retryBatch(2, nil, func(indexes []int) (*BatchModifyResult, error) {
+		sent = append(sent, append([]int(nil), indexes...))
+		if len(sent) == 1 {
+			err := &HTTPError{StatusCode: 503}
+			return batchModifyErrorResult(err), err
+		}
+		return &BatchModifyResult{Status: "ok"}, nil
+	})
+	if err != nil || !r.OK() || len(sent) != 2 || len(sent[1]) != 2 {
+		t.Errorf("bad retry: %v, %v", sent, err)
+	}
+
+	// Neither a client error nor a permanent operation failure is retried.
+	for _, fail := range []func() (*BatchModifyResult, error){
+		func() (*BatchModifyResult, error) {
+			err := &HTTPError{StatusCode: 400}
+			return batchModifyErrorResult(err), err
+		},
+		func() (*BatchModifyResult, error) {
+			r := &BatchModifyResult{Status: "error", Errors: []*BatchOperationError{{Index: 0, Message: "Entry already exists"}}}
+			return r, batchModifyError(r, 2)
+		},
+	} {
+		attempts := 0
+		_, err = retryBatch(2, nil, func(indexes []int) (*BatchModifyResult, error) {
+			attempts++
+			return fail()
+		})
+		if err == nil || attempts != 1 {
+			t.Errorf("bad retry: %d attempts, %v", attempts, err)
+		}
+	}
+}
+
+func TestBatchRanges(t *testing.T) {
+	t.Parallel()
+
+	ranges := batchRanges(2501, BatchModifyMaximumOperations)
+	if fmt.Sprint(ranges) != "[{0 1000} {1000 2000} {2000 2501}]" {
+		t.Errorf("bad ranges: %v", ranges)
+	}
+	if ranges := batchRanges(0, BatchModifyMaximumOperations); len(ranges) != 0 {
+		t.Errorf("bad ranges: %v", ranges)
+	}
+}
+
+func TestApplyBatches(t *testing.T) {
+	t.Parallel()
+
+	var progress []string
+	applied, failed := applyBatches(batchRanges(5, 2), func(done, total int) {
+		progress = append(progress, fmt.Sprintf("%d/%d", done, total))
+	}, func(n int) error {
+		if n == 1 {
+			return fmt.Errorf("boom")
+		}
+		return nil
+	})
+	if applied != 3 || len(failed) != 1 || failed[0].Error() != "batch 1 (2 operations): boom" {
+		t.Errorf("bad result: %d applied, %v", applied, failed)
+	}
+	if strings.Join(progress, " ") != "2/5 4/5 5/5" {
+		t.Errorf("bad progress: %v", progress)
+	}
+}
+
+func TestClient_RetryBatchModifyDictionaryItems_validation(t *testing.T) {
+	_, err := testClient.RetryBatchModifyDictionaryItems(&BatchModifyDictionaryItemsInput{}, nil)
+	if err != ErrMissingServiceID {
+		t.Errorf("bad error: %s", err)
+	}
+
+	r, err := testClient.BatchModifyDictionaryItemsWithResult(&BatchModifyDictionaryItemsInput{ServiceID: "foo"})
+	if r != nil || err != ErrMissingDictionaryID {
+		t.Errorf("bad error: %s", err)
+	}
+
+	r, err = testClient.BatchModifyACLEntriesWithResult(&BatchModifyACLEntriesInput{ServiceID: "foo"})
+	if r != nil || err != ErrMissingACLID {
+		t.Errorf("bad error: %s", err)
+	}
+}
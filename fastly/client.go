@@ -13,6 +13,7 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/ajg/form"
 	"github.com/google/jsonapi"
@@ -65,6 +66,24 @@ type Client struct {
 	// Concurrent modifications have undefined semantics.
 	updateLock sync.Mutex
 
+	// serviceIndex caches the names and IDs of the account's services. It is
+	// loaded lazily by the service index lookups. serviceIndexMisses records
+	// when lookups last failed to find a service after a reload.
+	serviceIndex       *serviceIndex
+	serviceIndexMisses map[string]time.Time
+	serviceIndexLock   sync.Mutex
+
+	// datacenters caches the list of datacenters used for shield
+	// recommendations. It is loaded on first use or set by SetDatacenters.
+	datacenters     []Datacenter
+	datacentersLock sync.Mutex
+
+	// snippetHistory records the previous contents of the dynamic snippets
+	// updated by name, by service ID and snippet name, so that they can be
+	// rolled back.
+	snippetHistory     map[string][]*DynamicSnippet
+	snippetHistoryLock sync.Mutex
+
 	// apiKey is the Fastly API key to authenticate requests.
 	apiKey string
 
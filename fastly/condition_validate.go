@@ -0,0 +1,83 @@
+package fastly
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/fastly/go-fastly/v3/fastly/vcl"
+)
+
+// conditionScopes are the subroutines in which each type of condition is
+// evaluated.
+var conditionScopes = map[string]vcl.Scope{
+	"REQUEST":  vcl.ScopeRecv,
+	"PREFETCH": vcl.ScopeMiss | vcl.ScopePass,
+	"CACHE":    vcl.ScopeFetch,
+	"RESPONSE": vcl.ScopeDeliver | vcl.ScopeLog,
+}
+
+// ValidateConditionStatement checks a condition statement locally for syntax
+// errors, variables unknown or unavailable where the given condition type is
+// evaluated and mismatched types. For example, beresp.status cannot be used in
+// a REQUEST condition and resp.status cannot be compared with a string.
+//
+// The returned error is a vcl.ErrorList with positions within the statement,
+// or a *FieldError when the type is unknown.
+func ValidateConditionStatement(conditionType, statement string) error {
+	scope, ok := conditionScopes[conditionType]
+	if !ok {
+		return NewFieldError("Type").Message(fmt.Sprintf("unknown condition type %q", conditionType))
+	}
+	return vcl.CheckCondition(statement, scope)
+}
+
+// ValidateCondition checks the statement of a condition, see
+// ValidateConditionStatement.
+func ValidateCondition(c *Condition) error {
+	return ValidateConditionStatement(c.Type, c.Statement)
+}
+
+// conditionSet evaluates the conditions of a version locally, by name.
+type conditionSet map[string]*Condition
+
+func newConditionSet(conditions []*Condition) conditionSet {
+	cs := make(conditionSet, len(conditions))
+	for _, c := range conditions {
+		cs[c.Name] = c
+	}
+	return cs
+}
+
+// priority returns the priority of the named condition. Objects without a
+// condition come first.
+func (cs conditionSet) priority(name string) int {
+	if c, ok := cs[name]; ok && name != "" {
+		return c.Priority
+	}
+	return math.MinInt32
+}
+
+// match reports whether the named condition, which must be of one of the
+// conditionTypes, is true in env. An empty name always matches.
+func (cs conditionSet) match(name string, env vcl.Env, conditionTypes ...string) (bool, error) {
+	if name == "" {
+		return true, nil
+	}
+	c, ok := cs[name]
+	if !ok {
+		return false, fmt.Errorf("unknown condition %q", name)
+	}
+	typeOK := false
+	for _, t := range conditionTypes {
+		typeOK = typeOK || c.Type == t
+	}
+	if !typeOK {
+		return false, fmt.Errorf("condition %q is a %s condition, not %s", name, c.Type, strings.Join(conditionTypes, " or "))
+	}
+	m, err := vcl.EvalCondition(c.Statement, env)
+	if err != nil {
+		return false, fmt.Errorf("condition %q: %s", name, err)
+	}
+	return m, nil
+}
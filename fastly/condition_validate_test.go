@@ -0,0 +1,30 @@
+package fastly
+
+import (
+	"testing"
+
+	"github.com/fastly/go-fastly/v3/fastly/vcl"
+)
+
+func TestValidateConditionStatement(t *testing.T) {
+	t.Parallel()
+
+	if err := ValidateCondition(&Condition{Type: "CACHE", Statement: `beresp.status == 404 && req.url ~ "^/static/"`}); err != nil {
+		t.Errorf("unexpected error %s", err)
+	}
+
+	err := ValidateConditionStatement("REQUEST", `beresp.status == 404`)
+	if _, ok := err.(vcl.ErrorList); !ok || err.Error() != "1:1: variable beresp.status is not available in recv" {
+		t.Errorf("bad error %v", err)
+	}
+
+	err = ValidateConditionStatement("RESPONSE", `resp.bytes_written > 0`)
+	if err == nil || err.Error() != "1:1: variable resp.bytes_written is not available in deliver" {
+		t.Errorf("bad error %v", err)
+	}
+
+	err = ValidateConditionStatement("NOPE", `req.url`)
+	if _, ok := err.(*FieldError); !ok {
+		t.Errorf("expected a field error, got %v", err)
+	}
+}
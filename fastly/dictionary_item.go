@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/url"
 	"sort"
+	"strconv"
 	"time"
 )
 
@@ -36,6 +37,44 @@ type ListDictionaryItemsInput struct {
 
 	// DictionaryID is the ID of the dictionary to retrieve items for (required).
 	DictionaryID string
+
+	// Page is the page index for pagination. When zero, all items are
+	// returned in a single response.
+	Page int
+
+	// PerPage is the number of items per page.
+	PerPage int
+
+	// Sort is the field to sort by, e.g. "item_key" or "created". When empty
+	// and Page is zero, the results are sorted by key.
+	Sort string
+
+	// Direction is the sort direction, either "ascend" or "descend".
+	Direction string
+}
+
+// formatFilters converts user input into query parameters for filtering.
+func (i *ListDictionaryItemsInput) formatFilters() map[string]string {
+	result := map[string]string{}
+	pairings := map[string]interface{}{
+		"page":      i.Page,
+		"per_page":  i.PerPage,
+		"sort":      i.Sort,
+		"direction": i.Direction,
+	}
+	for key, value := range pairings {
+		switch v := value.(type) {
+		case int:
+			if v != 0 {
+				result[key] = strconv.Itoa(v)
+			}
+		case string:
+			if v != "" {
+				result[key] = v
+			}
+		}
+	}
+	return result
 }
 
 // ListDictionaryItems returns the list of dictionary items for the
@@ -50,7 +89,9 @@ func (c *Client) ListDictionaryItems(i *ListDictionaryItemsInput) ([]*Dictionary
 	}
 
 	path := fmt.Sprintf("/service/%s/dictionary/%s/items", i.ServiceID, i.DictionaryID)
-	resp, err := c.Get(path, nil)
+	resp, err := c.Get(path, &RequestOptions{
+		Params: i.formatFilters(),
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -59,7 +100,9 @@ func (c *Client) ListDictionaryItems(i *ListDictionaryItemsInput) ([]*Dictionary
 	if err := decodeBodyMap(resp.Body, &bs); err != nil {
 		return nil, err
 	}
-	sort.Stable(dictionaryItemsByKey(bs))
+	if i.Page == 0 && i.Sort == "" {
+		sort.Stable(dictionaryItemsByKey(bs))
+	}
 	return bs, nil
 }
 
@@ -209,32 +252,45 @@ type BatchDictionaryItem struct {
 	ItemValue string         `json:"item_value"`
 }
 
+// BatchModifyDictionaryItems applies a batch of operations to the items of a
+// dictionary.
 func (c *Client) BatchModifyDictionaryItems(i *BatchModifyDictionaryItemsInput) error {
+	_, err := c.BatchModifyDictionaryItemsWithResult(i)
+	return err
+}
+
+// BatchModifyDictionaryItemsWithResult is like BatchModifyDictionaryItems, but
+// also returns the result of the batch. When the API rejects the batch, the
+// result is returned along with the error, listing the failed operations it
+// reported.
+func (c *Client) BatchModifyDictionaryItemsWithResult(i *BatchModifyDictionaryItemsInput) (*BatchModifyResult, error) {
 
 	if i.ServiceID == "" {
-		return ErrMissingServiceID
+		return nil, ErrMissingServiceID
 	}
 
 	if i.DictionaryID == "" {
-		return ErrMissingDictionaryID
+		return nil, ErrMissingDictionaryID
 	}
 
 	if len(i.Items) > BatchModifyMaximumOperations {
-		return ErrMaxExceededItems
+		return nil, ErrMaxExceededItems
 	}
 
 	path := fmt.Sprintf("/service/%s/dictionary/%s/items", i.ServiceID, i.DictionaryID)
 	resp, err := c.PatchJSON(path, i, nil)
 	if err != nil {
-		return err
+		return batchModifyErrorResult(err), err
 	}
 
-	var batchModifyResult map[string]string
-	if err := decodeBodyMap(resp.Body, &batchModifyResult); err != nil {
-		return err
+	r, err := decodeBatchModifyResult(resp.Body)
+	if err != nil {
+		return nil, err
 	}
-
-	return nil
+	if !r.OK() {
+		return r, batchModifyError(r, len(i.Items))
+	}
+	return r, nil
 }
 
 // DeleteDictionaryItemInput is the input parameter to DeleteDictionaryItem.
@@ -0,0 +1,140 @@
+package fastly
+
+import (
+	"fmt"
+	"sort"
+)
+
+// SyncDictionaryInput is used as input to the SyncDictionary function.
+type SyncDictionaryInput struct {
+	// ServiceID is the ID of the service (required).
+	ServiceID string
+
+	// DictionaryID is the ID of the dictionary to synchronize (required).
+	DictionaryID string
+
+	// Items is the desired content of the dictionary. Keys not in Items are
+	// deleted.
+	Items map[string]string
+
+	// DryRun computes the operations without applying them.
+	DryRun bool
+
+	// Progress, if set, is called after each batch is applied with the number
+	// of operations processed so far and the total.
+	Progress func(done, total int)
+}
+
+// SyncBatchError is the failure of one batch of a synchronization.
+type SyncBatchError struct {
+	// Batch is the index of the failed batch.
+	Batch int
+
+	// Operations is the number of operations in the failed batch.
+	Operations int
+
+	Err error
+}
+
+// Error fulfills the error interface.
+func (e *SyncBatchError) Error() string {
+	return fmt.Sprintf("batch %d (%d operations): %s", e.Batch, e.Operations, e.Err)
+}
+
+// SyncDictionaryResult reports the operations computed and applied by
+// SyncDictionary.
+type SyncDictionaryResult struct {
+	// Operations are the computed operations, in the order they are applied:
+	// deletes, then updates, then creates, each sorted by key.
+	Operations []*BatchDictionaryItem
+
+	// Batches are the operations grouped as sent to the API.
+	Batches [][]*BatchDictionaryItem
+
+	// Applied is the number of operations successfully applied.
+	Applied int
+
+	// Failed lists the batches which could not be applied.
+	Failed []*SyncBatchError
+}
+
+// SyncDictionary makes the items of a dictionary match the given key/value
+// map. It lists the current items, computes the create, update and delete
+// operations needed and applies them in batches of at most
+// BatchModifyMaximumOperations.
+//
+// A failing batch does not stop the remaining ones from being applied. The
+// failures are reported in the result and an error is returned along with it.
+func (c *Client) SyncDictionary(i *SyncDictionaryInput) (*SyncDictionaryResult, error) {
+	if i.ServiceID == "" {
+		return nil, ErrMissingServiceID
+	}
+
+	if i.DictionaryID == "" {
+		return nil, ErrMissingDictionaryID
+	}
+
+	if len(i.Items) > MaximumDictionarySize {
+		return nil, NewFieldError("Items").Message(fmt.Sprintf("exceeds the maximum dictionary size of %d", MaximumDictionarySize))
+	}
+
+	current, err := c.ListDictionaryItems(&ListDictionaryItemsInput{
+		ServiceID:    i.ServiceID,
+		DictionaryID: i.DictionaryID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ops := diffDictionaryItems(current, i.Items)
+	ranges := batchRanges(len(ops), BatchModifyMaximumOperations)
+	result := &SyncDictionaryResult{Operations: ops}
+	for _, r := range ranges {
+		result.Batches = append(result.Batches, ops[r.start:r.end:r.end])
+	}
+	if i.DryRun {
+		return result, nil
+	}
+
+	result.Applied, result.Failed = applyBatches(ranges, i.Progress, func(n int) error {
+		return c.BatchModifyDictionaryItems(&BatchModifyDictionaryItemsInput{
+			ServiceID:    i.ServiceID,
+			DictionaryID: i.DictionaryID,
+			Items:        result.Batches[n],
+		})
+	})
+
+	if len(result.Failed) > 0 {
+		return result, fmt.Errorf("%d of %d dictionary batches failed, first error: %s", len(result.Failed), len(result.Batches), result.Failed[0])
+	}
+	return result, nil
+}
+
+// diffDictionaryItems returns the operations turning current into desired.
+// Deletes come first so that a dictionary close to its size limit has room
+// for the creates.
+func diffDictionaryItems(current []*DictionaryItem, desired map[string]string) []*BatchDictionaryItem {
+	var deletes, updates, creates []*BatchDictionaryItem
+
+	existing := make(map[string]bool, len(current))
+	for _, item := range current {
+		existing[item.ItemKey] = true
+		value, ok := desired[item.ItemKey]
+		switch {
+		case !ok:
+			deletes = append(deletes, &BatchDictionaryItem{Operation: DeleteBatchOperation, ItemKey: item.ItemKey})
+		case value != item.ItemValue:
+			updates = append(updates, &BatchDictionaryItem{Operation: UpdateBatchOperation, ItemKey: item.ItemKey, ItemValue: value})
+		}
+	}
+	for key, value := range desired {
+		if !existing[key] {
+			creates = append(creates, &BatchDictionaryItem{Operation: CreateBatchOperation, ItemKey: key, ItemValue: value})
+		}
+	}
+
+	for _, ops := range [][]*BatchDictionaryItem{deletes, updates, creates} {
+		sort.Slice(ops, func(a, b int) bool { return ops[a].ItemKey < ops[b].ItemKey })
+	}
+	return append(append(deletes, updates...), creates...)
+}
@@ -0,0 +1,82 @@
+package fastly
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestClient_SyncDictionary(t *testing.T) {
+	t.Parallel()
+
+	var err error
+	var r *SyncDictionaryResult
+	var progress [][2]int
+	record(t, "dictionary_items_batch/sync", func(c *Client) {
+		r, err = c.SyncDictionary(&SyncDictionaryInput{
+			ServiceID:    testServiceID,
+			DictionaryID: "4s2r3X4mVt8kQ8ps50jz7w",
+			Items: map[string]string{
+				"same":    "v",
+				"changed": "new",
+				"added":   "val",
+			},
+			Progress: func(done, total int) {
+				progress = append(progress, [2]int{done, total})
+			},
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []BatchDictionaryItem{
+		{Operation: DeleteBatchOperation, ItemKey: "gone"},
+		{Operation: UpdateBatchOperation, ItemKey: "changed", ItemValue: "new"},
+		{Operation: CreateBatchOperation, ItemKey: "added", ItemValue: "val"},
+	}
+	if len(r.Operations) != len(want) {
+		t.Fatalf("bad operations: %d", len(r.Operations))
+	}
+	for n, w := range want {
+		if *r.Operations[n] != w {
+			t.Errorf("bad operation %d: %#v", n, r.Operations[n])
+		}
+	}
+	if len(r.Batches) != 1 || r.Applied != 3 || len(r.Failed) != 0 {
+		t.Errorf("bad result: %d batches, %d applied, %d failed", len(r.Batches), r.Applied, len(r.Failed))
+	}
+	if len(progress) != 1 || progress[0] != [2]int{3, 3} {
+		t.Errorf("bad progress: %v", progress)
+	}
+}
+
+func TestClient_SyncDictionary_validation(t *testing.T) {
+	var err error
+	_, err = testClient.SyncDictionary(&SyncDictionaryInput{
+		ServiceID: "",
+	})
+	if err != ErrMissingServiceID {
+		t.Errorf("bad error: %s", err)
+	}
+
+	_, err = testClient.SyncDictionary(&SyncDictionaryInput{
+		ServiceID:    "foo",
+		DictionaryID: "",
+	})
+	if err != ErrMissingDictionaryID {
+		t.Errorf("bad error: %s", err)
+	}
+
+	items := make(map[string]string, MaximumDictionarySize+1)
+	for n := 0; n <= MaximumDictionarySize; n++ {
+		items[fmt.Sprint(n)] = ""
+	}
+	_, err = testClient.SyncDictionary(&SyncDictionaryInput{
+		ServiceID:    "foo",
+		DictionaryID: "bar",
+		Items:        items,
+	})
+	if err == nil {
+		t.Error("expected an error for too many items")
+	}
+}
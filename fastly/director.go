@@ -36,6 +36,7 @@ type Director struct {
 	Type      DirectorType `mapstructure:"type"`
 	Retries   uint         `mapstructure:"retries"`
 	Capacity  uint         `mapstructure:"capacity"`
+	Backends  []string     `mapstructure:"backends"`
 	CreatedAt *time.Time   `mapstructure:"created_at"`
 	UpdatedAt *time.Time   `mapstructure:"updated_at"`
 	DeletedAt *time.Time   `mapstructure:"deleted_at"`
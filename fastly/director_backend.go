@@ -3,6 +3,7 @@ package fastly
 import (
 	"fmt"
 	"net/url"
+	"sort"
 	"time"
 )
 
@@ -163,3 +164,155 @@ func (c *Client) DeleteDirectorBackend(i *DeleteDirectorBackendInput) error {
 	}
 	return nil
 }
+
+// ListDirectorBackendsInput is used as input to the ListDirectorBackends
+// function.
+type ListDirectorBackendsInput struct {
+	// ServiceID is the ID of the service (required).
+	ServiceID string
+
+	// ServiceVersion is the specific configuration version (required).
+	ServiceVersion int
+
+	// Director is the name of the director (required).
+	Director string
+}
+
+// ListDirectorBackends lists the backends which belong to a director, as
+// reported by the director's backends field. The API has no endpoint listing
+// the relationships themselves, so only the names are filled in.
+func (c *Client) ListDirectorBackends(i *ListDirectorBackendsInput) ([]*DirectorBackend, error) {
+	if i.ServiceID == "" {
+		return nil, ErrMissingServiceID
+	}
+
+	if i.ServiceVersion == 0 {
+		return nil, ErrMissingServiceVersion
+	}
+
+	if i.Director == "" {
+		return nil, ErrMissingDirector
+	}
+
+	d, err := c.GetDirector(&GetDirectorInput{
+		ServiceID:      i.ServiceID,
+		ServiceVersion: i.ServiceVersion,
+		Name:           i.Director,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	bs := make([]*DirectorBackend, 0, len(d.Backends))
+	for _, name := range d.Backends {
+		bs = append(bs, &DirectorBackend{
+			ServiceID:      d.ServiceID,
+			ServiceVersion: d.ServiceVersion,
+			Director:       d.Name,
+			Backend:        name,
+		})
+	}
+	return bs, nil
+}
+
+// SetDirectorBackendsInput is used as input to the SetDirectorBackends
+// function.
+type SetDirectorBackendsInput struct {
+	// ServiceID is the ID of the service (required).
+	ServiceID string
+
+	// ServiceVersion is the specific configuration version (required).
+	ServiceVersion int
+
+	// Director is the name of the director (required).
+	Director string
+
+	// Backends are the names of the backends the director should contain.
+	// An empty list removes every backend from the director.
+	Backends []string
+}
+
+// DirectorBackendsChange reports the backends added to and removed from a
+// director by SetDirectorBackends.
+type DirectorBackendsChange struct {
+	Added   []string
+	Removed []string
+}
+
+// SetDirectorBackends adds and removes director backend relationships so that
+// the director contains exactly the given backends. Backends already in the
+// director are left untouched.
+func (c *Client) SetDirectorBackends(i *SetDirectorBackendsInput) (*DirectorBackendsChange, error) {
+	if i.ServiceID == "" {
+		return nil, ErrMissingServiceID
+	}
+
+	if i.ServiceVersion == 0 {
+		return nil, ErrMissingServiceVersion
+	}
+
+	if i.Director == "" {
+		return nil, ErrMissingDirector
+	}
+
+	desired := make(map[string]bool, len(i.Backends))
+	for _, name := range i.Backends {
+		if name == "" {
+			return nil, ErrMissingBackend
+		}
+		desired[name] = true
+	}
+
+	current, err := c.ListDirectorBackends(&ListDirectorBackendsInput{
+		ServiceID:      i.ServiceID,
+		ServiceVersion: i.ServiceVersion,
+		Director:       i.Director,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var add, remove []string
+	present := make(map[string]bool, len(current))
+	for _, b := range current {
+		present[b.Backend] = true
+		if !desired[b.Backend] {
+			remove = append(remove, b.Backend)
+		}
+	}
+	for name := range desired {
+		if !present[name] {
+			add = append(add, name)
+		}
+	}
+	sort.Strings(add)
+	sort.Strings(remove)
+
+	// Add first so that a director being repopulated is never left empty.
+	// On error, the change lists what was applied before the failure.
+	ch := &DirectorBackendsChange{}
+	for _, name := range add {
+		if _, err := c.CreateDirectorBackend(&CreateDirectorBackendInput{
+			ServiceID:      i.ServiceID,
+			ServiceVersion: i.ServiceVersion,
+			Director:       i.Director,
+			Backend:        name,
+		}); err != nil {
+			return ch, err
+		}
+		ch.Added = append(ch.Added, name)
+	}
+
+	for _, name := range remove {
+		if err := c.DeleteDirectorBackend(&DeleteDirectorBackendInput{
+			ServiceID:      i.ServiceID,
+			ServiceVersion: i.ServiceVersion,
+			Director:       i.Director,
+			Backend:        name,
+		}); err != nil {
+			return ch, err
+		}
+		ch.Removed = append(ch.Removed, name)
+	}
+	return ch, nil
+}
@@ -81,6 +81,56 @@ func TestClient_DirectorBackends(t *testing.T) {
 	}
 }
 
+func TestClient_ListDirectorBackends(t *testing.T) {
+	t.Parallel()
+
+	var err error
+	var bs []*DirectorBackend
+	record(t, "director_backends/list", func(c *Client) {
+		bs, err = c.ListDirectorBackends(&ListDirectorBackendsInput{
+			ServiceID:      testServiceID,
+			ServiceVersion: 36,
+			Director:       "director",
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bs) != 2 {
+		t.Fatalf("bad backends: %v", bs)
+	}
+	if bs[0].Director != "director" || bs[0].Backend != "backend-a" || bs[1].Backend != "backend-b" {
+		t.Errorf("bad backends: %#v, %#v", bs[0], bs[1])
+	}
+	if bs[0].ServiceVersion != 36 {
+		t.Errorf("bad version: %d", bs[0].ServiceVersion)
+	}
+}
+
+func TestClient_SetDirectorBackends(t *testing.T) {
+	t.Parallel()
+
+	var err error
+	var ch *DirectorBackendsChange
+	record(t, "director_backends/set", func(c *Client) {
+		ch, err = c.SetDirectorBackends(&SetDirectorBackendsInput{
+			ServiceID:      testServiceID,
+			ServiceVersion: 36,
+			Director:       "director",
+			Backends:       []string{"backend-b", "backend-c"},
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ch.Added) != 1 || ch.Added[0] != "backend-c" {
+		t.Errorf("bad added: %v", ch.Added)
+	}
+	if len(ch.Removed) != 1 || ch.Removed[0] != "backend-a" {
+		t.Errorf("bad removed: %v", ch.Removed)
+	}
+}
+
 func TestClient_CreateDirectorBackend_validation(t *testing.T) {
 	var err error
 	_, err = testClient.CreateDirectorBackend(&CreateDirectorBackendInput{
@@ -172,3 +222,65 @@ func TestClient_DeleteDirectorBackend_validation(t *testing.T) {
 		t.Errorf("bad error: %s", err)
 	}
 }
+
+func TestClient_ListDirectorBackends_validation(t *testing.T) {
+	var err error
+	_, err = testClient.ListDirectorBackends(&ListDirectorBackendsInput{
+		ServiceID: "",
+	})
+	if err != ErrMissingServiceID {
+		t.Errorf("bad error: %s", err)
+	}
+
+	_, err = testClient.ListDirectorBackends(&ListDirectorBackendsInput{
+		ServiceID:      "foo",
+		ServiceVersion: 0,
+	})
+	if err != ErrMissingServiceVersion {
+		t.Errorf("bad error: %s", err)
+	}
+
+	_, err = testClient.ListDirectorBackends(&ListDirectorBackendsInput{
+		ServiceID:      "foo",
+		ServiceVersion: 1,
+	})
+	if err != ErrMissingDirector {
+		t.Errorf("bad error: %s", err)
+	}
+}
+
+func TestClient_SetDirectorBackends_validation(t *testing.T) {
+	var err error
+	_, err = testClient.SetDirectorBackends(&SetDirectorBackendsInput{
+		ServiceID: "",
+	})
+	if err != ErrMissingServiceID {
+		t.Errorf("bad error: %s", err)
+	}
+
+	_, err = testClient.SetDirectorBackends(&SetDirectorBackendsInput{
+		ServiceID:      "foo",
+		ServiceVersion: 0,
+	})
+	if err != ErrMissingServiceVersion {
+		t.Errorf("bad error: %s", err)
+	}
+
+	_, err = testClient.SetDirectorBackends(&SetDirectorBackendsInput{
+		ServiceID:      "foo",
+		ServiceVersion: 1,
+	})
+	if err != ErrMissingDirector {
+		t.Errorf("bad error: %s", err)
+	}
+
+	_, err = testClient.SetDirectorBackends(&SetDirectorBackendsInput{
+		ServiceID:      "foo",
+		ServiceVersion: 1,
+		Director:       "bar",
+		Backends:       []string{""},
+	})
+	if err != ErrMissingBackend {
+		t.Errorf("bad error: %s", err)
+	}
+}
@@ -222,3 +222,120 @@ func (c *Client) DeleteDomain(i *DeleteDomainInput) error {
 	}
 	return nil
 }
+
+// DomainCheck is the result of checking whether a domain's DNS points at
+// Fastly.
+type DomainCheck struct {
+	// Domain is the domain that was checked.
+	Domain *Domain
+
+	// CNAME is the hostname the domain currently resolves to.
+	CNAME string
+
+	// Success is true when the domain's CNAME points at Fastly.
+	Success bool
+}
+
+// newDomainCheck decodes a single check result. The API returns each result
+// as a tuple of the domain details, the current CNAME and a success flag.
+func newDomainCheck(raw interface{}) (*DomainCheck, error) {
+	tuple, ok := raw.([]interface{})
+	if !ok || len(tuple) != 3 {
+		return nil, fmt.Errorf("unexpected domain check result: %v", raw)
+	}
+
+	var d *Domain
+	if err := decodeMap(tuple[0], &d); err != nil {
+		return nil, err
+	}
+
+	dc := &DomainCheck{Domain: d}
+	if s, ok := tuple[1].(string); ok {
+		dc.CNAME = s
+	}
+	if b, ok := tuple[2].(bool); ok {
+		dc.Success = b
+	}
+	return dc, nil
+}
+
+// CheckDomainInput is used as input to the CheckDomain function.
+type CheckDomainInput struct {
+	// ServiceID is the ID of the service (required).
+	ServiceID string
+
+	// ServiceVersion is the specific configuration version (required).
+	ServiceVersion int
+
+	// Name is the name of the domain to check (required).
+	Name string
+}
+
+// CheckDomain checks whether the given domain's CNAME points at Fastly.
+func (c *Client) CheckDomain(i *CheckDomainInput) (*DomainCheck, error) {
+	if i.ServiceID == "" {
+		return nil, ErrMissingServiceID
+	}
+
+	if i.ServiceVersion == 0 {
+		return nil, ErrMissingServiceVersion
+	}
+
+	if i.Name == "" {
+		return nil, ErrMissingName
+	}
+
+	path := fmt.Sprintf("/service/%s/version/%d/domain/%s/check", i.ServiceID, i.ServiceVersion, url.PathEscape(i.Name))
+	resp, err := c.Get(path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw interface{}
+	if err := decodeBodyMap(resp.Body, &raw); err != nil {
+		return nil, err
+	}
+	return newDomainCheck(raw)
+}
+
+// CheckDomainsInput is used as input to the CheckDomains function.
+type CheckDomainsInput struct {
+	// ServiceID is the ID of the service (required).
+	ServiceID string
+
+	// ServiceVersion is the specific configuration version (required).
+	ServiceVersion int
+}
+
+// CheckDomains checks whether the CNAME of every domain of the configuration
+// version points at Fastly.
+func (c *Client) CheckDomains(i *CheckDomainsInput) ([]*DomainCheck, error) {
+	if i.ServiceID == "" {
+		return nil, ErrMissingServiceID
+	}
+
+	if i.ServiceVersion == 0 {
+		return nil, ErrMissingServiceVersion
+	}
+
+	path := fmt.Sprintf("/service/%s/version/%d/domain/check_all", i.ServiceID, i.ServiceVersion)
+	resp, err := c.Get(path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw []interface{}
+	if err := decodeBodyMap(resp.Body, &raw); err != nil {
+		return nil, err
+	}
+
+	dcs := make([]*DomainCheck, 0, len(raw))
+	for _, r := range raw {
+		dc, err := newDomainCheck(r)
+		if err != nil {
+			return nil, err
+		}
+		dcs = append(dcs, dc)
+	}
+	return dcs, nil
+}
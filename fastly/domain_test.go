@@ -240,3 +240,96 @@ func TestClient_DeleteDomain_validation(t *testing.T) {
 		t.Errorf("bad error: %s", err)
 	}
 }
+
+func TestClient_CheckDomain(t *testing.T) {
+	t.Parallel()
+
+	var err error
+	var dc *DomainCheck
+	record(t, "domains/check", func(c *Client) {
+		dc, err = c.CheckDomain(&CheckDomainInput{
+			ServiceID:      testServiceID,
+			ServiceVersion: 17,
+			Name:           "www.example.com",
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dc.Domain.Name != "www.example.com" {
+		t.Errorf("bad name: %q", dc.Domain.Name)
+	}
+	if dc.CNAME != "dualstack.t.sni.global.fastly.net." {
+		t.Errorf("bad cname: %q", dc.CNAME)
+	}
+	if !dc.Success {
+		t.Errorf("bad success: %t", dc.Success)
+	}
+}
+
+func TestClient_CheckDomains(t *testing.T) {
+	t.Parallel()
+
+	var err error
+	var dcs []*DomainCheck
+	record(t, "domains/check_all", func(c *Client) {
+		dcs, err = c.CheckDomains(&CheckDomainsInput{
+			ServiceID:      testServiceID,
+			ServiceVersion: 17,
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dcs) != 2 {
+		t.Fatalf("bad checks: %v", dcs)
+	}
+	if dcs[1].Domain.Name != "api.example.com" || dcs[1].Success {
+		t.Errorf("bad check: %#v", dcs[1])
+	}
+}
+
+func TestClient_CheckDomain_validation(t *testing.T) {
+	var err error
+	_, err = testClient.CheckDomain(&CheckDomainInput{
+		ServiceID: "",
+	})
+	if err != ErrMissingServiceID {
+		t.Errorf("bad error: %s", err)
+	}
+
+	_, err = testClient.CheckDomain(&CheckDomainInput{
+		ServiceID:      "foo",
+		ServiceVersion: 0,
+	})
+	if err != ErrMissingServiceVersion {
+		t.Errorf("bad error: %s", err)
+	}
+
+	_, err = testClient.CheckDomain(&CheckDomainInput{
+		ServiceID:      "foo",
+		ServiceVersion: 1,
+		Name:           "",
+	})
+	if err != ErrMissingName {
+		t.Errorf("bad error: %s", err)
+	}
+}
+
+func TestClient_CheckDomains_validation(t *testing.T) {
+	var err error
+	_, err = testClient.CheckDomains(&CheckDomainsInput{
+		ServiceID: "",
+	})
+	if err != ErrMissingServiceID {
+		t.Errorf("bad error: %s", err)
+	}
+
+	_, err = testClient.CheckDomains(&CheckDomainsInput{
+		ServiceID:      "foo",
+		ServiceVersion: 0,
+	})
+	if err != ErrMissingServiceVersion {
+		t.Errorf("bad error: %s", err)
+	}
+}
@@ -0,0 +1,109 @@
+package fastly
+
+import (
+	"context"
+	"net"
+	"strings"
+)
+
+// DefaultFastlyCNAMESuffixes are the hostname suffixes a domain's CNAME must
+// end in to be considered pointing at Fastly.
+var DefaultFastlyCNAMESuffixes = []string{
+	"fastly.net.",
+	"fastlylb.net.",
+}
+
+// DNSResolver resolves the canonical name of a host. *net.Resolver satisfies
+// this interface.
+type DNSResolver interface {
+	LookupCNAME(ctx context.Context, host string) (string, error)
+}
+
+// DomainVerifier checks locally, without calling the Fastly API, whether
+// domains resolve to Fastly.
+type DomainVerifier struct {
+	// Resolver is used for DNS lookups. When nil, net.DefaultResolver is
+	// used.
+	Resolver DNSResolver
+
+	// Suffixes are the accepted CNAME suffixes. When empty,
+	// DefaultFastlyCNAMESuffixes is used.
+	Suffixes []string
+}
+
+// DomainVerification is the result of verifying a single domain locally.
+type DomainVerification struct {
+	DomainCheck
+
+	// Err is the DNS lookup error, if any.
+	Err error
+}
+
+// Verify resolves each domain and reports whether its CNAME points at Fastly.
+// Wildcard domains cannot be resolved and are reported as failed.
+func (v *DomainVerifier) Verify(ctx context.Context, names ...string) []*DomainVerification {
+	var resolver DNSResolver = net.DefaultResolver
+	if v.Resolver != nil {
+		resolver = v.Resolver
+	}
+
+	suffixes := v.Suffixes
+	if len(suffixes) == 0 {
+		suffixes = DefaultFastlyCNAMESuffixes
+	}
+
+	results := make([]*DomainVerification, 0, len(names))
+	for _, name := range names {
+		r := &DomainVerification{
+			DomainCheck: DomainCheck{Domain: &Domain{Name: name}},
+		}
+		results = append(results, r)
+
+		if strings.HasPrefix(name, "*.") {
+			continue
+		}
+
+		cname, err := resolver.LookupCNAME(ctx, name)
+		if err != nil {
+			r.Err = err
+			continue
+		}
+		r.CNAME = cname
+		r.Success = cnameMatches(cname, suffixes)
+	}
+	return results
+}
+
+// VerifyDomains verifies every domain of the given list, such as the result
+// of ListDomains.
+func (v *DomainVerifier) VerifyDomains(ctx context.Context, ds []*Domain) []*DomainVerification {
+	names := make([]string, 0, len(ds))
+	for _, d := range ds {
+		names = append(names, d.Name)
+	}
+
+	results := v.Verify(ctx, names...)
+	for i, r := range results {
+		r.Domain = ds[i]
+	}
+	return results
+}
+
+// cnameMatches reports whether cname ends in one of the suffixes, comparing
+// fully-qualified names on label boundaries.
+func cnameMatches(cname string, suffixes []string) bool {
+	cname = strings.ToLower(cname)
+	if !strings.HasSuffix(cname, ".") {
+		cname += "."
+	}
+	for _, s := range suffixes {
+		s = strings.ToLower(s)
+		if !strings.HasSuffix(s, ".") {
+			s += "."
+		}
+		if cname == s || strings.HasSuffix(cname, "."+strings.TrimPrefix(s, ".")) {
+			return true
+		}
+	}
+	return false
+}
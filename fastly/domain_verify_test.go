@@ -0,0 +1,60 @@
+package fastly
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// staticResolver is a DNSResolver answering from a fixed map.
+type staticResolver map[string]string
+
+func (r staticResolver) LookupCNAME(ctx context.Context, host string) (string, error) {
+	if cname, ok := r[host]; ok {
+		return cname, nil
+	}
+	return "", errors.New("no such host")
+}
+
+func TestDomainVerifier_Verify(t *testing.T) {
+	v := &DomainVerifier{
+		Resolver: staticResolver{
+			"www.example.com": "dualstack.t.sni.global.fastly.net.",
+			"api.example.com": "api.example.herokuapp.com.",
+			"old.example.com": "notfastly.net.",
+		},
+	}
+
+	rs := v.Verify(context.Background(), "www.example.com", "api.example.com", "old.example.com", "missing.example.com", "*.example.com")
+	if len(rs) != 5 {
+		t.Fatalf("bad results: %v", rs)
+	}
+	if !rs[0].Success || rs[0].CNAME != "dualstack.t.sni.global.fastly.net." {
+		t.Errorf("bad result: %#v", rs[0])
+	}
+	if rs[1].Success {
+		t.Errorf("bad result: %#v", rs[1])
+	}
+	if rs[2].Success {
+		t.Errorf("suffix matched across a label boundary: %#v", rs[2])
+	}
+	if rs[3].Success || rs[3].Err == nil {
+		t.Errorf("bad result: %#v", rs[3])
+	}
+	if rs[4].Success || rs[4].Err != nil {
+		t.Errorf("bad result: %#v", rs[4])
+	}
+}
+
+func TestDomainVerifier_VerifyDomains(t *testing.T) {
+	v := &DomainVerifier{
+		Resolver: staticResolver{"www.example.com": "example.map.fastly.net"},
+		Suffixes: []string{"map.fastly.net"},
+	}
+
+	d := &Domain{Name: "www.example.com", ServiceVersion: 3}
+	rs := v.VerifyDomains(context.Background(), []*Domain{d})
+	if len(rs) != 1 || !rs[0].Success || rs[0].Domain != d {
+		t.Errorf("bad results: %#v", rs)
+	}
+}
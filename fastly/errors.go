@@ -91,14 +91,26 @@ var ErrMissingDictionaryID = NewFieldError("DictionaryID")
 // requires a "Director" key, but one was not set.
 var ErrMissingDirector = NewFieldError("Director")
 
+// ErrMissingDomain is an error that is returned when an input struct
+// requires a "Domain" key, but one was not set.
+var ErrMissingDomain = NewFieldError("Domain")
+
 // ErrMissingEventID is an error that is returned when an input struct
 // requires a "EventID" key, but one was not set.
 var ErrMissingEventID = NewFieldError("EventID")
 
+// ErrMissingFiles is an error that is returned when an input struct
+// requires either a "Dir" or a "Files" key, but neither was set.
+var ErrMissingFiles = NewFieldError("Files").Message("either Dir or Files is required")
+
 // ErrMissingFrom is an error that is returned when an input struct
 // requires a "From" key, but one was not set.
 var ErrMissingFrom = NewFieldError("From")
 
+// ErrMissingHealthCheck is an error that is returned when an input struct
+// requires a "HealthCheck" key, but one was not set.
+var ErrMissingHealthCheck = NewFieldError("HealthCheck")
+
 // ErrMissingTokenID is an error that is returned when an input struct requires a
 // "TokenID" key, but one was not set.
 var ErrMissingTokenID = errors.New("missing required field 'TokenID'")
@@ -127,6 +139,10 @@ var ErrMissingKey = NewFieldError("Key")
 // requires a "Login" key, but one was not set.
 var ErrMissingLogin = NewFieldError("Login")
 
+// ErrMissingMain is an error that is returned when an input struct
+// requires a "Main" key, but one was not set.
+var ErrMissingMain = NewFieldError("Main")
+
 // ErrMissingMonth is an error that is returned when an input struct
 // requires a "Month" key, but one was not set.
 var ErrMissingMonth = NewFieldError("Month")
@@ -151,6 +167,10 @@ var ErrMissingNumber = NewFieldError("Number")
 // requires a "PoolID" key, but one was not set.
 var ErrMissingPoolID = NewFieldError("PoolID")
 
+// ErrMissingRequest is an error that is returned when an input struct
+// requires a "Request" key, but one was not set.
+var ErrMissingRequest = NewFieldError("Request")
+
 // ErrMissingServer is an error that is returned when an input struct
 // requires a "Server" key, but one was not set.
 var ErrMissingServer = NewFieldError("Server")
@@ -167,6 +187,30 @@ var ErrMissingServiceID = NewFieldError("ServiceID")
 // requires a "ServiceVersion" key, but one was not set.
 var ErrMissingServiceVersion = NewFieldError("ServiceVersion")
 
+// ErrMissingServices is an error that is returned when an input struct
+// requires a "Services" key, but one was not set.
+var ErrMissingServices = NewFieldError("Services")
+
+// ErrMissingSourceServiceID is an error that is returned when an input struct
+// requires a "SourceServiceID" key, but one was not set.
+var ErrMissingSourceServiceID = NewFieldError("SourceServiceID")
+
+// ErrMissingSourceServiceVersion is an error that is returned when an input
+// struct requires a "SourceServiceVersion" key, but one was not set.
+var ErrMissingSourceServiceVersion = NewFieldError("SourceServiceVersion")
+
+// ErrMissingTargetServiceID is an error that is returned when an input struct
+// requires a "TargetServiceID" key, but one was not set.
+var ErrMissingTargetServiceID = NewFieldError("TargetServiceID")
+
+// ErrLockedTargetServiceVersion is an error that is returned when an input
+// struct specifies a "TargetServiceVersion" that is locked or active.
+var ErrLockedTargetServiceVersion = NewFieldError("TargetServiceVersion").Message("version must not be locked or active")
+
+// ErrMissingTemplates is an error that is returned when an input struct
+// requires either a "Dir" or a "Templates" key, but neither was set.
+var ErrMissingTemplates = NewFieldError("Templates").Message("either Dir or Templates is required")
+
 // ErrMissingTLSCertificate is an error that is returned when an input struct
 // requires a "TLSCertificate" key, but one was not set.
 var ErrMissingTLSCertificate = NewFieldError("TLSCertificate")
@@ -191,6 +235,10 @@ var ErrMissingTo = NewFieldError("To")
 // "Kind" key, but one was not set.
 var ErrMissingKind = NewFieldError("Kind")
 
+// ErrMissingType is an error that is returned when an input struct
+// requires a "Type" key, but one was not set.
+var ErrMissingType = NewFieldError("Type")
+
 // ErrMissingURL is an error that is returned when an input struct
 // requires a "URL" key, but one was not set.
 var ErrMissingURL = NewFieldError("URL")
@@ -223,6 +271,10 @@ var ErrMissingWAFVersionNumber = NewFieldError("WAFVersionNumber")
 // "Year" key, but one was not set.
 var ErrMissingYear = NewFieldError("Year")
 
+// ErrMissingOriginRegion is an error that is returned when an input struct
+// requires either an "Origin" or "Region" key, but neither was set.
+var ErrMissingOriginRegion = NewFieldError("Origin, Region").Message("at least one of the available 'optional' fields is required")
+
 // ErrMissingOptionalNameComment is an error that is returned when an input
 // struct requires either a "Name" or "Comment" key, but one was not set.
 var ErrMissingOptionalNameComment = NewFieldError("Name, Comment").Message("at least one of the available 'optional' fields is required")
@@ -241,6 +293,19 @@ var ErrNotImplemented = errors.New("not implemented")
 // already enabled for a service.
 var ErrManagedLoggingEnabled = errors.New("managed logging already enabled")
 
+// ErrNoActiveVersion is an error that indicates that a service has no active
+// version.
+var ErrNoActiveVersion = errors.New("service has no active version")
+
+// ErrSnippetNotDynamic is an error that indicates that a snippet expected to
+// be dynamic is versioned.
+var ErrSnippetNotDynamic = errors.New("snippet is not dynamic")
+
+// ErrDynamicSnippetChanged is an error that indicates that the content of a
+// dynamic snippet was not the expected one, because it was changed by someone
+// else.
+var ErrDynamicSnippetChanged = errors.New("dynamic snippet content changed")
+
 // Ensure HTTPError is, in fact, an error.
 var _ error = (*HTTPError)(nil)
 
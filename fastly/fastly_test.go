@@ -1,8 +1,13 @@
 package fastly
 
 import (
+	"bytes"
 	"fmt"
+	"io/ioutil"
+	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 
@@ -22,13 +27,6 @@ var testServiceID = serviceIDForTest()
 // Default ID of the testing service.
 var defaultTestServiceID = "7i6HN3TK9wS159v2gPAZ8A"
 
-const (
-	// ServiceTypeVCL is the type for VCL services.
-	ServiceTypeVCL = "vcl"
-	// ServiceTypeWasm is the type for Wasm services.
-	ServiceTypeWasm = "wasm"
-)
-
 // testVersionLock is a lock around version creation because the Fastly API
 // kinda dies on concurrent requests to create a version.
 var testVersionLock sync.Mutex
@@ -81,6 +79,47 @@ func recordIgnoreBody(t *testing.T, fixture string, f func(*Client)) {
 	}
 }
 
+// writeTestFiles writes files to a temporary directory and returns it.
+func writeTestFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// requestCapture is an http.RoundTripper recording the requests sent through
+// it, so that tests can check what was created.
+type requestCapture struct {
+	next     http.RoundTripper
+	requests []string
+}
+
+func (rc *requestCapture) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	rc.requests = append(rc.requests, req.Method+" "+req.URL.Path+" "+string(body))
+	return rc.next.RoundTrip(req)
+}
+
+// find returns the body of the first request with the given method and path.
+func (rc *requestCapture) find(method, path string) (string, bool) {
+	for _, r := range rc.requests {
+		if strings.HasPrefix(r, method+" "+path+" ") {
+			return strings.TrimPrefix(r, method+" "+path+" "), true
+		}
+	}
+	return "", false
+}
+
 func getRecorder(t *testing.T, fixture string) *recorder.Recorder {
 	r, err := recorder.New("fixtures/" + fixture)
 	if err != nil {
@@ -0,0 +1,263 @@
+package fastly
+
+import (
+	"sort"
+	"strings"
+)
+
+// GzipPreset is a curated set of content types and extensions to compress.
+type GzipPreset struct {
+	Name         string
+	ContentTypes []string
+	Extensions   []string
+}
+
+var (
+	// GzipPresetText compresses HTML, plain text, CSS, CSV, Markdown and XML
+	// documents.
+	GzipPresetText = &GzipPreset{
+		Name: "text",
+		ContentTypes: []string{
+			"text/html", "text/plain", "text/css", "text/csv", "text/markdown", "text/xml",
+			"application/xml", "application/xhtml+xml", "application/rss+xml", "application/atom+xml",
+		},
+		Extensions: []string{"html", "htm", "txt", "css", "csv", "md", "xml", "rss"},
+	}
+
+	// GzipPresetWebFonts compresses the TrueType, OpenType and Embedded
+	// OpenType fonts. WOFF and WOFF2 fonts are already compressed.
+	GzipPresetWebFonts = &GzipPreset{
+		Name: "web fonts",
+		ContentTypes: []string{
+			"font/ttf", "font/otf", "application/x-font-ttf", "application/x-font-opentype",
+			"application/font-sfnt", "application/vnd.ms-fontobject",
+		},
+		Extensions: []string{"ttf", "otf", "eot"},
+	}
+
+	// GzipPresetJSONJavaScriptSVG compresses JSON documents, JavaScript,
+	// source maps and SVG images.
+	GzipPresetJSONJavaScriptSVG = &GzipPreset{
+		Name: "JSON, JavaScript and SVG",
+		ContentTypes: []string{
+			"application/json", "application/ld+json", "application/manifest+json",
+			"application/javascript", "application/x-javascript", "text/javascript",
+			"image/svg+xml",
+		},
+		Extensions: []string{"json", "js", "mjs", "map", "svg"},
+	}
+)
+
+// GzipPolicy is a set of content types and extensions to compress, which can
+// be built from presets, merged with existing Gzip configurations and turned
+// into the space separated strings used by the API. The zero value is an
+// empty policy.
+type GzipPolicy struct {
+	contentTypes map[string]bool
+	extensions   map[string]bool
+}
+
+// NewGzipPolicy returns a policy holding the content types and extensions of
+// presets.
+func NewGzipPolicy(presets ...*GzipPreset) *GzipPolicy {
+	return (&GzipPolicy{}).AddPresets(presets...)
+}
+
+// ParseGzip returns the policy of a Gzip configuration.
+func ParseGzip(g *Gzip) *GzipPolicy {
+	return (&GzipPolicy{}).
+		AddContentTypes(strings.Fields(g.ContentTypes)...).
+		AddExtensions(strings.Fields(g.Extensions)...)
+}
+
+// normalizeGzipContentType returns the form of a content type kept in a
+// policy.
+func normalizeGzipContentType(t string) string {
+	return strings.ToLower(strings.TrimSpace(t))
+}
+
+// normalizeGzipExtension returns the form of an extension kept in a policy,
+// without a leading dot.
+func normalizeGzipExtension(e string) string {
+	return strings.TrimPrefix(strings.ToLower(strings.TrimSpace(e)), ".")
+}
+
+// AddPresets adds the content types and extensions of presets to p and returns
+// p.
+func (p *GzipPolicy) AddPresets(presets ...*GzipPreset) *GzipPolicy {
+	for _, preset := range presets {
+		p.AddContentTypes(preset.ContentTypes...)
+		p.AddExtensions(preset.Extensions...)
+	}
+	return p
+}
+
+// AddContentTypes adds content types to p and returns p.
+func (p *GzipPolicy) AddContentTypes(contentTypes ...string) *GzipPolicy {
+	if p.contentTypes == nil {
+		p.contentTypes = make(map[string]bool)
+	}
+	for _, t := range contentTypes {
+		if t = normalizeGzipContentType(t); t != "" {
+			p.contentTypes[t] = true
+		}
+	}
+	return p
+}
+
+// AddExtensions adds extensions, with or without a leading dot, to p and
+// returns p.
+func (p *GzipPolicy) AddExtensions(extensions ...string) *GzipPolicy {
+	if p.extensions == nil {
+		p.extensions = make(map[string]bool)
+	}
+	for _, e := range extensions {
+		if e = normalizeGzipExtension(e); e != "" {
+			p.extensions[e] = true
+		}
+	}
+	return p
+}
+
+// RemoveContentTypes removes content types from p and returns p.
+func (p *GzipPolicy) RemoveContentTypes(contentTypes ...string) *GzipPolicy {
+	for _, t := range contentTypes {
+		delete(p.contentTypes, normalizeGzipContentType(t))
+	}
+	return p
+}
+
+// RemoveExtensions removes extensions from p and returns p.
+func (p *GzipPolicy) RemoveExtensions(extensions ...string) *GzipPolicy {
+	for _, e := range extensions {
+		delete(p.extensions, normalizeGzipExtension(e))
+	}
+	return p
+}
+
+// Merge adds the content types and extensions of other policies to p and
+// returns p.
+func (p *GzipPolicy) Merge(others ...*GzipPolicy) *GzipPolicy {
+	for _, o := range others {
+		p.AddContentTypes(o.ContentTypes()...)
+		p.AddExtensions(o.Extensions()...)
+	}
+	return p
+}
+
+// ContentTypes returns the content types of p, sorted.
+func (p *GzipPolicy) ContentTypes() []string {
+	return sortedSet(p.contentTypes)
+}
+
+// Extensions returns the extensions of p, sorted.
+func (p *GzipPolicy) Extensions() []string {
+	return sortedSet(p.extensions)
+}
+
+// ContentTypesString returns the content types of p in the form of the
+// ContentTypes of a Gzip.
+func (p *GzipPolicy) ContentTypesString() string {
+	return strings.Join(p.ContentTypes(), " ")
+}
+
+// ExtensionsString returns the extensions of p in the form of the Extensions
+// of a Gzip.
+func (p *GzipPolicy) ExtensionsString() string {
+	return strings.Join(p.Extensions(), " ")
+}
+
+// Equal reports whether p and other hold the same content types and
+// extensions.
+func (p *GzipPolicy) Equal(other *GzipPolicy) bool {
+	return p.ContentTypesString() == other.ContentTypesString() && p.ExtensionsString() == other.ExtensionsString()
+}
+
+// CreateInput returns the input creating a Gzip with the policy.
+func (p *GzipPolicy) CreateInput(serviceID string, serviceVersion int, name string) *CreateGzipInput {
+	return &CreateGzipInput{
+		ServiceID:      serviceID,
+		ServiceVersion: serviceVersion,
+		Name:           name,
+		ContentTypes:   p.ContentTypesString(),
+		Extensions:     p.ExtensionsString(),
+	}
+}
+
+// UpdateInput returns the input replacing the content types and extensions of
+// a Gzip with those of the policy.
+func (p *GzipPolicy) UpdateInput(serviceID string, serviceVersion int, name string) *UpdateGzipInput {
+	return &UpdateGzipInput{
+		ServiceID:      serviceID,
+		ServiceVersion: serviceVersion,
+		Name:           name,
+		ContentTypes:   String(p.ContentTypesString()),
+		Extensions:     String(p.ExtensionsString()),
+	}
+}
+
+// sortedSet returns the members of a set, sorted.
+func sortedSet(set map[string]bool) []string {
+	members := make([]string, 0, len(set))
+	for m := range set {
+		members = append(members, m)
+	}
+	sort.Strings(members)
+	return members
+}
+
+// GzipOverlap is a pair of Gzip configurations of a version which both
+// compress some content types or extensions.
+type GzipOverlap struct {
+	// Gzips are the names of the configurations, sorted.
+	Gzips [2]string
+
+	// ContentTypes and Extensions are those listed by both configurations.
+	ContentTypes []string
+	Extensions   []string
+
+	// Conditional reports whether either configuration has a cache
+	// condition, in which case both may never apply to the same response.
+	Conditional bool
+}
+
+// GzipOverlaps returns the overlaps between the Gzip configurations of a
+// version, such as those returned by ListGzips, ordered by the names of the
+// configurations.
+func GzipOverlaps(gzips []*Gzip) []*GzipOverlap {
+	sorted := append([]*Gzip(nil), gzips...)
+	sort.Stable(gzipsByName(sorted))
+
+	policies := make([]*GzipPolicy, len(sorted))
+	for n, g := range sorted {
+		policies[n] = ParseGzip(g)
+	}
+
+	var overlaps []*GzipOverlap
+	for a := range sorted {
+		for b := a + 1; b < len(sorted); b++ {
+			o := &GzipOverlap{
+				Gzips:        [2]string{sorted[a].Name, sorted[b].Name},
+				ContentTypes: intersectSets(policies[a].contentTypes, policies[b].contentTypes),
+				Extensions:   intersectSets(policies[a].extensions, policies[b].extensions),
+				Conditional:  sorted[a].CacheCondition != "" || sorted[b].CacheCondition != "",
+			}
+			if len(o.ContentTypes) > 0 || len(o.Extensions) > 0 {
+				overlaps = append(overlaps, o)
+			}
+		}
+	}
+	return overlaps
+}
+
+// intersectSets returns the sorted members of both a and b.
+func intersectSets(a, b map[string]bool) []string {
+	var members []string
+	for m := range a {
+		if b[m] {
+			members = append(members, m)
+		}
+	}
+	sort.Strings(members)
+	return members
+}
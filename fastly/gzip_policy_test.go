@@ -0,0 +1,83 @@
+package fastly
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestGzipPolicy(t *testing.T) {
+	t.Parallel()
+
+	p := NewGzipPolicy(GzipPresetJSONJavaScriptSVG, GzipPresetWebFonts)
+	if got := p.ExtensionsString(); got != "eot js json map mjs otf svg ttf" {
+		t.Errorf("bad extensions: %q", got)
+	}
+	if !strings.Contains(p.ContentTypesString(), "image/svg+xml") {
+		t.Errorf("bad content types: %q", p.ContentTypesString())
+	}
+
+	existing := ParseGzip(&Gzip{
+		ContentTypes: "text/html  Application/JSON text/html",
+		Extensions:   ".HTML css\thtml",
+	})
+	if got := existing.ContentTypesString(); got != "application/json text/html" {
+		t.Errorf("bad parsed content types: %q", got)
+	}
+	if got := existing.ExtensionsString(); got != "css html" {
+		t.Errorf("bad parsed extensions: %q", got)
+	}
+
+	merged := (&GzipPolicy{}).Merge(existing).
+		AddPresets(GzipPresetJSONJavaScriptSVG).
+		RemoveContentTypes("application/x-javascript", "IMAGE/SVG+XML").
+		RemoveExtensions(".map", "svg")
+	if got := merged.ContentTypesString(); got != "application/javascript application/json application/ld+json application/manifest+json text/html text/javascript" {
+		t.Errorf("bad merged content types: %q", got)
+	}
+	if got := merged.ExtensionsString(); got != "css html js json mjs" {
+		t.Errorf("bad merged extensions: %q", got)
+	}
+
+	if !ParseGzip(&Gzip{ContentTypes: "b a", Extensions: "x"}).Equal(NewGzipPolicy().AddContentTypes("a", "b").AddExtensions(".x")) {
+		t.Error("expected equal policies")
+	}
+
+	create := existing.CreateInput(testServiceID, 2, "gzip")
+	if create.ContentTypes != "application/json text/html" || create.Extensions != "css html" || create.Name != "gzip" {
+		t.Errorf("bad create input: %+v", create)
+	}
+	update := existing.UpdateInput(testServiceID, 2, "gzip")
+	if *update.ContentTypes != "application/json text/html" || *update.Extensions != "css html" {
+		t.Errorf("bad update input: %+v", update)
+	}
+
+	var empty GzipPolicy
+	if empty.ContentTypesString() != "" || empty.ExtensionsString() != "" {
+		t.Error("expected an empty policy")
+	}
+	empty.RemoveExtensions("js")
+}
+
+func TestGzipOverlaps(t *testing.T) {
+	t.Parallel()
+
+	overlaps := GzipOverlaps([]*Gzip{
+		{Name: "web", ContentTypes: "text/html application/json", Extensions: "html json"},
+		{Name: "api", ContentTypes: "application/json", Extensions: "json", CacheCondition: "is_api"},
+		{Name: "fonts", ContentTypes: "font/ttf", Extensions: "ttf"},
+		{Name: "legacy", ContentTypes: "TEXT/HTML", Extensions: ".css"},
+	})
+
+	var got []string
+	for _, o := range overlaps {
+		got = append(got, fmt.Sprintf("%s/%s: types=%v extensions=%v conditional=%t", o.Gzips[0], o.Gzips[1], o.ContentTypes, o.Extensions, o.Conditional))
+	}
+	expected := strings.Join([]string{
+		"api/web: types=[application/json] extensions=[json] conditional=true",
+		"legacy/web: types=[text/html] extensions=[] conditional=false",
+	}, "\n")
+	if strings.Join(got, "\n") != expected {
+		t.Errorf("bad overlaps:\n%s\nexpected:\n%s", strings.Join(got, "\n"), expected)
+	}
+}
@@ -0,0 +1,221 @@
+package fastly
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/fastly/go-fastly/v3/fastly/vcl"
+)
+
+// headerTypeVariables are the prefixes of the variables modified by each type
+// of header.
+var headerTypeVariables = map[HeaderType]string{
+	HeaderTypeRequest:  "req.",
+	HeaderTypeFetch:    "bereq.",
+	HeaderTypeCache:    "beresp.",
+	HeaderTypeResponse: "resp.",
+}
+
+// EvaluateHeadersInput is used as input to the EvaluateHeaders function.
+type EvaluateHeadersInput struct {
+	// Headers and Conditions are the objects of a version, as returned by
+	// ListHeaders and ListConditions.
+	Headers    []*Header
+	Conditions []*Condition
+
+	// Type selects the headers to apply (required).
+	Type HeaderType
+
+	// Header holds the headers of the object modified by Type, such as the
+	// client request for HeaderTypeRequest.
+	Header http.Header
+
+	// Env holds the other variables used by sources and conditions, such as
+	// req.url or beresp.status.
+	Env vcl.Env
+}
+
+// HeaderTraceEntry reports what a header rule did.
+type HeaderTraceEntry struct {
+	Name        string
+	Priority    uint
+	Action      HeaderAction
+	Destination string
+	Condition   string
+
+	// Applied reports whether the rule changed or could have changed the
+	// destination. Reason explains why it did not.
+	Applied bool
+	Reason  string
+
+	// Before and After are the values of the destination, nil when not set.
+	Before *string
+	After  *string
+}
+
+// HeaderEvaluation is the result of EvaluateHeaders.
+type HeaderEvaluation struct {
+	// Header holds the final headers.
+	Header http.Header
+
+	// Trace lists the rules of the type in the order they were evaluated.
+	Trace []*HeaderTraceEntry
+}
+
+// EvaluateHeaders applies locally the header rules of a type to a sample set
+// of headers. Rules are applied by increasing priority, and by name for rules
+// of equal priority, so that a rule sees the changes made by the previous ones.
+//
+// The condition of a rule is its request condition for the request and fetch
+// types, its cache condition for the cache type and its response condition for
+// the response type. Conditions and sources are evaluated by vcl.Eval with the
+// sample headers and the variables of Env. The destination of a rule is a
+// variable relative to the object modified, such as "http.X-Foo" or "url";
+// only the http.* destinations are part of the final headers.
+func EvaluateHeaders(i *EvaluateHeadersInput) (*HeaderEvaluation, error) {
+	if i.Type == "" {
+		return nil, ErrMissingType
+	}
+	prefix, ok := headerTypeVariables[i.Type]
+	if !ok {
+		return nil, NewFieldError("Type").Message(fmt.Sprintf("unknown header type %q", i.Type))
+	}
+
+	env := make(vcl.Env, len(i.Env))
+	for k, v := range i.Env {
+		env[k] = v
+	}
+	result := &HeaderEvaluation{Header: make(http.Header, len(i.Header))}
+	for name, values := range i.Header {
+		result.Header[http.CanonicalHeaderKey(name)] = append([]string(nil), values...)
+	}
+	env.SetHeaders(prefix+"http.", result.Header)
+
+	var rules []*Header
+	for _, h := range i.Headers {
+		if h.Type == i.Type {
+			rules = append(rules, h)
+		}
+	}
+	sort.SliceStable(rules, func(a, b int) bool {
+		if rules[a].Priority != rules[b].Priority {
+			return rules[a].Priority < rules[b].Priority
+		}
+		return rules[a].Name < rules[b].Name
+	})
+
+	conditions := newConditionSet(i.Conditions)
+	for _, h := range rules {
+		entry, err := applyHeaderRule(h, prefix, conditions, env, result.Header)
+		if err != nil {
+			return nil, fmt.Errorf("header %q: %s", h.Name, err)
+		}
+		result.Trace = append(result.Trace, entry)
+	}
+	return result, nil
+}
+
+// applyHeaderRule applies a header rule to env and header.
+func applyHeaderRule(h *Header, prefix string, conditions conditionSet, env vcl.Env, header http.Header) (*HeaderTraceEntry, error) {
+	entry := &HeaderTraceEntry{
+		Name:        h.Name,
+		Priority:    h.Priority,
+		Action:      h.Action,
+		Destination: h.Destination,
+	}
+
+	var conditionTypes []string
+	switch h.Type {
+	case HeaderTypeRequest:
+		entry.Condition, conditionTypes = h.RequestCondition, []string{"REQUEST"}
+	case HeaderTypeFetch:
+		entry.Condition, conditionTypes = h.RequestCondition, []string{"REQUEST", "PREFETCH"}
+	case HeaderTypeCache:
+		entry.Condition, conditionTypes = h.CacheCondition, []string{"CACHE"}
+	case HeaderTypeResponse:
+		entry.Condition, conditionTypes = h.ResponseCondition, []string{"RESPONSE"}
+	}
+
+	variable := prefix + h.Destination
+	if v, ok := env.Lookup(variable); ok && v != nil {
+		entry.Before = String(vcl.ToString(v))
+	}
+	entry.After = entry.Before
+
+	m, err := conditions.match(entry.Condition, env, conditionTypes...)
+	if err != nil {
+		return nil, err
+	}
+	if !m {
+		entry.Reason = "condition is false"
+		return entry, nil
+	}
+	if h.IgnoreIfSet && entry.Before != nil && h.Action != HeaderActionDelete {
+		entry.Reason = "destination is set"
+		return entry, nil
+	}
+
+	var value string
+	if h.Action != HeaderActionDelete {
+		if value, err = evalHeaderSource(h.Source, env); err != nil {
+			return nil, err
+		}
+	}
+
+	entry.Applied = true
+	switch h.Action {
+	case HeaderActionSet:
+		entry.After = String(value)
+	case HeaderActionAppend:
+		if entry.Before != nil {
+			value = *entry.Before + value
+		}
+		entry.After = String(value)
+	case HeaderActionDelete:
+		entry.After = nil
+	case HeaderActionRegex, HeaderActionRegexRepeat:
+		s, err := vcl.Regsub(value, h.Regex, h.Substitution, h.Action == HeaderActionRegexRepeat)
+		if err != nil {
+			return nil, err
+		}
+		entry.After = String(s)
+	default:
+		return nil, fmt.Errorf("unknown action %q", h.Action)
+	}
+
+	name := strings.TrimPrefix(h.Destination, "http.")
+	isHeader := name != h.Destination
+	if isHeader {
+		variable = prefix + "http." + strings.ToLower(name)
+	}
+	if entry.After == nil {
+		delete(env, variable)
+		if isHeader {
+			header.Del(name)
+		}
+	} else {
+		env[variable] = *entry.After
+		if isHeader {
+			header.Set(name, *entry.After)
+		}
+	}
+	return entry, nil
+}
+
+// evalHeaderSource evaluates the source expression of a header rule.
+func evalHeaderSource(src string, env vcl.Env) (string, error) {
+	if strings.TrimSpace(src) == "" {
+		return "", nil
+	}
+	x, err := vcl.ParseExpr(src)
+	if err != nil {
+		return "", fmt.Errorf("source: %s", err)
+	}
+	v, err := vcl.Eval(x, env)
+	if err != nil {
+		return "", fmt.Errorf("source: %s", err)
+	}
+	return vcl.ToString(v), nil
+}
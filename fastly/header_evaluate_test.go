@@ -0,0 +1,127 @@
+package fastly
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/fastly/go-fastly/v3/fastly/vcl"
+)
+
+func headerTraceString(trace []*HeaderTraceEntry) string {
+	value := func(s *string) string {
+		if s == nil {
+			return "<unset>"
+		}
+		return *s
+	}
+	var lines []string
+	for _, e := range trace {
+		line := fmt.Sprintf("%s %s %s: %s -> %s", e.Name, e.Action, e.Destination, value(e.Before), value(e.After))
+		if !e.Applied {
+			line += " (" + e.Reason + ")"
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestEvaluateHeaders(t *testing.T) {
+	t.Parallel()
+
+	headers := []*Header{
+		{Name: "strip cookies", Type: HeaderTypeRequest, Action: HeaderActionDelete, Destination: "http.Cookie", Priority: 10, RequestCondition: "is_api"},
+		{Name: "api version", Type: HeaderTypeRequest, Action: HeaderActionRegex, Destination: "http.X-API-Version", Source: "req.url.path", Regex: "^/api/(v[0-9]+)/.*$", Substitution: "\\1", Priority: 20},
+		{Name: "trace", Type: HeaderTypeRequest, Action: HeaderActionAppend, Destination: "http.X-Trace", Source: `",edge"`, Priority: 30},
+		{Name: "default lang", Type: HeaderTypeRequest, Action: HeaderActionSet, Destination: "http.Accept-Language", Source: `"en"`, IgnoreIfSet: true, Priority: 30},
+		{Name: "dashes", Type: HeaderTypeRequest, Action: HeaderActionRegexRepeat, Destination: "http.X-Slug", Source: "req.http.X-Slug", Regex: "_", Substitution: "-", Priority: 40},
+		{Name: "copy version", Type: HeaderTypeRequest, Action: HeaderActionSet, Destination: "http.X-Backend-Version", Source: `"api-" req.http.X-API-Version`, Priority: 50},
+		{Name: "private", Type: HeaderTypeRequest, Action: HeaderActionSet, Destination: "http.X-Internal", Source: `"1"`, Priority: 60, RequestCondition: "is_internal"},
+		{Name: "response only", Type: HeaderTypeResponse, Action: HeaderActionSet, Destination: "http.X-Served-By", Source: `"edge"`},
+	}
+	conditions := []*Condition{
+		{Name: "is_api", Type: "REQUEST", Statement: `req.url ~ "^/api/"`},
+		{Name: "is_internal", Type: "REQUEST", Statement: `req.http.X-Internal-Token == "secret"`},
+	}
+
+	e, err := EvaluateHeaders(&EvaluateHeadersInput{
+		Headers:    headers,
+		Conditions: conditions,
+		Type:       HeaderTypeRequest,
+		Header: http.Header{
+			"Cookie":          {"session=1"},
+			"X-Trace":         {"client"},
+			"Accept-Language": {"fr"},
+			"x-slug":          {"a_b_c"},
+		},
+		Env: vcl.Env{"req.url": "/api/v2/users", "req.url.path": "/api/v2/users"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := strings.Join([]string{
+		"strip cookies delete http.Cookie: session=1 -> <unset>",
+		"api version regex http.X-API-Version: <unset> -> v2",
+		"default lang set http.Accept-Language: fr -> fr (destination is set)",
+		"trace append http.X-Trace: client -> client,edge",
+		"dashes regex_repeat http.X-Slug: a_b_c -> a-b-c",
+		"copy version set http.X-Backend-Version: <unset> -> api-v2",
+		"private set http.X-Internal: <unset> -> <unset> (condition is false)",
+	}, "\n")
+	if got := headerTraceString(e.Trace); got != expected {
+		t.Errorf("bad trace:\n%s\nexpected:\n%s", got, expected)
+	}
+
+	expectedHeader := map[string]string{
+		"Accept-Language":   "fr",
+		"X-Api-Version":     "v2",
+		"X-Backend-Version": "api-v2",
+		"X-Slug":            "a-b-c",
+		"X-Trace":           "client,edge",
+	}
+	if len(e.Header) != len(expectedHeader) {
+		t.Errorf("bad headers: %v", e.Header)
+	}
+	for name, value := range expectedHeader {
+		if got := e.Header.Get(name); got != value {
+			t.Errorf("bad %s: %q, want %q", name, got, value)
+		}
+	}
+}
+
+func TestEvaluateHeaders_validation(t *testing.T) {
+	t.Parallel()
+
+	_, err := EvaluateHeaders(&EvaluateHeadersInput{})
+	if err != ErrMissingType {
+		t.Errorf("bad error: %s", err)
+	}
+
+	_, err = EvaluateHeaders(&EvaluateHeadersInput{Type: "nope"})
+	if err == nil || err.Error() != `problem with field 'Type': unknown header type "nope"` {
+		t.Errorf("bad error: %v", err)
+	}
+
+	_, err = EvaluateHeaders(&EvaluateHeadersInput{
+		Type: HeaderTypeCache,
+		Headers: []*Header{
+			{Name: "bad", Type: HeaderTypeCache, Action: HeaderActionSet, Destination: "http.X", Source: `"a" ==`},
+		},
+	})
+	if err == nil || err.Error() != `header "bad": source: 1:7: unexpected end of file, expected expression` {
+		t.Errorf("bad error: %v", err)
+	}
+
+	_, err = EvaluateHeaders(&EvaluateHeadersInput{
+		Type: HeaderTypeResponse,
+		Headers: []*Header{
+			{Name: "c", Type: HeaderTypeResponse, Action: HeaderActionSet, Destination: "http.X", Source: `"a"`, ResponseCondition: "is_api"},
+		},
+		Conditions: []*Condition{{Name: "is_api", Type: "REQUEST", Statement: "true"}},
+	})
+	if err == nil || err.Error() != `header "c": condition "is_api" is a REQUEST condition, not RESPONSE` {
+		t.Errorf("bad error: %v", err)
+	}
+}
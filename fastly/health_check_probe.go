@@ -0,0 +1,263 @@
+package fastly
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The values the API uses for health check fields that are not set.
+const (
+	defaultHealthCheckMethod           = "HEAD"
+	defaultHealthCheckPath             = "/"
+	defaultHealthCheckHTTPVersion      = "1.1"
+	defaultHealthCheckTimeout          = 500
+	defaultHealthCheckInterval         = 5000
+	defaultHealthCheckExpectedResponse = 200
+	defaultHealthCheckWindow           = 2
+)
+
+// ProbeHealthCheckInput is used as input to the ProbeHealthCheck function.
+type ProbeHealthCheckInput struct {
+	// HealthCheck is the health check definition to emulate (required).
+	// Unset fields take the API defaults, except Initial and Threshold, for
+	// which zero is a valid value and which are used as given.
+	HealthCheck *HealthCheck
+
+	// Address is the host:port of the backend to probe (required).
+	Address string
+
+	// UseSSL probes the backend over TLS, like a backend with UseSSL set.
+	UseSSL bool
+
+	// SSLSNIHostname is the server name sent when UseSSL is set. It
+	// defaults to the health check Host.
+	SSLSNIHostname string
+
+	// SSLCheckCert verifies the backend's certificate against the server
+	// name when UseSSL is set.
+	SSLCheckCert bool
+
+	// Probes is the number of probes to run. It defaults to the window size,
+	// enough for the initial state to be fully replaced.
+	Probes int
+
+	// Interval overrides the health check's CheckInterval between probes,
+	// e.g. to get a result sooner.
+	Interval time.Duration
+}
+
+// ProbeResult is the outcome of a single probe.
+type ProbeResult struct {
+	// Status is the response status code, or zero when no response was read.
+	Status int
+
+	// Duration is how long the probe took.
+	Duration time.Duration
+
+	// Err is the reason the probe failed to get a response, e.g. a timeout.
+	Err error
+
+	// Good is whether the probe counts as successful: a response with the
+	// expected status received within the timeout.
+	Good bool
+
+	// Healthy is whether the backend is considered healthy after this probe.
+	Healthy bool
+}
+
+// HealthCheckReport is the result of ProbeHealthCheck.
+type HealthCheckReport struct {
+	// Probes are the probes run, in order.
+	Probes []*ProbeResult
+
+	// InitiallyHealthy is whether the backend starts out healthy, before any
+	// probe, based on Initial and Threshold.
+	InitiallyHealthy bool
+
+	// Healthy is whether the backend is considered healthy after the last
+	// probe.
+	Healthy bool
+}
+
+// ProbeHealthCheck runs a health check definition locally against a backend
+// and reports whether Fastly would consider the backend healthy.
+//
+// Like Varnish, it keeps the results of the last Window probes, starting with
+// Initial good ones, and considers the backend healthy while at least
+// Threshold of them are good. A probe is good when a response with
+// ExpectedResponse status arrives within Timeout.
+func ProbeHealthCheck(ctx context.Context, i *ProbeHealthCheckInput) (*HealthCheckReport, error) {
+	if i.HealthCheck == nil {
+		return nil, ErrMissingHealthCheck
+	}
+
+	if i.Address == "" {
+		return nil, ErrMissingAddress
+	}
+
+	hc := withHealthCheckDefaults(i.HealthCheck)
+
+	interval := i.Interval
+	if interval == 0 {
+		interval = time.Duration(hc.CheckInterval) * time.Millisecond
+	}
+	probes := i.Probes
+	if probes == 0 {
+		probes = int(hc.Window)
+	}
+
+	window := newProbeWindow(hc.Window, hc.Initial)
+	report := &HealthCheckReport{InitiallyHealthy: window.good() >= hc.Threshold}
+
+	for n := 0; n < probes; n++ {
+		if n > 0 {
+			select {
+			case <-ctx.Done():
+				return report, ctx.Err()
+			case <-time.After(interval):
+			}
+		}
+
+		r := probeOnce(ctx, hc, i)
+		window.push(r.Good)
+		r.Healthy = window.good() >= hc.Threshold
+		report.Probes = append(report.Probes, r)
+		report.Healthy = r.Healthy
+	}
+	return report, nil
+}
+
+// withHealthCheckDefaults returns a copy of hc with unset fields defaulted.
+// Initial and Threshold are kept, since zero is meaningful for both.
+func withHealthCheckDefaults(hc *HealthCheck) *HealthCheck {
+	c := *hc
+	if c.Method == "" {
+		c.Method = defaultHealthCheckMethod
+	}
+	if c.Path == "" {
+		c.Path = defaultHealthCheckPath
+	}
+	if c.HTTPVersion == "" {
+		c.HTTPVersion = defaultHealthCheckHTTPVersion
+	}
+	if c.Timeout == 0 {
+		c.Timeout = defaultHealthCheckTimeout
+	}
+	if c.CheckInterval == 0 {
+		c.CheckInterval = defaultHealthCheckInterval
+	}
+	if c.ExpectedResponse == 0 {
+		c.ExpectedResponse = defaultHealthCheckExpectedResponse
+	}
+	if c.Window == 0 {
+		c.Window = defaultHealthCheckWindow
+	}
+	return &c
+}
+
+// probeWindow holds the results of the most recent probes.
+type probeWindow struct {
+	results []bool
+	size    int
+}
+
+// newProbeWindow returns a window of the given size, pre-filled with initial
+// good results.
+func newProbeWindow(size, initial uint) *probeWindow {
+	w := &probeWindow{size: int(size)}
+	for n := uint(0); n < initial && n < size; n++ {
+		w.results = append(w.results, true)
+	}
+	return w
+}
+
+func (w *probeWindow) push(good bool) {
+	w.results = append(w.results, good)
+	if len(w.results) > w.size {
+		w.results = w.results[len(w.results)-w.size:]
+	}
+}
+
+func (w *probeWindow) good() uint {
+	var n uint
+	for _, r := range w.results {
+		if r {
+			n++
+		}
+	}
+	return n
+}
+
+// probeOnce sends a single probe request and reads the status line of the
+// response, the way a Varnish probe does.
+func probeOnce(ctx context.Context, hc *HealthCheck, i *ProbeHealthCheckInput) *ProbeResult {
+	start := time.Now()
+	r := &ProbeResult{}
+	defer func() { r.Duration = time.Since(start) }()
+
+	timeout := time.Duration(hc.Timeout) * time.Millisecond
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", i.Address)
+	if err != nil {
+		r.Err = err
+		return r
+	}
+	defer conn.Close()
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+
+	if i.UseSSL {
+		sni := i.SSLSNIHostname
+		if sni == "" {
+			sni = hc.Host
+		}
+		tc := tls.Client(conn, &tls.Config{ServerName: sni, InsecureSkipVerify: !i.SSLCheckCert})
+		if err := tc.Handshake(); err != nil {
+			r.Err = err
+			return r
+		}
+		conn = tc
+	}
+
+	host := hc.Host
+	if host == "" {
+		host = i.Address
+	}
+	req := fmt.Sprintf("%s %s HTTP/%s\r\nHost: %s\r\nConnection: close\r\nUser-Agent: Varnish/fastly (healthcheck)\r\n\r\n",
+		hc.Method, hc.Path, hc.HTTPVersion, host)
+	if _, err := conn.Write([]byte(req)); err != nil {
+		r.Err = err
+		return r
+	}
+
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		r.Err = err
+		return r
+	}
+	r.Status, r.Err = parseStatusLine(line)
+	r.Good = r.Err == nil && r.Status == int(hc.ExpectedResponse)
+	return r
+}
+
+// parseStatusLine returns the status code of an HTTP response status line.
+func parseStatusLine(line string) (int, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 || !strings.HasPrefix(fields[0], "HTTP/") {
+		return 0, fmt.Errorf("invalid status line %q", strings.TrimSpace(line))
+	}
+	status, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return 0, fmt.Errorf("invalid status line %q", strings.TrimSpace(line))
+	}
+	return status, nil
+}
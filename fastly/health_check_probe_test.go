@@ -0,0 +1,177 @@
+package fastly
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// serveProbes answers probes on a local listener with the given status lines,
+// one per connection, and records the request lines received. An empty status
+// leaves the connection open without answering.
+func serveProbes(t *testing.T, statuses ...string) (string, func() []string) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var requests []string
+	var idle []net.Conn
+	go func() {
+		for _, status := range statuses {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			r := bufio.NewReader(conn)
+			line, _ := r.ReadString('\n')
+			host, _ := r.ReadString('\n')
+			mu.Lock()
+			requests = append(requests, strings.TrimSpace(line)+" "+strings.TrimSpace(host))
+			if status == "" {
+				idle = append(idle, conn)
+				mu.Unlock()
+				continue
+			}
+			mu.Unlock()
+			conn.Write([]byte(status + "\r\n\r\n"))
+			conn.Close()
+		}
+	}()
+	t.Cleanup(func() {
+		l.Close()
+		mu.Lock()
+		for _, conn := range idle {
+			conn.Close()
+		}
+		mu.Unlock()
+	})
+
+	return l.Addr().String(), func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), requests...)
+	}
+}
+
+func TestProbeHealthCheck(t *testing.T) {
+	t.Parallel()
+
+	addr, requests := serveProbes(t,
+		"HTTP/1.1 200 OK",
+		"HTTP/1.1 503 Service Unavailable",
+		"HTTP/1.1 503 Service Unavailable",
+		"HTTP/1.1 200 OK",
+	)
+
+	r, err := ProbeHealthCheck(context.Background(), &ProbeHealthCheckInput{
+		HealthCheck: &HealthCheck{
+			Method:    "GET",
+			Host:      "example.com",
+			Path:      "/health",
+			Window:    3,
+			Threshold: 2,
+			Initial:   2,
+		},
+		Address:  addr,
+		Probes:   4,
+		Interval: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !r.InitiallyHealthy {
+		t.Error("expected the backend to start healthy")
+	}
+	// The window goes [ok ok] -> [ok ok ok] -> [ok ok bad] -> [ok bad bad]
+	// -> [bad bad ok].
+	want := []struct {
+		status  int
+		healthy bool
+	}{{200, true}, {503, true}, {503, false}, {200, false}}
+	if len(r.Probes) != len(want) {
+		t.Fatalf("bad probes: %d", len(r.Probes))
+	}
+	for n, w := range want {
+		p := r.Probes[n]
+		if p.Err != nil || p.Status != w.status || p.Good != (w.status == 200) || p.Healthy != w.healthy {
+			t.Errorf("bad probe %d: %#v", n, p)
+		}
+	}
+	if r.Healthy {
+		t.Error("expected the backend to end unhealthy")
+	}
+
+	reqs := requests()
+	if len(reqs) == 0 || reqs[0] != "GET /health HTTP/1.1 Host: example.com" {
+		t.Errorf("bad requests: %q", reqs)
+	}
+}
+
+func TestProbeHealthCheck_timeout(t *testing.T) {
+	t.Parallel()
+
+	// The server never answers, so the probe times out.
+	addr, _ := serveProbes(t, "")
+
+	r, err := ProbeHealthCheck(context.Background(), &ProbeHealthCheckInput{
+		HealthCheck: &HealthCheck{Timeout: 50, Initial: 1, Threshold: 1, Window: 1},
+		Address:     addr,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Probes) != 1 || r.Probes[0].Good {
+		t.Fatalf("bad probes: %#v", r.Probes)
+	}
+	if err, ok := r.Probes[0].Err.(net.Error); !ok || !err.Timeout() {
+		t.Fatalf("bad probes: %#v", r.Probes)
+	}
+	if r.Healthy {
+		t.Error("expected the backend to be unhealthy")
+	}
+}
+
+func TestProbeHealthCheck_initialUnhealthy(t *testing.T) {
+	t.Parallel()
+
+	addr, _ := serveProbes(t, "HTTP/1.1 200 OK", "HTTP/1.1 200 OK")
+
+	// With no initial good probes the backend starts unhealthy and only
+	// becomes healthy once Threshold probes succeed.
+	r, err := ProbeHealthCheck(context.Background(), &ProbeHealthCheckInput{
+		HealthCheck: &HealthCheck{Window: 2, Threshold: 2, Initial: 0},
+		Address:     addr,
+		Probes:      2,
+		Interval:    1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.InitiallyHealthy {
+		t.Error("expected the backend to start unhealthy")
+	}
+	if len(r.Probes) != 2 || r.Probes[0].Healthy || !r.Probes[1].Healthy {
+		t.Fatalf("bad probes: %#v", r.Probes)
+	}
+}
+
+func TestProbeHealthCheck_validation(t *testing.T) {
+	var err error
+	_, err = ProbeHealthCheck(context.Background(), &ProbeHealthCheckInput{})
+	if err != ErrMissingHealthCheck {
+		t.Errorf("bad error: %s", err)
+	}
+
+	_, err = ProbeHealthCheck(context.Background(), &ProbeHealthCheckInput{
+		HealthCheck: &HealthCheck{},
+	})
+	if err != ErrMissingAddress {
+		t.Errorf("bad error: %s", err)
+	}
+}
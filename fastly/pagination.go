@@ -0,0 +1,146 @@
+package fastly
+
+// DefaultPaginationPerPage is the page size used by the list iterators when
+// none is given.
+const DefaultPaginationPerPage = 100
+
+// pager fetches the pages of a list one at a time.
+type pager struct {
+	page    int
+	perPage int
+	done    bool
+	err     error
+
+	// fetch returns the number of results of the given page.
+	fetch func(page, perPage int) (int, error)
+}
+
+// next fetches the next page. It returns false when there are no more pages
+// or an error occurred.
+func (p *pager) next() bool {
+	if p.done || p.err != nil {
+		return false
+	}
+	if p.perPage == 0 {
+		p.perPage = DefaultPaginationPerPage
+	}
+
+	p.page++
+	n, err := p.fetch(p.page, p.perPage)
+	if err != nil {
+		p.err = err
+		return false
+	}
+	// A short page is the last one.
+	if n < p.perPage {
+		p.done = true
+	}
+	return n > 0
+}
+
+// DictionaryItemsIterator streams the items of a dictionary page by page. It
+// is used like:
+//
+//	it := client.IterateDictionaryItems(&fastly.ListDictionaryItemsInput{...})
+//	for it.Next() {
+//		item := it.Item()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type DictionaryItemsIterator struct {
+	pager
+	items []*DictionaryItem
+	item  *DictionaryItem
+}
+
+// IterateDictionaryItems returns an iterator over the items of a dictionary,
+// fetching pages of i.PerPage items (DefaultPaginationPerPage when zero) as
+// they are needed. Page is ignored. Sort defaults to "item_key" so that the
+// pages are consistent.
+func (c *Client) IterateDictionaryItems(i *ListDictionaryItemsInput) *DictionaryItemsIterator {
+	in := *i
+	if in.Sort == "" {
+		in.Sort = "item_key"
+	}
+
+	it := &DictionaryItemsIterator{}
+	it.perPage = in.PerPage
+	it.fetch = func(page, perPage int) (int, error) {
+		in.Page, in.PerPage = page, perPage
+		items, err := c.ListDictionaryItems(&in)
+		it.items = items
+		return len(items), err
+	}
+	return it
+}
+
+// Next advances to the next item, fetching the next page when needed. It
+// returns false at the end of the items or on error.
+func (it *DictionaryItemsIterator) Next() bool {
+	if len(it.items) == 0 && !it.next() {
+		it.item = nil
+		return false
+	}
+	it.item, it.items = it.items[0], it.items[1:]
+	return true
+}
+
+// Item returns the current item.
+func (it *DictionaryItemsIterator) Item() *DictionaryItem { return it.item }
+
+// Page returns the number of the page the current item is from.
+func (it *DictionaryItemsIterator) Page() int { return it.page }
+
+// Err returns the error which stopped the iteration, if any.
+func (it *DictionaryItemsIterator) Err() error { return it.err }
+
+// ACLEntriesIterator streams the entries of an ACL page by page. It is used
+// like DictionaryItemsIterator.
+type ACLEntriesIterator struct {
+	pager
+	entries []*ACLEntry
+	entry   *ACLEntry
+}
+
+// IterateACLEntries returns an iterator over the entries of an ACL, fetching
+// pages of i.PerPage entries (DefaultPaginationPerPage when zero) as they are
+// needed. Page is ignored. Sort defaults to "created" so that the pages are
+// consistent.
+func (c *Client) IterateACLEntries(i *ListACLEntriesInput) *ACLEntriesIterator {
+	in := *i
+	if in.Sort == "" {
+		in.Sort = "created"
+	}
+
+	it := &ACLEntriesIterator{}
+	it.perPage = in.PerPage
+	it.fetch = func(page, perPage int) (int, error) {
+		in.Page, in.PerPage = page, perPage
+		entries, err := c.ListACLEntries(&in)
+		it.entries = entries
+		return len(entries), err
+	}
+	return it
+}
+
+// Next advances to the next entry, fetching the next page when needed. It
+// returns false at the end of the entries or on error.
+func (it *ACLEntriesIterator) Next() bool {
+	if len(it.entries) == 0 && !it.next() {
+		it.entry = nil
+		return false
+	}
+	it.entry, it.entries = it.entries[0], it.entries[1:]
+	return true
+}
+
+// Entry returns the current entry.
+func (it *ACLEntriesIterator) Entry() *ACLEntry { return it.entry }
+
+// Page returns the number of the page the current entry is from.
+func (it *ACLEntriesIterator) Page() int { return it.page }
+
+// Err returns the error which stopped the iteration, if any.
+func (it *ACLEntriesIterator) Err() error { return it.err }
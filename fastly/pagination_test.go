@@ -0,0 +1,100 @@
+package fastly
+
+import (
+	"testing"
+)
+
+func TestClient_ListDictionaryItems_paginated(t *testing.T) {
+	t.Parallel()
+
+	var err error
+	var items []*DictionaryItem
+	record(t, "dictionary_items/list_paginated", func(c *Client) {
+		items, err = c.ListDictionaryItems(&ListDictionaryItemsInput{
+			ServiceID:    testServiceID,
+			DictionaryID: "4s2r3X4mVt8kQ8ps50jz7w",
+			Page:         1,
+			PerPage:      2,
+			Sort:         "item_key",
+			Direction:    "descend",
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The API order is kept when sorting is requested.
+	if len(items) != 2 || items[0].ItemKey != "key3" || items[1].ItemKey != "key2" {
+		t.Errorf("bad items: %v", items)
+	}
+}
+
+func TestClient_IterateDictionaryItems(t *testing.T) {
+	t.Parallel()
+
+	var keys []string
+	var pages []int
+	var err error
+	record(t, "dictionary_items/iterate", func(c *Client) {
+		it := c.IterateDictionaryItems(&ListDictionaryItemsInput{
+			ServiceID:    testServiceID,
+			DictionaryID: "4s2r3X4mVt8kQ8ps50jz7w",
+			PerPage:      2,
+		})
+		for it.Next() {
+			keys = append(keys, it.Item().ItemKey)
+			pages = append(pages, it.Page())
+		}
+		err = it.Err()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 3 || keys[0] != "key1" || keys[2] != "key3" {
+		t.Errorf("bad keys: %v", keys)
+	}
+	if len(pages) != 3 || pages[1] != 1 || pages[2] != 2 {
+		t.Errorf("bad pages: %v", pages)
+	}
+}
+
+func TestClient_IterateACLEntries(t *testing.T) {
+	t.Parallel()
+
+	var ids []string
+	var err error
+	record(t, "acl_entries/iterate", func(c *Client) {
+		it := c.IterateACLEntries(&ListACLEntriesInput{
+			ServiceID: testServiceID,
+			ACLID:     "2mI5c4hlwXTRJHkpBi5XZc",
+			PerPage:   2,
+		})
+		for it.Next() {
+			ids = append(ids, it.Entry().ID)
+		}
+		err = it.Err()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 || ids[0] != "e1" || ids[1] != "e2" {
+		t.Errorf("bad entries: %v", ids)
+	}
+}
+
+func TestClient_IterateDictionaryItems_validation(t *testing.T) {
+	it := testClient.IterateDictionaryItems(&ListDictionaryItemsInput{})
+	if it.Next() {
+		t.Error("expected no items")
+	}
+	if it.Err() != ErrMissingServiceID {
+		t.Errorf("bad error: %s", it.Err())
+	}
+
+	ait := testClient.IterateACLEntries(&ListACLEntriesInput{ServiceID: "foo"})
+	if ait.Next() {
+		t.Error("expected no entries")
+	}
+	if ait.Err() != ErrMissingACLID {
+		t.Errorf("bad error: %s", ait.Err())
+	}
+}
@@ -0,0 +1,373 @@
+package fastly
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+)
+
+// MaxResponseObjectSize is the largest content, in bytes, allowed in a
+// response object rendered from a template.
+const MaxResponseObjectSize = 64 * 1024
+
+// templateContentTypes are the content types of common template extensions,
+// which do not depend on the MIME types known to the system.
+var templateContentTypes = map[string]string{
+	".html": "text/html; charset=utf-8",
+	".htm":  "text/html; charset=utf-8",
+	".txt":  "text/plain; charset=utf-8",
+	".json": "application/json",
+	".xml":  "application/xml",
+	".css":  "text/css; charset=utf-8",
+	".js":   "application/javascript",
+	".svg":  "image/svg+xml",
+}
+
+// ResponseObjectTemplate describes a response object whose content is
+// rendered from a Go template.
+type ResponseObjectTemplate struct {
+	Name             string
+	Status           uint
+	Response         string
+	ContentType      string
+	RequestCondition string
+	CacheCondition   string
+
+	// Template is the source of the template. It is an html/template when
+	// ContentType is HTML and a text/template otherwise.
+	Template string
+}
+
+// Render executes the template with vars, which are available as {{.Name}}.
+// The variables Name and Status hold those of the template unless vars sets
+// them. A variable missing from vars is an error. The content is checked, see
+// CheckResponseObjectContent.
+func (t *ResponseObjectTemplate) Render(vars map[string]interface{}) (string, error) {
+	data := map[string]interface{}{
+		"Name":   t.Name,
+		"Status": t.Status,
+	}
+	for k, v := range vars {
+		data[k] = v
+	}
+
+	var buf bytes.Buffer
+	mediaType, _, _ := mime.ParseMediaType(t.ContentType)
+	if mediaType == "text/html" {
+		tmpl, err := htmltemplate.New(t.Name).Option("missingkey=error").Parse(t.Template)
+		if err != nil {
+			return "", err
+		}
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return "", err
+		}
+	} else {
+		tmpl, err := template.New(t.Name).Option("missingkey=error").Parse(t.Template)
+		if err != nil {
+			return "", err
+		}
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return "", err
+		}
+	}
+
+	content := buf.String()
+	if err := CheckResponseObjectContent(content, t.ContentType); err != nil {
+		return "", fmt.Errorf("response object %q: %s", t.Name, err)
+	}
+	return content, nil
+}
+
+// CheckResponseObjectContent checks that content fits in a response object
+// and matches its content type: it must be at most MaxResponseObjectSize bytes,
+// contentType must be a valid media type, text must be valid UTF-8 and JSON
+// must be well formed.
+func CheckResponseObjectContent(content, contentType string) error {
+	if len(content) > MaxResponseObjectSize {
+		return fmt.Errorf("content is %d bytes, more than the %d allowed", len(content), MaxResponseObjectSize)
+	}
+	if contentType == "" {
+		return nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("invalid content type %q: %s", contentType, err)
+	}
+	isJSON := mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+	isText := isJSON || strings.HasPrefix(mediaType, "text/") || mediaType == "application/javascript" ||
+		mediaType == "application/xml" || strings.HasSuffix(mediaType, "+xml")
+
+	if isText && !utf8.ValidString(content) {
+		return fmt.Errorf("content of type %s is not valid UTF-8", mediaType)
+	}
+	if isJSON && !json.Valid([]byte(content)) {
+		return fmt.Errorf("content of type %s is not valid JSON", mediaType)
+	}
+	if mediaType == "text/html" && content != "" {
+		if detected := http.DetectContentType([]byte(content)); !strings.HasPrefix(detected, "text/") {
+			return fmt.Errorf("content of type %s looks like %s", mediaType, detected)
+		}
+	}
+	return nil
+}
+
+// ReadResponseObjectTemplates reads the templates of a directory, one per file.
+// A template is named after its file without the extension, and its content
+// type is given by the extension. A name starting with a status code and a
+// dash, such as "503-maintenance.html", sets the status and response of the
+// response object, which are 200 OK otherwise. Subdirectories and files whose
+// name starts with a dot, such as ".gitkeep", are ignored. A file whose
+// extension has no known content type is an error.
+func ReadResponseObjectTemplates(dir string) ([]*ResponseObjectTemplate, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var templates []*ResponseObjectTemplate
+	for _, fi := range infos {
+		if !fi.Mode().IsRegular() || strings.HasPrefix(fi.Name(), ".") {
+			continue
+		}
+
+		ext := filepath.Ext(fi.Name())
+		contentType, ok := templateContentTypes[strings.ToLower(ext)]
+		if !ok {
+			contentType = mime.TypeByExtension(ext)
+		}
+		if contentType == "" {
+			return nil, fmt.Errorf("template %s: unknown content type for extension %q", fi.Name(), ext)
+		}
+
+		b, err := ioutil.ReadFile(filepath.Join(dir, fi.Name()))
+		if err != nil {
+			return nil, err
+		}
+		t := &ResponseObjectTemplate{
+			Name:        strings.TrimSuffix(fi.Name(), ext),
+			Status:      http.StatusOK,
+			ContentType: contentType,
+			Template:    string(b),
+		}
+		if n := strings.IndexByte(t.Name, '-'); n == 3 {
+			if status, err := strconv.Atoi(t.Name[:n]); err == nil && http.StatusText(status) != "" {
+				t.Status = uint(status)
+			}
+		}
+		t.Response = http.StatusText(int(t.Status))
+		templates = append(templates, t)
+	}
+	return templates, nil
+}
+
+// ResponseObjectTarget is a service version whose response objects are synced
+// by SyncResponseObjects.
+type ResponseObjectTarget struct {
+	// ServiceID is the ID of the service (required).
+	ServiceID string
+
+	// ServiceVersion is the editable configuration version (required).
+	ServiceVersion int
+
+	// Vars are the template variables of the service. They override the
+	// common variables.
+	Vars map[string]interface{}
+}
+
+// SyncResponseObjectsInput is used as input to the SyncResponseObjects
+// function.
+type SyncResponseObjectsInput struct {
+	// Services are the service versions to update (required).
+	Services []*ResponseObjectTarget
+
+	// Dir is a directory of templates, see ReadResponseObjectTemplates.
+	Dir string
+
+	// Templates are templates to sync in addition to those in Dir. Template
+	// names must be unique across both.
+	Templates []*ResponseObjectTemplate
+
+	// Vars are the template variables common to every service.
+	Vars map[string]interface{}
+
+	// DryRun computes the changes without applying them.
+	DryRun bool
+}
+
+// ResponseObjectSyncResult reports the changes made by SyncResponseObjects to
+// a service version, or those it would make when DryRun is set. When a change
+// fails, only the changes applied before it are listed. Names are sorted.
+type ResponseObjectSyncResult struct {
+	ServiceID      string
+	ServiceVersion int
+
+	Created   []string
+	Updated   []string
+	Unchanged []string
+}
+
+// SyncResponseObjects renders a set of templates for each of several service
+// versions and makes their response objects match. Response objects missing
+// from a version are created and those whose status, response, content type,
+// conditions or content differ are updated. Other response objects are left
+// alone.
+//
+// The variables of a service are the common variables, its own variables and
+// ServiceID and ServiceVersion. Every template is rendered for every service
+// before anything is changed, so that a template error or a failed content
+// check changes nothing.
+func (c *Client) SyncResponseObjects(i *SyncResponseObjectsInput) ([]*ResponseObjectSyncResult, error) {
+	if len(i.Services) == 0 {
+		return nil, ErrMissingServices
+	}
+
+	if i.Dir == "" && len(i.Templates) == 0 {
+		return nil, ErrMissingTemplates
+	}
+
+	for _, s := range i.Services {
+		if s.ServiceID == "" {
+			return nil, ErrMissingServiceID
+		}
+		if s.ServiceVersion == 0 {
+			return nil, ErrMissingServiceVersion
+		}
+	}
+
+	templates := append([]*ResponseObjectTemplate(nil), i.Templates...)
+	if i.Dir != "" {
+		fromDir, err := ReadResponseObjectTemplates(i.Dir)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(fromDir, templates...)
+	}
+	sort.SliceStable(templates, func(a, b int) bool {
+		return templates[a].Name < templates[b].Name
+	})
+	for n := 1; n < len(templates); n++ {
+		if templates[n].Name == templates[n-1].Name {
+			return nil, NewFieldError("Templates").Message(fmt.Sprintf("more than one template named %q", templates[n].Name))
+		}
+	}
+
+	rendered := make([]map[string]string, len(i.Services))
+	for n, s := range i.Services {
+		vars := map[string]interface{}{
+			"ServiceID":      s.ServiceID,
+			"ServiceVersion": s.ServiceVersion,
+		}
+		for k, v := range i.Vars {
+			vars[k] = v
+		}
+		for k, v := range s.Vars {
+			vars[k] = v
+		}
+		rendered[n] = make(map[string]string, len(templates))
+		for _, t := range templates {
+			content, err := t.Render(vars)
+			if err != nil {
+				return nil, fmt.Errorf("service %s: %s", s.ServiceID, err)
+			}
+			rendered[n][t.Name] = content
+		}
+	}
+
+	var results []*ResponseObjectSyncResult
+	for n, s := range i.Services {
+		result, err := c.syncResponseObjects(s, templates, rendered[n], i.DryRun)
+		if result != nil {
+			results = append(results, result)
+		}
+		if err != nil {
+			return results, fmt.Errorf("service %s: %s", s.ServiceID, err)
+		}
+	}
+	return results, nil
+}
+
+// syncResponseObjects makes the response objects of a service version match
+// the rendered templates.
+func (c *Client) syncResponseObjects(s *ResponseObjectTarget, templates []*ResponseObjectTemplate, content map[string]string, dryRun bool) (*ResponseObjectSyncResult, error) {
+	current, err := c.ListResponseObjects(&ListResponseObjectsInput{ServiceID: s.ServiceID, ServiceVersion: s.ServiceVersion})
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]*ResponseObject, len(current))
+	for _, ro := range current {
+		existing[ro.Name] = ro
+	}
+
+	plan := &ResponseObjectSyncResult{ServiceID: s.ServiceID, ServiceVersion: s.ServiceVersion}
+	for _, t := range templates {
+		ro, ok := existing[t.Name]
+		switch {
+		case !ok:
+			plan.Created = append(plan.Created, t.Name)
+		case ro.Status != t.Status || ro.Response != t.Response || ro.ContentType != t.ContentType ||
+			ro.RequestCondition != t.RequestCondition || ro.CacheCondition != t.CacheCondition || ro.Content != content[t.Name]:
+			plan.Updated = append(plan.Updated, t.Name)
+		default:
+			plan.Unchanged = append(plan.Unchanged, t.Name)
+		}
+	}
+	if dryRun {
+		return plan, nil
+	}
+
+	// The result only lists the changes which were applied.
+	result := &ResponseObjectSyncResult{ServiceID: s.ServiceID, ServiceVersion: s.ServiceVersion, Unchanged: plan.Unchanged}
+
+	byName := make(map[string]*ResponseObjectTemplate, len(templates))
+	for _, t := range templates {
+		byName[t.Name] = t
+	}
+	for _, name := range plan.Created {
+		t := byName[name]
+		_, err := c.CreateResponseObject(&CreateResponseObjectInput{
+			ServiceID:        s.ServiceID,
+			ServiceVersion:   s.ServiceVersion,
+			Name:             t.Name,
+			Status:           t.Status,
+			Response:         t.Response,
+			Content:          content[name],
+			ContentType:      t.ContentType,
+			RequestCondition: t.RequestCondition,
+			CacheCondition:   t.CacheCondition,
+		})
+		if err != nil {
+			return result, fmt.Errorf("creating response object %q: %s", name, err)
+		}
+		result.Created = append(result.Created, name)
+	}
+	for _, name := range plan.Updated {
+		t := byName[name]
+		_, err := c.UpdateResponseObject(&UpdateResponseObjectInput{
+			ServiceID:        s.ServiceID,
+			ServiceVersion:   s.ServiceVersion,
+			Name:             t.Name,
+			Status:           Uint(t.Status),
+			Response:         String(t.Response),
+			Content:          String(content[name]),
+			ContentType:      String(t.ContentType),
+			RequestCondition: String(t.RequestCondition),
+			CacheCondition:   String(t.CacheCondition),
+		})
+		if err != nil {
+			return result, fmt.Errorf("updating response object %q: %s", name, err)
+		}
+		result.Updated = append(result.Updated, name)
+	}
+	return result, nil
+}
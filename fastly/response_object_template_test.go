@@ -0,0 +1,225 @@
+package fastly
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var testResponseObjectTemplates = map[string]string{
+	"503-maintenance.html": "<!DOCTYPE html>\n<html><body><h1>{{.Brand}} is down for maintenance</h1><p>{{.Support}}</p></body></html>\n",
+	"status.json":          "{\"service\":\"{{.ServiceID}}\",\"brand\":\"{{.Brand}}\"}\n",
+}
+
+func TestClient_SyncResponseObjects(t *testing.T) {
+	t.Parallel()
+
+	dir := writeTestFiles(t, testResponseObjectTemplates)
+
+	var results []*ResponseObjectSyncResult
+	var err error
+	record(t, "response_objects/sync", func(c *Client) {
+		results, err = c.SyncResponseObjects(&SyncResponseObjectsInput{
+			Services: []*ResponseObjectTarget{
+				{ServiceID: testServiceID, ServiceVersion: 4, Vars: map[string]interface{}{"Brand": "Acme"}},
+				{ServiceID: "kKJb5bOFI47uHeBVluGfX1", ServiceVersion: 2, Vars: map[string]interface{}{"Brand": "Globex"}},
+			},
+			Dir:  dir,
+			Vars: map[string]interface{}{"Support": "support@example.com"},
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []*ResponseObjectSyncResult{
+		{ServiceID: testServiceID, ServiceVersion: 4, Created: []string{"status"}, Updated: []string{"503-maintenance"}},
+		{ServiceID: "kKJb5bOFI47uHeBVluGfX1", ServiceVersion: 2, Updated: []string{"status"}, Unchanged: []string{"503-maintenance"}},
+	}
+	if !reflect.DeepEqual(results, expected) {
+		for n, r := range results {
+			t.Errorf("result %d: %+v", n, r)
+		}
+	}
+}
+
+func TestClient_SyncResponseObjects_partial(t *testing.T) {
+	t.Parallel()
+
+	text := func(name, content string) *ResponseObjectTemplate {
+		return &ResponseObjectTemplate{Name: name, Status: 200, Response: "OK", ContentType: "text/plain; charset=utf-8", Template: content}
+	}
+
+	var results []*ResponseObjectSyncResult
+	var err error
+	record(t, "response_objects/sync_partial", func(c *Client) {
+		results, err = c.SyncResponseObjects(&SyncResponseObjectsInput{
+			Services:  []*ResponseObjectTarget{{ServiceID: testServiceID, ServiceVersion: 6}},
+			Templates: []*ResponseObjectTemplate{text("a", "new"), text("b", "b"), text("c", "c")},
+		})
+	})
+	if err == nil || !strings.Contains(err.Error(), `creating response object "c"`) {
+		t.Fatalf("bad error: %v", err)
+	}
+
+	// Creating c failed, so neither c nor the update of a, which would have
+	// followed, were applied.
+	expected := []*ResponseObjectSyncResult{{ServiceID: testServiceID, ServiceVersion: 6, Created: []string{"b"}}}
+	if !reflect.DeepEqual(results, expected) {
+		for n, r := range results {
+			t.Errorf("result %d: %+v", n, r)
+		}
+	}
+}
+
+func TestReadResponseObjectTemplates(t *testing.T) {
+	t.Parallel()
+
+	dir := writeTestFiles(t, testResponseObjectTemplates)
+	templates, err := ReadResponseObjectTemplates(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(templates) != 2 {
+		t.Fatalf("expected 2 templates, got %d", len(templates))
+	}
+
+	maintenance, status := templates[0], templates[1]
+	if maintenance.Name != "503-maintenance" || maintenance.Status != 503 || maintenance.Response != "Service Unavailable" || maintenance.ContentType != "text/html; charset=utf-8" {
+		t.Errorf("bad template: %+v", maintenance)
+	}
+	if status.Name != "status" || status.Status != 200 || status.Response != "OK" || status.ContentType != "application/json" {
+		t.Errorf("bad template: %+v", status)
+	}
+
+	content, err := maintenance.Render(map[string]interface{}{"Brand": "<Acme>", "Support": "help"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(content, "<h1>&lt;Acme&gt; is down") {
+		t.Errorf("HTML template not escaped: %s", content)
+	}
+
+	_, err = maintenance.Render(map[string]interface{}{"Brand": "Acme"})
+	if err == nil || !strings.Contains(err.Error(), `map has no entry for key "Support"`) {
+		t.Errorf("bad error: %v", err)
+	}
+
+	_, err = status.Render(map[string]interface{}{"ServiceID": "x", "Brand": `"`})
+	if err == nil || err.Error() != `response object "status": content of type application/json is not valid JSON` {
+		t.Errorf("bad error: %v", err)
+	}
+
+	// Dotfiles are not templates.
+	dir = writeTestFiles(t, map[string]string{".gitkeep": "", "page.txt": "a"})
+	templates, err = ReadResponseObjectTemplates(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(templates) != 1 || templates[0].Name != "page" {
+		t.Errorf("bad templates: %+v", templates)
+	}
+
+	dir = writeTestFiles(t, map[string]string{"page.nope": "a"})
+	_, err = ReadResponseObjectTemplates(dir)
+	if err == nil || err.Error() != `template page.nope: unknown content type for extension ".nope"` {
+		t.Errorf("bad error: %v", err)
+	}
+}
+
+func TestCheckResponseObjectContent(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		content     string
+		contentType string
+		want        string
+	}{
+		{"<html></html>", "text/html", ""},
+		{"anything", "", ""},
+		{`{"a":1}`, "application/problem+json", ""},
+		{strings.Repeat("a", MaxResponseObjectSize+1), "text/plain", "content is 65537 bytes, more than the 65536 allowed"},
+		{"a", "text/", `invalid content type "text/": mime: expected token after slash`},
+		{"\xff", "text/plain", "content of type text/plain is not valid UTF-8"},
+		{"\x89PNG\r\n\x1a\n", "text/html", "content of type text/html is not valid UTF-8"},
+		{"%PDF-1.4", "text/html", "content of type text/html looks like application/pdf"},
+	}
+	for _, c := range cases {
+		err := CheckResponseObjectContent(c.content, c.contentType)
+		got := ""
+		if err != nil {
+			got = err.Error()
+		}
+		if got != c.want {
+			t.Errorf("%.20q %s: got error %q, want %q", c.content, c.contentType, got, c.want)
+		}
+	}
+}
+
+func TestClient_SyncResponseObjects_validation(t *testing.T) {
+	t.Parallel()
+
+	var err error
+	_, err = testClient.SyncResponseObjects(&SyncResponseObjectsInput{})
+	if err != ErrMissingServices {
+		t.Errorf("bad error: %s", err)
+	}
+
+	_, err = testClient.SyncResponseObjects(&SyncResponseObjectsInput{
+		Services: []*ResponseObjectTarget{{ServiceID: "foo", ServiceVersion: 1}},
+	})
+	if err != ErrMissingTemplates {
+		t.Errorf("bad error: %s", err)
+	}
+
+	_, err = testClient.SyncResponseObjects(&SyncResponseObjectsInput{
+		Services:  []*ResponseObjectTarget{{ServiceVersion: 1}},
+		Templates: []*ResponseObjectTemplate{{Name: "a"}},
+	})
+	if err != ErrMissingServiceID {
+		t.Errorf("bad error: %s", err)
+	}
+
+	_, err = testClient.SyncResponseObjects(&SyncResponseObjectsInput{
+		Services:  []*ResponseObjectTarget{{ServiceID: "foo"}},
+		Templates: []*ResponseObjectTemplate{{Name: "a"}},
+	})
+	if err != ErrMissingServiceVersion {
+		t.Errorf("bad error: %s", err)
+	}
+
+	// Templates sharing a name would map to the same response object.
+	dir := writeTestFiles(t, map[string]string{
+		"503.html": "<p>down</p>",
+		"503.json": `{"error":"down"}`,
+	})
+	_, err = testClient.SyncResponseObjects(&SyncResponseObjectsInput{
+		Services: []*ResponseObjectTarget{{ServiceID: "foo", ServiceVersion: 1}},
+		Dir:      dir,
+	})
+	if err == nil || err.Error() != `problem with field 'Templates': more than one template named "503"` {
+		t.Errorf("bad error: %v", err)
+	}
+
+	dir = writeTestFiles(t, map[string]string{"page.txt": "a"})
+	_, err = testClient.SyncResponseObjects(&SyncResponseObjectsInput{
+		Services:  []*ResponseObjectTarget{{ServiceID: "foo", ServiceVersion: 1}},
+		Dir:       dir,
+		Templates: []*ResponseObjectTemplate{{Name: "page", ContentType: "text/plain", Template: "b"}},
+	})
+	if err == nil || err.Error() != `problem with field 'Templates': more than one template named "page"` {
+		t.Errorf("bad error: %v", err)
+	}
+
+	// Templates are rendered for every service before any request is made.
+	_, err = testClient.SyncResponseObjects(&SyncResponseObjectsInput{
+		Services: []*ResponseObjectTarget{
+			{ServiceID: "foo", ServiceVersion: 1, Vars: map[string]interface{}{"Brand": "Acme"}},
+			{ServiceID: "bar", ServiceVersion: 1},
+		},
+		Templates: []*ResponseObjectTemplate{{Name: "page", ContentType: "text/plain", Template: "{{.Brand}}"}},
+	})
+	if err == nil || !strings.HasPrefix(err.Error(), "service bar: ") {
+		t.Errorf("bad error: %v", err)
+	}
+}
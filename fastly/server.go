@@ -90,6 +90,12 @@ type CreateServerInput struct {
 // CreateServer creates a single server for a particular service and pool.
 // Servers are versionless resources that are associated with a Pool.
 func (c *Client) CreateServer(i *CreateServerInput) (*Server, error) {
+	return c.createServer(i, nil)
+}
+
+// createServer creates a server with the given request options. Servers are
+// versionless, so SyncPoolServers uses this to modify them in parallel.
+func (c *Client) createServer(i *CreateServerInput, ro *RequestOptions) (*Server, error) {
 	if i.ServiceID == "" {
 		return nil, ErrMissingServiceID
 	}
@@ -103,7 +109,7 @@ func (c *Client) CreateServer(i *CreateServerInput) (*Server, error) {
 	}
 
 	path := fmt.Sprintf("/service/%s/pool/%s/server", i.ServiceID, i.PoolID)
-	resp, err := c.PostForm(path, i, nil)
+	resp, err := c.PostForm(path, i, ro)
 	if err != nil {
 		return nil, err
 	}
@@ -175,6 +181,11 @@ type UpdateServerInput struct {
 
 // UpdateServer updates a single server for a particular service and pool.
 func (c *Client) UpdateServer(i *UpdateServerInput) (*Server, error) {
+	return c.updateServer(i, nil)
+}
+
+// updateServer updates a server with the given request options.
+func (c *Client) updateServer(i *UpdateServerInput, ro *RequestOptions) (*Server, error) {
 	if i.ServiceID == "" {
 		return nil, ErrMissingServiceID
 	}
@@ -188,7 +199,7 @@ func (c *Client) UpdateServer(i *UpdateServerInput) (*Server, error) {
 	}
 
 	path := fmt.Sprintf("/service/%s/pool/%s/server/%s", i.ServiceID, i.PoolID, i.Server)
-	resp, err := c.PutForm(path, i, nil)
+	resp, err := c.PutForm(path, i, ro)
 	if err != nil {
 		return nil, err
 	}
@@ -213,6 +224,11 @@ type DeleteServerInput struct {
 
 // DeleteServer deletes a single server for a particular service and pool.
 func (c *Client) DeleteServer(i *DeleteServerInput) error {
+	return c.deleteServer(i, nil)
+}
+
+// deleteServer deletes a server with the given request options.
+func (c *Client) deleteServer(i *DeleteServerInput, ro *RequestOptions) error {
 	if i.ServiceID == "" {
 		return ErrMissingServiceID
 	}
@@ -226,7 +242,7 @@ func (c *Client) DeleteServer(i *DeleteServerInput) error {
 	}
 
 	path := fmt.Sprintf("/service/%s/pool/%s/server/%s", i.ServiceID, i.PoolID, i.Server)
-	resp, err := c.Delete(path, nil)
+	resp, err := c.Delete(path, ro)
 	if err != nil {
 		return err
 	}
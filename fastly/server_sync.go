@@ -0,0 +1,324 @@
+package fastly
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultServerPort and defaultServerWeight are the values the API uses
+	// for servers created without a port or weight.
+	defaultServerPort   = 80
+	defaultServerWeight = 100
+
+	// defaultSyncConcurrency is the number of changes applied in parallel
+	// when no concurrency is given.
+	defaultSyncConcurrency = 4
+)
+
+// PoolServerSpec describes a server that should be present in a pool.
+type PoolServerSpec struct {
+	// Address is the hostname or IP of the server (required).
+	Address string
+
+	// Port is the port of the server. Zero means the API default of 80.
+	Port uint
+
+	// Weight is the weight of the server. Zero means the API default of 100.
+	Weight uint
+
+	// Disabled marks the server as not receiving traffic.
+	Disabled bool
+}
+
+// key identifies a server within a pool by its address and port.
+func (s *PoolServerSpec) key() string {
+	return fmt.Sprintf("%s:%d", s.Address, s.port())
+}
+
+func (s *PoolServerSpec) port() uint {
+	if s.Port == 0 {
+		return defaultServerPort
+	}
+	return s.Port
+}
+
+func (s *PoolServerSpec) weight() uint {
+	if s.Weight == 0 {
+		return defaultServerWeight
+	}
+	return s.Weight
+}
+
+// PoolServerAction is the kind of change made to a pool server.
+type PoolServerAction string
+
+const (
+	// PoolServerCreate is a server that was created.
+	PoolServerCreate PoolServerAction = "create"
+
+	// PoolServerUpdate is a server whose weight or disabled state changed.
+	PoolServerUpdate PoolServerAction = "update"
+
+	// PoolServerDrain is a server that was disabled before being deleted.
+	PoolServerDrain PoolServerAction = "drain"
+
+	// PoolServerDelete is a server that was deleted.
+	PoolServerDelete PoolServerAction = "delete"
+)
+
+// PoolServerChange is a single change computed by SyncPoolServers.
+type PoolServerChange struct {
+	Action PoolServerAction
+
+	// Server is the server after the change, or the existing server for
+	// drains and deletes. For creates that were not applied it has no ID.
+	Server *Server
+
+	// Err is the error returned by the API when applying the change.
+	Err error
+}
+
+// SyncPoolServersResult reports the changes made by SyncPoolServers.
+type SyncPoolServersResult struct {
+	Changes []*PoolServerChange
+
+	// Unchanged is the number of servers that already matched.
+	Unchanged int
+}
+
+// Failed returns the changes which could not be applied.
+func (r *SyncPoolServersResult) Failed() []*PoolServerChange {
+	var cs []*PoolServerChange
+	for _, c := range r.Changes {
+		if c.Err != nil {
+			cs = append(cs, c)
+		}
+	}
+	return cs
+}
+
+// SyncPoolServersInput is used as input to the SyncPoolServers function.
+type SyncPoolServersInput struct {
+	// ServiceID is the ID of the service (required).
+	ServiceID string
+
+	// PoolID is the ID of the pool (required).
+	PoolID string
+
+	// Servers is the desired set of servers. Servers are matched to existing
+	// ones by address and port.
+	Servers []*PoolServerSpec
+
+	// Concurrency is the maximum number of changes applied at once. Defaults
+	// to 4.
+	Concurrency int
+
+	// DrainDelay is how long to wait between disabling servers and deleting
+	// them, giving in-flight requests time to finish.
+	DrainDelay time.Duration
+
+	// DryRun computes the changes without applying them.
+	DryRun bool
+}
+
+// SyncPoolServers makes the servers of a pool match the desired set with the
+// minimum number of changes. Servers to be removed are disabled first and
+// deleted afterwards. Because servers are versionless, the changes take effect
+// immediately.
+//
+// The returned result lists every change. If any change failed, an error is
+// returned along with the result, and failed changes carry their error.
+func (c *Client) SyncPoolServers(i *SyncPoolServersInput) (*SyncPoolServersResult, error) {
+	if i.ServiceID == "" {
+		return nil, ErrMissingServiceID
+	}
+
+	if i.PoolID == "" {
+		return nil, ErrMissingPoolID
+	}
+
+	desired := make(map[string]*PoolServerSpec, len(i.Servers))
+	for _, s := range i.Servers {
+		if s.Address == "" {
+			return nil, ErrMissingAddress
+		}
+		desired[s.key()] = s
+	}
+
+	existing, err := c.ListServers(&ListServersInput{ServiceID: i.ServiceID, PoolID: i.PoolID})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SyncPoolServersResult{}
+	var creates, updates, deletes []*PoolServerChange
+
+	seen := make(map[string]bool, len(existing))
+	for _, s := range existing {
+		key := fmt.Sprintf("%s:%d", s.Address, s.Port)
+		spec, ok := desired[key]
+		if !ok || seen[key] {
+			deletes = append(deletes, &PoolServerChange{Action: PoolServerDelete, Server: s})
+			continue
+		}
+		seen[key] = true
+
+		if s.Weight == spec.weight() && s.Disabled == spec.Disabled {
+			result.Unchanged++
+			continue
+		}
+		updates = append(updates, &PoolServerChange{Action: PoolServerUpdate, Server: s})
+	}
+
+	keys := make([]string, 0, len(desired))
+	for k := range desired {
+		if !seen[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		spec := desired[k]
+		creates = append(creates, &PoolServerChange{
+			Action: PoolServerCreate,
+			Server: &Server{
+				ServiceID: i.ServiceID,
+				PoolID:    i.PoolID,
+				Address:   spec.Address,
+				Port:      spec.port(),
+				Weight:    spec.weight(),
+				Disabled:  spec.Disabled,
+			},
+		})
+	}
+
+	if i.DryRun {
+		result.Changes = append(append(append(result.Changes, creates...), updates...), deletes...)
+		return result, nil
+	}
+
+	// Modifications of versionless servers are safe to run in parallel, so
+	// they bypass the client's update lock.
+	ro := func() *RequestOptions { return &RequestOptions{Parallel: true} }
+
+	// Add and update servers before removing any, so the pool never loses
+	// capacity it is meant to keep.
+	var fns []func()
+	for _, ch := range creates {
+		ch := ch
+		fns = append(fns, func() {
+			s, err := c.createServer(&CreateServerInput{
+				ServiceID: i.ServiceID,
+				PoolID:    i.PoolID,
+				Address:   ch.Server.Address,
+				Port:      ch.Server.Port,
+				Weight:    ch.Server.Weight,
+				Disabled:  ch.Server.Disabled,
+			}, ro())
+			if err != nil {
+				ch.Err = err
+				return
+			}
+			ch.Server = s
+		})
+	}
+	for _, ch := range updates {
+		ch := ch
+		spec := desired[fmt.Sprintf("%s:%d", ch.Server.Address, ch.Server.Port)]
+		fns = append(fns, func() {
+			s, err := c.updateServer(&UpdateServerInput{
+				ServiceID: i.ServiceID,
+				PoolID:    i.PoolID,
+				Server:    ch.Server.ID,
+				Weight:    Uint(spec.weight()),
+				Disabled:  Bool(spec.Disabled),
+			}, ro())
+			if err != nil {
+				ch.Err = err
+				return
+			}
+			ch.Server = s
+		})
+	}
+	concurrency := i.Concurrency
+	if concurrency == 0 {
+		concurrency = defaultSyncConcurrency
+	}
+	runConcurrently(concurrency, fns)
+	result.Changes = append(append(result.Changes, creates...), updates...)
+
+	// Drain servers that are still enabled, then delete them.
+	fns = nil
+	drained := make([]*PoolServerChange, len(deletes))
+	for n, ch := range deletes {
+		if ch.Server.Disabled {
+			continue
+		}
+		drain := &PoolServerChange{Action: PoolServerDrain, Server: ch.Server}
+		drained[n] = drain
+		fns = append(fns, func() {
+			_, drain.Err = c.updateServer(&UpdateServerInput{
+				ServiceID: i.ServiceID,
+				PoolID:    i.PoolID,
+				Server:    drain.Server.ID,
+				Disabled:  Bool(true),
+			}, ro())
+		})
+	}
+	runConcurrently(concurrency, fns)
+	for _, d := range drained {
+		if d != nil {
+			result.Changes = append(result.Changes, d)
+		}
+	}
+	if len(fns) > 0 && i.DrainDelay > 0 {
+		time.Sleep(i.DrainDelay)
+	}
+
+	fns = nil
+	for n, ch := range deletes {
+		if d := drained[n]; d != nil && d.Err != nil {
+			// Never delete a server that is still receiving traffic.
+			continue
+		}
+		ch := ch
+		fns = append(fns, func() {
+			ch.Err = c.deleteServer(&DeleteServerInput{
+				ServiceID: i.ServiceID,
+				PoolID:    i.PoolID,
+				Server:    ch.Server.ID,
+			}, ro())
+		})
+		result.Changes = append(result.Changes, ch)
+	}
+	runConcurrently(concurrency, fns)
+
+	if failed := result.Failed(); len(failed) > 0 {
+		return result, fmt.Errorf("%d of %d pool server changes failed, first error: %v", len(failed), len(result.Changes), failed[0].Err)
+	}
+	return result, nil
+}
+
+// runConcurrently calls every function in fns using at most n goroutines at a
+// time and waits for all of them to return.
+func runConcurrently(n int, fns []func()) {
+	if n < 1 {
+		n = 1
+	}
+
+	sem := make(chan struct{}, n)
+	var wg sync.WaitGroup
+	for _, fn := range fns {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(fn func()) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn()
+		}(fn)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,78 @@
+package fastly
+
+import (
+	"testing"
+)
+
+func TestClient_SyncPoolServers(t *testing.T) {
+	t.Parallel()
+
+	var err error
+	var r *SyncPoolServersResult
+	record(t, "servers/sync", func(c *Client) {
+		r, err = c.SyncPoolServers(&SyncPoolServersInput{
+			ServiceID: testServiceID,
+			PoolID:    "0dINauGIu5SXLm56N5HIAN",
+			Servers: []*PoolServerSpec{
+				{Address: "10.0.0.1"},
+				{Address: "10.0.0.2", Weight: 20},
+				{Address: "10.0.0.4", Port: 443, Weight: 50},
+			},
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Unchanged != 1 {
+		t.Errorf("bad unchanged: %d", r.Unchanged)
+	}
+
+	want := []struct {
+		action PoolServerAction
+		id     string
+	}{
+		{PoolServerCreate, "srvD"},
+		{PoolServerUpdate, "srvB"},
+		{PoolServerDrain, "srvC"},
+		{PoolServerDelete, "srvC"},
+	}
+	if len(r.Changes) != len(want) {
+		t.Fatalf("bad changes: %d", len(r.Changes))
+	}
+	for n, w := range want {
+		ch := r.Changes[n]
+		if ch.Action != w.action || ch.Server.ID != w.id || ch.Err != nil {
+			t.Errorf("bad change %d: %s %s %v", n, ch.Action, ch.Server.ID, ch.Err)
+		}
+	}
+	if r.Changes[1].Server.Weight != 20 {
+		t.Errorf("bad weight: %d", r.Changes[1].Server.Weight)
+	}
+}
+
+func TestClient_SyncPoolServers_validation(t *testing.T) {
+	var err error
+	_, err = testClient.SyncPoolServers(&SyncPoolServersInput{
+		ServiceID: "",
+	})
+	if err != ErrMissingServiceID {
+		t.Errorf("bad error: %s", err)
+	}
+
+	_, err = testClient.SyncPoolServers(&SyncPoolServersInput{
+		ServiceID: "foo",
+		PoolID:    "",
+	})
+	if err != ErrMissingPoolID {
+		t.Errorf("bad error: %q", err)
+	}
+
+	_, err = testClient.SyncPoolServers(&SyncPoolServersInput{
+		ServiceID: "foo",
+		PoolID:    "bar",
+		Servers:   []*PoolServerSpec{{Port: 80}},
+	})
+	if err != ErrMissingAddress {
+		t.Errorf("bad error: %q", err)
+	}
+}
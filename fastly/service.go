@@ -3,9 +3,18 @@ package fastly
 import (
 	"fmt"
 	"sort"
+	"strconv"
 	"time"
 )
 
+const (
+	// ServiceTypeVCL is the type for VCL services.
+	ServiceTypeVCL = "vcl"
+
+	// ServiceTypeWasm is the type for Wasm services.
+	ServiceTypeWasm = "wasm"
+)
+
 // Service represents a single service for the Fastly account.
 type Service struct {
 	ID            string     `mapstructure:"id"`
@@ -57,11 +66,65 @@ func (s servicesByName) Less(i, j int) bool {
 }
 
 // ListServicesInput is used as input to the ListServices function.
-type ListServicesInput struct{}
+type ListServicesInput struct {
+	// Page is the page index for pagination. When zero, all services are
+	// returned in a single response.
+	Page int
+
+	// PerPage is the number of services per page.
+	PerPage int
+
+	// Sort is the field to sort by, e.g. "created" or "name". When empty, the
+	// results are sorted by name.
+	Sort string
+
+	// Direction is the sort direction, either "ascend" or "descend".
+	Direction string
+
+	// Type limits the results to services of the given type, either
+	// ServiceTypeVCL or ServiceTypeWasm. The API cannot filter by type, so
+	// the filter is applied to the services returned and cannot be combined
+	// with Page or PerPage, which would then return short pages.
+	Type string
+}
 
-// ListServices returns the full list of services for the current account.
+// formatFilters converts user input into query parameters for filtering.
+func (i *ListServicesInput) formatFilters() map[string]string {
+	result := map[string]string{}
+	pairings := map[string]interface{}{
+		"page":      i.Page,
+		"per_page":  i.PerPage,
+		"sort":      i.Sort,
+		"direction": i.Direction,
+	}
+	for key, value := range pairings {
+		switch v := value.(type) {
+		case int:
+			if v != 0 {
+				result[key] = strconv.Itoa(v)
+			}
+		case string:
+			if v != "" {
+				result[key] = v
+			}
+		}
+	}
+	return result
+}
+
+// ListServices returns the list of services for the current account.
 func (c *Client) ListServices(i *ListServicesInput) ([]*Service, error) {
-	resp, err := c.Get("/service", nil)
+	if i == nil {
+		i = &ListServicesInput{}
+	}
+
+	if i.Type != "" && (i.Page != 0 || i.PerPage != 0) {
+		return nil, NewFieldError("Type").Message("cannot be combined with Page or PerPage")
+	}
+
+	resp, err := c.Get("/service", &RequestOptions{
+		Params: i.formatFilters(),
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -70,7 +133,22 @@ func (c *Client) ListServices(i *ListServicesInput) ([]*Service, error) {
 	if err := decodeBodyMap(resp.Body, &s); err != nil {
 		return nil, err
 	}
-	sort.Stable(servicesByName(s))
+
+	// The API has no filter for the service type, so it is applied here, on
+	// the complete list.
+	if i.Type != "" {
+		filtered := s[:0]
+		for _, svc := range s {
+			if svc.Type == i.Type {
+				filtered = append(filtered, svc)
+			}
+		}
+		s = filtered
+	}
+
+	if i.Sort == "" {
+		sort.Stable(servicesByName(s))
+	}
 	return s, nil
 }
 
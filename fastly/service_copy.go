@@ -0,0 +1,752 @@
+package fastly
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// CopySecretFunc returns the value to use for a secret field of a copied
+// resource. Kind is the resource type (e.g. "backend" or "logging/s3"), name
+// is the resource name and field is the API field name (e.g.
+// "ssl_client_key"). Returning false keeps the value read from the source.
+type CopySecretFunc func(kind, name, field string) (string, bool)
+
+// CopyServiceConfigInput is used as input to the CopyServiceConfig function.
+type CopyServiceConfigInput struct {
+	// SourceServiceID is the ID of the service to copy from (required).
+	SourceServiceID string
+
+	// SourceServiceVersion is the version to copy from (required).
+	SourceServiceVersion int
+
+	// TargetServiceID is the ID of the service to copy to (required).
+	TargetServiceID string
+
+	// TargetServiceVersion is an unlocked version of the target service to copy
+	// into. When zero, a new blank version is created.
+	TargetServiceVersion int
+
+	// Comment is used for the version created when TargetServiceVersion is
+	// zero.
+	Comment string
+
+	// RewriteDomain, if set, returns the domain name to create in the target
+	// for each domain of the source. Returning an empty string skips the
+	// domain.
+	RewriteDomain func(name string) string
+
+	// RewriteBackend, if set, is called with each backend before it is
+	// created, so addresses and TLS hostnames can be changed.
+	RewriteBackend func(b *CreateBackendInput)
+
+	// RewriteServer, if set, is called with each pool server before it is
+	// created.
+	RewriteServer func(pool string, s *CreateServerInput)
+
+	// RewriteDictionary, if set, returns the items to create in the target for
+	// each dictionary. Write-only dictionaries cannot be read back, so this is
+	// the only way to populate them.
+	RewriteDictionary func(name string, items map[string]string) map[string]string
+
+	// Secret, if set, supplies the values of secret fields that the API does
+	// not return.
+	Secret CopySecretFunc
+}
+
+// copyLoggingKinds is the list of logging endpoint types copied by
+// CopyServiceConfig, together with the fields holding credentials.
+var copyLoggingKinds = map[string][]string{
+	"azureblob":     {"sas_token"},
+	"bigquery":      {"secret_key"},
+	"cloudfiles":    {"access_key"},
+	"datadog":       {"token"},
+	"digitalocean":  {"access_key", "secret_key"},
+	"elasticsearch": {"password", "tls_client_key"},
+	"ftp":           {"password"},
+	"gcs":           {"secret_key"},
+	"heroku":        {"token"},
+	"honeycomb":     {"token"},
+	"https":         {"tls_client_key"},
+	"kafka":         {"password", "tls_client_key"},
+	"kinesis":       {"access_key", "secret_key"},
+	"logentries":    {"token"},
+	"loggly":        {"token"},
+	"logshuttle":    {"token"},
+	"newrelic":      {"token"},
+	"openstack":     {"access_key"},
+	"papertrail":    nil,
+	"pubsub":        {"secret_key"},
+	"s3":            {"access_key", "secret_key"},
+	"scalyr":        {"token"},
+	"sftp":          {"password", "secret_key"},
+	"splunk":        {"token", "tls_client_key"},
+	"sumologic":     nil,
+	"syslog":        {"token", "tls_client_key"},
+}
+
+// copyReadOnlyFields are fields returned for logging endpoints which must not
+// be sent back when creating them.
+var copyReadOnlyFields = map[string]bool{
+	"service_id": true,
+	"version":    true,
+	"created_at": true,
+	"updated_at": true,
+	"deleted_at": true,
+}
+
+// CopyServiceConfig reads every resource of a source service version and
+// recreates it on an unlocked version of a target service. The target version
+// is returned; it is neither validated nor activated.
+func (c *Client) CopyServiceConfig(i *CopyServiceConfigInput) (*Version, error) {
+	if i.SourceServiceID == "" {
+		return nil, ErrMissingSourceServiceID
+	}
+
+	if i.SourceServiceVersion == 0 {
+		return nil, ErrMissingSourceServiceVersion
+	}
+
+	if i.TargetServiceID == "" {
+		return nil, ErrMissingTargetServiceID
+	}
+
+	var v *Version
+	var err error
+	if i.TargetServiceVersion == 0 {
+		v, err = c.CreateVersion(&CreateVersionInput{
+			ServiceID: i.TargetServiceID,
+			Comment:   i.Comment,
+		})
+	} else {
+		v, err = c.GetVersion(&GetVersionInput{
+			ServiceID:      i.TargetServiceID,
+			ServiceVersion: i.TargetServiceVersion,
+		})
+		if err == nil && (v.Locked || v.Active) {
+			err = ErrLockedTargetServiceVersion
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	cp := &serviceCopier{
+		c:     c,
+		in:    i,
+		srcID: i.SourceServiceID,
+		srcV:  i.SourceServiceVersion,
+		dstID: i.TargetServiceID,
+		dstV:  v.Number,
+	}
+	steps := []func() error{
+		cp.settings,
+		cp.domains,
+		cp.healthChecks,
+		cp.conditions,
+		cp.backends,
+		cp.directors,
+		cp.pools,
+		cp.cacheSettings,
+		cp.requestSettings,
+		cp.responseObjects,
+		cp.headers,
+		cp.gzips,
+		cp.dictionaries,
+		cp.acls,
+		cp.vcls,
+		cp.snippets,
+		cp.logging,
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return v, err
+		}
+	}
+	return v, nil
+}
+
+// serviceCopier holds the state of a single CopyServiceConfig call.
+type serviceCopier struct {
+	c     *Client
+	in    *CopyServiceConfigInput
+	srcID string
+	srcV  int
+	dstID string
+	dstV  int
+}
+
+// secret returns the caller supplied value for a secret field, or def.
+func (cp *serviceCopier) secret(kind, name, field, def string) string {
+	if cp.in.Secret == nil {
+		return def
+	}
+	if s, ok := cp.in.Secret(kind, name, field); ok {
+		return s
+	}
+	return def
+}
+
+func (cp *serviceCopier) settings() error {
+	s, err := cp.c.GetSettings(&GetSettingsInput{ServiceID: cp.srcID, ServiceVersion: cp.srcV})
+	if err != nil {
+		return err
+	}
+	_, err = cp.c.UpdateSettings(&UpdateSettingsInput{
+		ServiceID:       cp.dstID,
+		ServiceVersion:  cp.dstV,
+		DefaultTTL:      s.DefaultTTL,
+		DefaultHost:     String(s.DefaultHost),
+		StaleIfError:    Bool(s.StaleIfError),
+		StaleIfErrorTTL: Uint(s.StaleIfErrorTTL),
+	})
+	return err
+}
+
+func (cp *serviceCopier) domains() error {
+	ds, err := cp.c.ListDomains(&ListDomainsInput{ServiceID: cp.srcID, ServiceVersion: cp.srcV})
+	if err != nil {
+		return err
+	}
+	for _, d := range ds {
+		name := d.Name
+		if cp.in.RewriteDomain != nil {
+			if name = cp.in.RewriteDomain(name); name == "" {
+				continue
+			}
+		}
+		if _, err := cp.c.CreateDomain(&CreateDomainInput{
+			ServiceID:      cp.dstID,
+			ServiceVersion: cp.dstV,
+			Name:           name,
+			Comment:        d.Comment,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cp *serviceCopier) healthChecks() error {
+	hs, err := cp.c.ListHealthChecks(&ListHealthChecksInput{ServiceID: cp.srcID, ServiceVersion: cp.srcV})
+	if err != nil {
+		return err
+	}
+	for _, h := range hs {
+		if _, err := cp.c.CreateHealthCheck(&CreateHealthCheckInput{
+			ServiceID:        cp.dstID,
+			ServiceVersion:   cp.dstV,
+			Name:             h.Name,
+			Comment:          h.Comment,
+			Method:           h.Method,
+			Host:             h.Host,
+			Path:             h.Path,
+			HTTPVersion:      h.HTTPVersion,
+			Timeout:          h.Timeout,
+			CheckInterval:    h.CheckInterval,
+			ExpectedResponse: h.ExpectedResponse,
+			Window:           h.Window,
+			Threshold:        h.Threshold,
+			Initial:          h.Initial,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cp *serviceCopier) conditions() error {
+	cs, err := cp.c.ListConditions(&ListConditionsInput{ServiceID: cp.srcID, ServiceVersion: cp.srcV})
+	if err != nil {
+		return err
+	}
+	for _, cond := range cs {
+		if _, err := cp.c.CreateCondition(&CreateConditionInput{
+			ServiceID:      cp.dstID,
+			ServiceVersion: cp.dstV,
+			Name:           cond.Name,
+			Statement:      cond.Statement,
+			Type:           cond.Type,
+			Priority:       cond.Priority,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cp *serviceCopier) backends() error {
+	bs, err := cp.c.ListBackends(&ListBackendsInput{ServiceID: cp.srcID, ServiceVersion: cp.srcV})
+	if err != nil {
+		return err
+	}
+	for _, b := range bs {
+		in := &CreateBackendInput{
+			ServiceID:           cp.dstID,
+			ServiceVersion:      cp.dstV,
+			Name:                b.Name,
+			Comment:             b.Comment,
+			Address:             b.Address,
+			Port:                b.Port,
+			OverrideHost:        b.OverrideHost,
+			ConnectTimeout:      b.ConnectTimeout,
+			MaxConn:             b.MaxConn,
+			ErrorThreshold:      b.ErrorThreshold,
+			FirstByteTimeout:    b.FirstByteTimeout,
+			BetweenBytesTimeout: b.BetweenBytesTimeout,
+			AutoLoadbalance:     Compatibool(b.AutoLoadbalance),
+			Weight:              b.Weight,
+			RequestCondition:    b.RequestCondition,
+			HealthCheck:         b.HealthCheck,
+			Shield:              b.Shield,
+			UseSSL:              Compatibool(b.UseSSL),
+			SSLCheckCert:        Compatibool(b.SSLCheckCert),
+			SSLCACert:           b.SSLCACert,
+			SSLClientCert:       b.SSLClientCert,
+			SSLClientKey:        cp.secret("backend", b.Name, "ssl_client_key", b.SSLClientKey),
+			SSLCertHostname:     b.SSLCertHostname,
+			SSLSNIHostname:      b.SSLSNIHostname,
+			MinTLSVersion:       b.MinTLSVersion,
+			MaxTLSVersion:       b.MaxTLSVersion,
+			SSLCiphers:          b.SSLCiphers,
+		}
+		if cp.in.RewriteBackend != nil {
+			cp.in.RewriteBackend(in)
+		}
+		if _, err := cp.c.CreateBackend(in); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cp *serviceCopier) directors() error {
+	ds, err := cp.c.ListDirectors(&ListDirectorsInput{ServiceID: cp.srcID, ServiceVersion: cp.srcV})
+	if err != nil {
+		return err
+	}
+	for _, d := range ds {
+		if _, err := cp.c.CreateDirector(&CreateDirectorInput{
+			ServiceID:      cp.dstID,
+			ServiceVersion: cp.dstV,
+			Name:           d.Name,
+			Comment:        d.Comment,
+			Shield:         d.Shield,
+			Quorum:         d.Quorum,
+			Type:           d.Type,
+			Retries:        d.Retries,
+			Capacity:       d.Capacity,
+		}); err != nil {
+			return err
+		}
+		for _, b := range d.Backends {
+			if _, err := cp.c.CreateDirectorBackend(&CreateDirectorBackendInput{
+				ServiceID:      cp.dstID,
+				ServiceVersion: cp.dstV,
+				Director:       d.Name,
+				Backend:        b,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (cp *serviceCopier) pools() error {
+	ps, err := cp.c.ListPools(&ListPoolsInput{ServiceID: cp.srcID, ServiceVersion: cp.srcV})
+	if err != nil {
+		return err
+	}
+	for _, p := range ps {
+		np, err := cp.c.CreatePool(&CreatePoolInput{
+			ServiceID:        cp.dstID,
+			ServiceVersion:   cp.dstV,
+			Name:             p.Name,
+			Comment:          p.Comment,
+			Shield:           p.Shield,
+			RequestCondition: p.RequestCondition,
+			MaxConnDefault:   p.MaxConnDefault,
+			ConnectTimeout:   p.ConnectTimeout,
+			FirstByteTimeout: p.FirstByteTimeout,
+			Quorum:           p.Quorum,
+			UseTLS:           Compatibool(p.UseTLS),
+			TLSCACert:        p.TLSCACert,
+			TLSCiphers:       p.TLSCiphers,
+			TLSClientKey:     cp.secret("pool", p.Name, "tls_client_key", p.TLSClientKey),
+			TLSClientCert:    p.TLSClientCert,
+			TLSSNIHostname:   p.TLSSNIHostname,
+			TLSCheckCert:     Compatibool(p.TLSCheckCert),
+			TLSCertHostname:  p.TLSCertHostname,
+			MinTLSVersion:    p.MinTLSVersion,
+			MaxTLSVersion:    p.MaxTLSVersion,
+			Healthcheck:      p.Healthcheck,
+			Type:             p.Type,
+			OverrideHost:     p.OverrideHost,
+		})
+		if err != nil {
+			return err
+		}
+
+		ss, err := cp.c.ListServers(&ListServersInput{ServiceID: cp.srcID, PoolID: p.ID})
+		if err != nil {
+			return err
+		}
+		for _, s := range ss {
+			in := &CreateServerInput{
+				ServiceID:    cp.dstID,
+				PoolID:       np.ID,
+				Address:      s.Address,
+				Comment:      s.Comment,
+				Weight:       s.Weight,
+				MaxConn:      s.MaxConn,
+				Port:         s.Port,
+				Disabled:     s.Disabled,
+				OverrideHost: s.OverrideHost,
+			}
+			if cp.in.RewriteServer != nil {
+				cp.in.RewriteServer(p.Name, in)
+			}
+			if _, err := cp.c.CreateServer(in); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (cp *serviceCopier) cacheSettings() error {
+	cs, err := cp.c.ListCacheSettings(&ListCacheSettingsInput{ServiceID: cp.srcID, ServiceVersion: cp.srcV})
+	if err != nil {
+		return err
+	}
+	for _, s := range cs {
+		if _, err := cp.c.CreateCacheSetting(&CreateCacheSettingInput{
+			ServiceID:      cp.dstID,
+			ServiceVersion: cp.dstV,
+			Name:           s.Name,
+			Action:         s.Action,
+			TTL:            s.TTL,
+			StaleTTL:       s.StaleTTL,
+			CacheCondition: s.CacheCondition,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cp *serviceCopier) requestSettings() error {
+	rs, err := cp.c.ListRequestSettings(&ListRequestSettingsInput{ServiceID: cp.srcID, ServiceVersion: cp.srcV})
+	if err != nil {
+		return err
+	}
+	for _, s := range rs {
+		if _, err := cp.c.CreateRequestSetting(&CreateRequestSettingInput{
+			ServiceID:        cp.dstID,
+			ServiceVersion:   cp.dstV,
+			Name:             s.Name,
+			ForceMiss:        Compatibool(s.ForceMiss),
+			ForceSSL:         Compatibool(s.ForceSSL),
+			Action:           s.Action,
+			BypassBusyWait:   Compatibool(s.BypassBusyWait),
+			MaxStaleAge:      s.MaxStaleAge,
+			HashKeys:         s.HashKeys,
+			XForwardedFor:    s.XForwardedFor,
+			TimerSupport:     Compatibool(s.TimerSupport),
+			GeoHeaders:       Compatibool(s.GeoHeaders),
+			DefaultHost:      s.DefaultHost,
+			RequestCondition: s.RequestCondition,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cp *serviceCopier) responseObjects() error {
+	ros, err := cp.c.ListResponseObjects(&ListResponseObjectsInput{ServiceID: cp.srcID, ServiceVersion: cp.srcV})
+	if err != nil {
+		return err
+	}
+	for _, ro := range ros {
+		if _, err := cp.c.CreateResponseObject(&CreateResponseObjectInput{
+			ServiceID:        cp.dstID,
+			ServiceVersion:   cp.dstV,
+			Name:             ro.Name,
+			Status:           ro.Status,
+			Response:         ro.Response,
+			Content:          ro.Content,
+			ContentType:      ro.ContentType,
+			RequestCondition: ro.RequestCondition,
+			CacheCondition:   ro.CacheCondition,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cp *serviceCopier) headers() error {
+	hs, err := cp.c.ListHeaders(&ListHeadersInput{ServiceID: cp.srcID, ServiceVersion: cp.srcV})
+	if err != nil {
+		return err
+	}
+	for _, h := range hs {
+		if _, err := cp.c.CreateHeader(&CreateHeaderInput{
+			ServiceID:         cp.dstID,
+			ServiceVersion:    cp.dstV,
+			Name:              h.Name,
+			Action:            h.Action,
+			IgnoreIfSet:       Compatibool(h.IgnoreIfSet),
+			Type:              h.Type,
+			Destination:       h.Destination,
+			Source:            h.Source,
+			Regex:             h.Regex,
+			Substitution:      h.Substitution,
+			Priority:          h.Priority,
+			RequestCondition:  h.RequestCondition,
+			CacheCondition:    h.CacheCondition,
+			ResponseCondition: h.ResponseCondition,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cp *serviceCopier) gzips() error {
+	gs, err := cp.c.ListGzips(&ListGzipsInput{ServiceID: cp.srcID, ServiceVersion: cp.srcV})
+	if err != nil {
+		return err
+	}
+	for _, g := range gs {
+		if _, err := cp.c.CreateGzip(&CreateGzipInput{
+			ServiceID:      cp.dstID,
+			ServiceVersion: cp.dstV,
+			Name:           g.Name,
+			ContentTypes:   g.ContentTypes,
+			Extensions:     g.Extensions,
+			CacheCondition: g.CacheCondition,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cp *serviceCopier) dictionaries() error {
+	ds, err := cp.c.ListDictionaries(&ListDictionariesInput{ServiceID: cp.srcID, ServiceVersion: cp.srcV})
+	if err != nil {
+		return err
+	}
+	for _, d := range ds {
+		nd, err := cp.c.CreateDictionary(&CreateDictionaryInput{
+			ServiceID:      cp.dstID,
+			ServiceVersion: cp.dstV,
+			Name:           d.Name,
+			WriteOnly:      Compatibool(d.WriteOnly),
+		})
+		if err != nil {
+			return err
+		}
+
+		items := make(map[string]string)
+		if !d.WriteOnly {
+			is, err := cp.c.ListDictionaryItems(&ListDictionaryItemsInput{ServiceID: cp.srcID, DictionaryID: d.ID})
+			if err != nil {
+				return err
+			}
+			for _, item := range is {
+				items[item.ItemKey] = item.ItemValue
+			}
+		}
+		if cp.in.RewriteDictionary != nil {
+			items = cp.in.RewriteDictionary(d.Name, items)
+		}
+
+		keys := make([]string, 0, len(items))
+		for k := range items {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, r := range batchRanges(len(keys), BatchModifyMaximumOperations) {
+			batch := make([]*BatchDictionaryItem, 0, r.end-r.start)
+			for _, k := range keys[r.start:r.end] {
+				batch = append(batch, &BatchDictionaryItem{
+					Operation: CreateBatchOperation,
+					ItemKey:   k,
+					ItemValue: items[k],
+				})
+			}
+			if err := cp.c.BatchModifyDictionaryItems(&BatchModifyDictionaryItemsInput{
+				ServiceID:    cp.dstID,
+				DictionaryID: nd.ID,
+				Items:        batch,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (cp *serviceCopier) acls() error {
+	as, err := cp.c.ListACLs(&ListACLsInput{ServiceID: cp.srcID, ServiceVersion: cp.srcV})
+	if err != nil {
+		return err
+	}
+	for _, a := range as {
+		na, err := cp.c.CreateACL(&CreateACLInput{
+			ServiceID:      cp.dstID,
+			ServiceVersion: cp.dstV,
+			Name:           a.Name,
+		})
+		if err != nil {
+			return err
+		}
+
+		es, err := cp.c.ListACLEntries(&ListACLEntriesInput{ServiceID: cp.srcID, ACLID: a.ID})
+		if err != nil {
+			return err
+		}
+		for _, r := range batchRanges(len(es), BatchModifyMaximumOperations) {
+			batch := make([]*BatchACLEntry, 0, r.end-r.start)
+			for _, e := range es[r.start:r.end] {
+				batch = append(batch, &BatchACLEntry{
+					Operation: CreateBatchOperation,
+					IP:        String(e.IP),
+					Subnet:    String(e.Subnet),
+					Negated:   Bool(e.Negated),
+					Comment:   String(e.Comment),
+				})
+			}
+			if err := cp.c.BatchModifyACLEntries(&BatchModifyACLEntriesInput{
+				ServiceID: cp.dstID,
+				ACLID:     na.ID,
+				Entries:   batch,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (cp *serviceCopier) vcls() error {
+	vs, err := cp.c.ListVCLs(&ListVCLsInput{ServiceID: cp.srcID, ServiceVersion: cp.srcV})
+	if err != nil {
+		return err
+	}
+	for _, v := range vs {
+		if _, err := cp.c.CreateVCL(&CreateVCLInput{
+			ServiceID:      cp.dstID,
+			ServiceVersion: cp.dstV,
+			Name:           v.Name,
+			Content:        v.Content,
+			Main:           v.Main,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cp *serviceCopier) snippets() error {
+	ss, err := cp.c.ListSnippets(&ListSnippetsInput{ServiceID: cp.srcID, ServiceVersion: cp.srcV})
+	if err != nil {
+		return err
+	}
+	for _, s := range ss {
+		content := s.Content
+		if s.Dynamic == 1 {
+			// The versioned snippet of a dynamic snippet holds no content; the
+			// live content is only available from the dynamic endpoint.
+			ds, err := cp.c.GetDynamicSnippet(&GetDynamicSnippetInput{ServiceID: cp.srcID, ID: s.ID})
+			if err != nil {
+				return err
+			}
+			content = ds.Content
+		}
+		if _, err := cp.c.CreateSnippet(&CreateSnippetInput{
+			ServiceID:      cp.dstID,
+			ServiceVersion: cp.dstV,
+			Name:           s.Name,
+			Priority:       s.Priority,
+			Dynamic:        s.Dynamic,
+			Content:        content,
+			Type:           s.Type,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// logging copies every logging endpoint. Endpoints are copied field by field
+// from the raw API response so that all providers and their options are
+// covered without a typed mapping for each.
+func (cp *serviceCopier) logging() error {
+	kinds := make([]string, 0, len(copyLoggingKinds))
+	for k := range copyLoggingKinds {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+
+	for _, kind := range kinds {
+		path := fmt.Sprintf("/service/%s/version/%d/logging/%s", cp.srcID, cp.srcV, kind)
+		resp, err := cp.c.Get(path, nil)
+		if err != nil {
+			return err
+		}
+
+		var endpoints []map[string]interface{}
+		if err := decodeBodyMap(resp.Body, &endpoints); err != nil {
+			return err
+		}
+
+		for _, e := range endpoints {
+			form := copyLoggingForm(e)
+			for _, field := range copyLoggingKinds[kind] {
+				if s := cp.secret("logging/"+kind, form["name"], field, form[field]); s != "" {
+					form[field] = s
+				}
+			}
+
+			path := fmt.Sprintf("/service/%s/version/%d/logging/%s", cp.dstID, cp.dstV, kind)
+			resp, err := cp.c.PostForm(path, form, nil)
+			if err != nil {
+				return err
+			}
+			resp.Body.Close()
+		}
+	}
+	return nil
+}
+
+// copyLoggingForm converts a raw logging endpoint into form values suitable
+// for creating it again, dropping read-only and empty fields.
+func copyLoggingForm(e map[string]interface{}) map[string]string {
+	form := make(map[string]string, len(e))
+	for k, v := range e {
+		if copyReadOnlyFields[k] {
+			continue
+		}
+		switch t := v.(type) {
+		case string:
+			if t != "" {
+				form[k] = t
+			}
+		case bool:
+			if t {
+				form[k] = "1"
+			} else {
+				form[k] = "0"
+			}
+		case float64:
+			form[k] = strconv.FormatFloat(t, 'f', -1, 64)
+		}
+	}
+	return form
+}
@@ -0,0 +1,174 @@
+package fastly
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// HistoryVersionCreated is the Kind of a HistoryEntry recording the creation
+// of a version, taken from the version list rather than the event log.
+const HistoryVersionCreated = "version.created"
+
+// HistoryEntry is a single point in a service's history. It is either the
+// creation of a version or an entry of the event log.
+type HistoryEntry struct {
+	// Time is when the entry happened.
+	Time *time.Time
+
+	// Kind is HistoryVersionCreated or the event type, e.g.
+	// "version.activate".
+	Kind string
+
+	// Version is the version number the entry relates to, or zero when it
+	// could not be determined.
+	Version int
+
+	// Description is the human readable description of the entry.
+	Description string
+
+	// UserID and IP identify who caused the entry, when it comes from the
+	// event log.
+	UserID string
+	IP     string
+
+	// Event is the underlying event log entry, or nil for version entries.
+	Event *Event
+}
+
+// ServiceHistory is a chronological timeline of a service's versions and
+// event log entries.
+type ServiceHistory struct {
+	// Versions is the list of versions of the service, sorted by number.
+	Versions []*Version
+
+	// Entries are the history entries, oldest first.
+	Entries []*HistoryEntry
+}
+
+// ForVersion returns the entries related to the given version number.
+func (h *ServiceHistory) ForVersion(number int) []*HistoryEntry {
+	var es []*HistoryEntry
+	for _, e := range h.Entries {
+		if e.Version == number {
+			es = append(es, e)
+		}
+	}
+	return es
+}
+
+// Activations returns the version activation entries, oldest first.
+func (h *ServiceHistory) Activations() []*HistoryEntry {
+	var es []*HistoryEntry
+	for _, e := range h.Entries {
+		if e.Kind == "version.activate" {
+			es = append(es, e)
+		}
+	}
+	return es
+}
+
+// GetServiceHistoryInput is used as input to the GetServiceHistory function.
+type GetServiceHistoryInput struct {
+	// ServiceID is the ID of the service (required).
+	ServiceID string
+
+	// Since, if set, excludes entries that happened before it.
+	Since *time.Time
+}
+
+// GetServiceHistory merges the versions of a service with its event log into
+// a single chronological timeline, relating events such as activations,
+// clones and locks to the version they affected.
+func (c *Client) GetServiceHistory(i *GetServiceHistoryInput) (*ServiceHistory, error) {
+	if i.ServiceID == "" {
+		return nil, ErrMissingServiceID
+	}
+
+	vs, err := c.ListVersions(&ListVersionsInput{ServiceID: i.ServiceID})
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := c.GetAPIEvents(&GetAPIEventsFilterInput{ServiceID: i.ServiceID})
+	if err != nil {
+		return nil, err
+	}
+
+	return newServiceHistory(vs, events.Events, i.Since), nil
+}
+
+// newServiceHistory builds the timeline from versions and events.
+func newServiceHistory(vs []*Version, events []*Event, since *time.Time) *ServiceHistory {
+	h := &ServiceHistory{Versions: vs}
+
+	include := func(t *time.Time) bool {
+		return since == nil || t == nil || !t.Before(*since)
+	}
+
+	for _, v := range vs {
+		if !include(v.CreatedAt) {
+			continue
+		}
+		h.Entries = append(h.Entries, &HistoryEntry{
+			Time:        v.CreatedAt,
+			Kind:        HistoryVersionCreated,
+			Version:     v.Number,
+			Description: v.Comment,
+		})
+	}
+
+	for _, e := range events {
+		if !include(e.CreatedAt) {
+			continue
+		}
+		h.Entries = append(h.Entries, &HistoryEntry{
+			Time:        e.CreatedAt,
+			Kind:        e.EventType,
+			Version:     eventVersion(e),
+			Description: e.Description,
+			UserID:      e.UserID,
+			IP:          e.IP,
+			Event:       e,
+		})
+	}
+
+	sort.SliceStable(h.Entries, func(a, b int) bool {
+		ta, tb := h.Entries[a].Time, h.Entries[b].Time
+		switch {
+		case ta == nil:
+			return false
+		case tb == nil:
+			return true
+		default:
+			return ta.Before(*tb)
+		}
+	})
+	return h
+}
+
+// eventVersionRegexp matches a version number in an event description, e.g.
+// "Healthcheck 'foo' was deleted on Version '23'".
+var eventVersionRegexp = regexp.MustCompile(`(?i)\bversion '?(\d+)'?`)
+
+// eventVersion returns the version number an event relates to, from its
+// metadata or, failing that, its description.
+func eventVersion(e *Event) int {
+	switch v := e.Metadata["version"].(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	case string:
+		if n, err := strconv.Atoi(v); err == nil {
+			return n
+		}
+	}
+
+	if m := eventVersionRegexp.FindStringSubmatch(e.Description); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		return n
+	}
+	return 0
+}
@@ -0,0 +1,77 @@
+package fastly
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClient_GetServiceHistory(t *testing.T) {
+	t.Parallel()
+
+	var err error
+	var h *ServiceHistory
+	record(t, "service_history/get", func(c *Client) {
+		h, err = c.GetServiceHistory(&GetServiceHistoryInput{
+			ServiceID: testServiceID,
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(h.Versions) != 2 {
+		t.Fatalf("bad versions: %v", h.Versions)
+	}
+
+	kinds := []string{
+		HistoryVersionCreated,
+		HistoryVersionCreated,
+		"version.clone",
+		"backend.update",
+		"version.lock",
+		"version.activate",
+	}
+	if len(h.Entries) != len(kinds) {
+		t.Fatalf("bad entries: %d", len(h.Entries))
+	}
+	for i, k := range kinds {
+		if h.Entries[i].Kind != k {
+			t.Errorf("bad kind at %d: %q", i, h.Entries[i].Kind)
+		}
+	}
+
+	as := h.Activations()
+	if len(as) != 1 {
+		t.Fatalf("bad activations: %v", as)
+	}
+	if as[0].Version != 142 || as[0].UserID != "1tSCjJnpQYBBCAsIfI6pX0" || as[0].IP != "92.238.40.48" {
+		t.Errorf("bad activation: %#v", as[0])
+	}
+
+	if es := h.ForVersion(142); len(es) != 4 {
+		t.Errorf("bad entries for version: %d", len(es))
+	}
+}
+
+func TestClient_GetServiceHistory_validation(t *testing.T) {
+	var err error
+	_, err = testClient.GetServiceHistory(&GetServiceHistoryInput{
+		ServiceID: "",
+	})
+	if err != ErrMissingServiceID {
+		t.Errorf("bad error: %s", err)
+	}
+}
+
+func TestNewServiceHistory_since(t *testing.T) {
+	t1 := time.Date(2021, 2, 20, 10, 0, 0, 0, time.UTC)
+	t2 := time.Date(2021, 2, 21, 10, 0, 0, 0, time.UTC)
+
+	h := newServiceHistory(
+		[]*Version{{Number: 1, CreatedAt: &t1}, {Number: 2, CreatedAt: &t2}},
+		[]*Event{{EventType: "version.activate", Description: "Version 1 was activated", CreatedAt: &t1}},
+		&t2,
+	)
+	if len(h.Entries) != 1 || h.Entries[0].Version != 2 {
+		t.Errorf("bad entries: %#v", h.Entries)
+	}
+}
@@ -0,0 +1,228 @@
+package fastly
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// serviceIndex maps service names to IDs and back.
+type serviceIndex struct {
+	byID   map[string]*Service
+	byName map[string]*Service
+}
+
+// serviceIndexMissTTL is how long a service which was not found, even after
+// reloading the index, is reported as missing without reloading it again.
+var serviceIndexMissTTL = time.Minute
+
+// RefreshServiceIndex reloads the client's cached index of service names and
+// IDs, and forgets the services which were not found.
+func (c *Client) RefreshServiceIndex() error {
+	if err := c.loadServiceIndex(); err != nil {
+		return err
+	}
+	c.serviceIndexLock.Lock()
+	c.serviceIndexMisses = nil
+	c.serviceIndexLock.Unlock()
+	return nil
+}
+
+// loadServiceIndex reloads the client's cached index of service names and
+// IDs.
+func (c *Client) loadServiceIndex() error {
+	ss, err := c.ListServices(&ListServicesInput{})
+	if err != nil {
+		return err
+	}
+
+	idx := &serviceIndex{
+		byID:   make(map[string]*Service, len(ss)),
+		byName: make(map[string]*Service, len(ss)),
+	}
+	for _, s := range ss {
+		idx.byID[s.ID] = s
+		idx.byName[s.Name] = s
+	}
+
+	c.serviceIndexLock.Lock()
+	c.serviceIndex = idx
+	c.serviceIndexLock.Unlock()
+	return nil
+}
+
+// lookupService finds a service in the cached index. The index is loaded on
+// first use and reloaded when the service is not found, so services created
+// since the last load are still found. A service still not found is reported
+// as missing without reloading the index for serviceIndexMissTTL, so looking
+// up an unknown service repeatedly does not list every service each time.
+// Key identifies the lookup.
+func (c *Client) lookupService(key string, find func(*serviceIndex) *Service) (*Service, error) {
+	c.serviceIndexLock.Lock()
+	idx := c.serviceIndex
+	missed, isMissing := c.serviceIndexMisses[key]
+	c.serviceIndexLock.Unlock()
+
+	if idx != nil {
+		if s := find(idx); s != nil {
+			return s, nil
+		}
+		if isMissing && time.Since(missed) < serviceIndexMissTTL {
+			return nil, nil
+		}
+	}
+
+	if err := c.loadServiceIndex(); err != nil {
+		return nil, err
+	}
+
+	c.serviceIndexLock.Lock()
+	defer c.serviceIndexLock.Unlock()
+	s := find(c.serviceIndex)
+	if s == nil {
+		if c.serviceIndexMisses == nil {
+			c.serviceIndexMisses = make(map[string]time.Time)
+		}
+		c.serviceIndexMisses[key] = time.Now()
+	} else {
+		delete(c.serviceIndexMisses, key)
+	}
+	return s, nil
+}
+
+// ServiceIDByName returns the ID of the service with the given name, using
+// the client's cached service index.
+func (c *Client) ServiceIDByName(name string) (string, error) {
+	if name == "" {
+		return "", ErrMissingName
+	}
+
+	s, err := c.lookupService("name:"+name, func(idx *serviceIndex) *Service {
+		return idx.byName[name]
+	})
+	if err != nil {
+		return "", err
+	}
+	if s == nil {
+		return "", fmt.Errorf("no service named %q", name)
+	}
+	return s.ID, nil
+}
+
+// ServiceNameByID returns the name of the service with the given ID, using
+// the client's cached service index.
+func (c *Client) ServiceNameByID(id string) (string, error) {
+	if id == "" {
+		return "", ErrMissingID
+	}
+
+	s, err := c.lookupService("id:"+id, func(idx *serviceIndex) *Service {
+		return idx.byID[id]
+	})
+	if err != nil {
+		return "", err
+	}
+	if s == nil {
+		return "", fmt.Errorf("no service with ID %q", id)
+	}
+	return s.Name, nil
+}
+
+// ServiceDomainMatch is a service that has a domain in one or more of its
+// versions.
+type ServiceDomainMatch struct {
+	// Service is the matching service.
+	Service *Service
+
+	// Domain is the configured domain that matched, which may be a wildcard.
+	Domain string
+
+	// Versions lists the versions of the service containing the domain.
+	Versions []int
+
+	// Active is true when the active version contains the domain, meaning
+	// the service currently owns it.
+	Active bool
+}
+
+// FindServicesByDomainInput is used as input to the FindServicesByDomain
+// function.
+type FindServicesByDomainInput struct {
+	// Domain is the hostname to look up (required). Wildcard domains such as
+	// "*.example.com" configured on a service match their subdomains.
+	Domain string
+
+	// Type limits the search to services of the given type.
+	Type string
+}
+
+// FindServicesByDomain returns the services which have the given domain in
+// any of their versions. The service whose active version contains the domain
+// is listed first.
+func (c *Client) FindServicesByDomain(i *FindServicesByDomainInput) ([]*ServiceDomainMatch, error) {
+	if i.Domain == "" {
+		return nil, ErrMissingDomain
+	}
+
+	ss, err := c.ListServices(&ListServicesInput{Type: i.Type})
+	if err != nil {
+		return nil, err
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(i.Domain, "."))
+
+	var matches []*ServiceDomainMatch
+	for _, s := range ss {
+		ds, err := c.ListServiceDomains(&ListServiceDomainInput{ID: s.ID})
+		if err != nil {
+			return nil, err
+		}
+
+		byDomain := make(map[string]*ServiceDomainMatch)
+		for _, d := range ds {
+			if !domainMatches(d.Name, host) {
+				continue
+			}
+			m, ok := byDomain[d.Name]
+			if !ok {
+				m = &ServiceDomainMatch{Service: s, Domain: d.Name}
+				byDomain[d.Name] = m
+				matches = append(matches, m)
+			}
+			m.Versions = append(m.Versions, int(d.ServiceVersion))
+			if uint(d.ServiceVersion) == s.ActiveVersion {
+				m.Active = true
+			}
+		}
+	}
+
+	for _, m := range matches {
+		sort.Ints(m.Versions)
+	}
+	sort.SliceStable(matches, func(a, b int) bool {
+		if matches[a].Active != matches[b].Active {
+			return matches[a].Active
+		}
+		// Exact matches are more specific than wildcard matches.
+		return !strings.HasPrefix(matches[a].Domain, "*.") && strings.HasPrefix(matches[b].Domain, "*.")
+	})
+	return matches, nil
+}
+
+// domainMatches reports whether a configured domain, which may be a wildcard
+// like "*.example.com", matches the given lower-case hostname.
+func domainMatches(domain, host string) bool {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	if domain == host {
+		return true
+	}
+	if strings.HasPrefix(domain, "*.") {
+		suffix := domain[1:]
+		if strings.HasSuffix(host, suffix) {
+			label := strings.TrimSuffix(host, suffix)
+			return label != "" && !strings.Contains(label, ".")
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+package fastly
+
+import "testing"
+
+func TestClient_ServiceIndex(t *testing.T) {
+	t.Parallel()
+
+	var err error
+	var id, name string
+	record(t, "services/index", func(c *Client) {
+		id, err = c.ServiceIDByName("httpbin proxy")
+		if err != nil {
+			return
+		}
+
+		// Served from the cached index.
+		name, err = c.ServiceNameByID("7i6HN3TK9wS159v2gPAZ8A")
+		if err != nil {
+			return
+		}
+
+		// Not in the cached index, so the index is reloaded.
+		_, err = c.ServiceIDByName("tf-test-29z4qkpz3z")
+		if err != nil {
+			return
+		}
+
+		// An unknown service reloads the index once, then is reported
+		// missing from the cache.
+		for n := 0; n < 3; n++ {
+			if _, err := c.ServiceIDByName("nope"); err == nil || err.Error() != `no service named "nope"` {
+				t.Errorf("bad error: %v", err)
+			}
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "3dO1abc6FDqYDYrc6QbfEU" {
+		t.Errorf("bad id: %q", id)
+	}
+	if name != "Go-Fastly Tests" {
+		t.Errorf("bad name: %q", name)
+	}
+}
+
+func TestClient_FindServicesByDomain(t *testing.T) {
+	t.Parallel()
+
+	var err error
+	var ms []*ServiceDomainMatch
+	record(t, "services/find_by_domain", func(c *Client) {
+		ms, err = c.FindServicesByDomain(&FindServicesByDomainInput{
+			Domain: "WWW.example.com.",
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ms) != 2 {
+		t.Fatalf("bad matches: %v", ms)
+	}
+	if ms[0].Service.ID != "3dO1abc6FDqYDYrc6QbfEU" || ms[0].Domain != "*.example.com" || !ms[0].Active {
+		t.Errorf("bad match: %#v", ms[0])
+	}
+	if ms[1].Service.ID != "7i6HN3TK9wS159v2gPAZ8A" || ms[1].Active {
+		t.Errorf("bad match: %#v", ms[1])
+	}
+	if len(ms[1].Versions) != 2 || ms[1].Versions[0] != 1 || ms[1].Versions[1] != 3 {
+		t.Errorf("bad versions: %v", ms[1].Versions)
+	}
+}
+
+func TestClient_FindServicesByDomain_validation(t *testing.T) {
+	var err error
+	_, err = testClient.FindServicesByDomain(&FindServicesByDomainInput{})
+	if err != ErrMissingDomain {
+		t.Errorf("bad error: %s", err)
+	}
+}
+
+func TestDomainMatches(t *testing.T) {
+	cases := []struct {
+		domain string
+		host   string
+		match  bool
+	}{
+		{"www.example.com", "www.example.com", true},
+		{"WWW.Example.com", "www.example.com", true},
+		{"*.example.com", "www.example.com", true},
+		{"*.example.com", "example.com", false},
+		{"*.example.com", "a.b.example.com", false},
+		{"www.example.com", "example.com", false},
+	}
+	for _, c := range cases {
+		if got := domainMatches(c.domain, c.host); got != c.match {
+			t.Errorf("domainMatches(%q, %q) = %t", c.domain, c.host, got)
+		}
+	}
+}
@@ -160,6 +160,39 @@ func TestClient_Services(t *testing.T) {
 	}
 }
 
+func TestClient_ListServices_paginated(t *testing.T) {
+	t.Parallel()
+
+	var err error
+	var ss []*Service
+	record(t, "services/list_paginated", func(c *Client) {
+		ss, err = c.ListServices(&ListServicesInput{
+			Page:      1,
+			PerPage:   3,
+			Sort:      "created",
+			Direction: "descend",
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ss) != 3 {
+		t.Fatalf("bad services: %v", ss)
+	}
+	// The API order is kept when sorting is requested.
+	if ss[0].Name != "Go-Fastly Tests" || ss[1].Name != "tf-test-29z4qkpz3z" || ss[2].Name != "httpbin proxy" {
+		t.Errorf("bad order: %q, %q, %q", ss[0].Name, ss[1].Name, ss[2].Name)
+	}
+}
+
+func TestClient_ListServices_validation(t *testing.T) {
+	// The type is filtered locally, which would return short pages.
+	_, err := testClient.ListServices(&ListServicesInput{PerPage: 3, Type: ServiceTypeVCL})
+	if err == nil || err.Error() != "problem with field 'Type': cannot be combined with Page or PerPage" {
+		t.Errorf("bad error: %v", err)
+	}
+}
+
 func TestClient_GetService_validation(t *testing.T) {
 	var err error
 	_, err = testClient.GetService(&GetServiceInput{})
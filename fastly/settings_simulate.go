@@ -0,0 +1,414 @@
+package fastly
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fastly/go-fastly/v3/fastly/vcl"
+)
+
+// DefaultSimulatedTTL is the TTL given by SimulateSettings to responses
+// without caching headers, as Fastly does by default.
+const DefaultSimulatedTTL = 3600 * time.Second
+
+// defaultHashKeys are the variables the cache key is made of by default.
+var defaultHashKeys = []string{"req.url", "req.http.host"}
+
+// cacheableStatuses are the response statuses cached by default.
+var cacheableStatuses = map[int]bool{200: true, 203: true, 300: true, 301: true, 302: true, 404: true, 410: true}
+
+// SimulateSettingsInput is used as input to the SimulateSettings function.
+type SimulateSettingsInput struct {
+	// RequestSettings, CacheSettings and Conditions are the objects of a
+	// version, as returned by ListRequestSettings, ListCacheSettings and
+	// ListConditions.
+	RequestSettings []*RequestSetting
+	CacheSettings   []*CacheSetting
+	Conditions      []*Condition
+
+	// Request is the client request (required). Its scheme decides whether
+	// the request is over TLS.
+	Request *http.Request
+
+	// ClientIP is the address of the client, used in conditions and
+	// X-Forwarded-For. Defaults to 192.0.2.1.
+	ClientIP net.IP
+
+	// Response is the response of the backend. When it is nil, only the
+	// request settings are simulated.
+	Response *http.Response
+
+	// DefaultTTL is the TTL of responses without caching headers. Defaults to
+	// DefaultSimulatedTTL.
+	DefaultTTL time.Duration
+}
+
+// SimulatedSetting reports how a setting was evaluated.
+type SimulatedSetting struct {
+	Name      string
+	Condition string
+
+	// Applied reports whether the setting applied, because it has no
+	// condition or its condition was true.
+	Applied bool
+
+	// Skipped reports whether the setting was not evaluated because an
+	// earlier setting with an action ended the subroutine.
+	Skipped bool
+}
+
+// SettingsSimulation is the result of SimulateSettings.
+type SettingsSimulation struct {
+	// RequestSettings and CacheSettings are the settings in the order they
+	// were evaluated.
+	RequestSettings []*SimulatedSetting
+	CacheSettings   []*SimulatedSetting
+
+	// Action is the decision to look the request up in the cache or to pass
+	// it to the backend.
+	Action RequestSettingAction
+
+	// Redirect is the URL the client is redirected to by a request setting
+	// forcing TLS, or "".
+	Redirect string
+
+	ForceMiss      bool
+	BypassBusyWait bool
+	TimerSupport   bool
+	GeoHeaders     bool
+	MaxStaleAge    time.Duration
+
+	// Host is the Host header sent to the backend.
+	Host string
+
+	// HashKeys are the variables the cache key is made of.
+	HashKeys []string
+
+	// XForwardedFor is the X-Forwarded-For mode of the request and
+	// XForwardedForHeader the resulting header, "" when it is removed.
+	XForwardedFor       RequestSettingXFF
+	XForwardedForHeader string
+
+	// CacheAction is the action of the cache setting which ended vcl_fetch,
+	// or "".
+	CacheAction CacheSettingAction
+
+	// TTL and StaleTTL are the time to live and stale if error period of the
+	// response.
+	TTL      time.Duration
+	StaleTTL time.Duration
+
+	// Cached reports whether the response is stored in the cache.
+	Cached bool
+}
+
+// SimulateSettings simulates locally the request and cache settings of a
+// version for a request and a backend response, to test caching policy changes
+// before deploying them.
+//
+// Settings without a condition are evaluated first, then the others by
+// increasing priority of their condition, and by name. Every setting whose
+// condition is true applies and overrides the values set by the previous
+// ones, but a setting with an action ends the subroutine, so that the
+// following settings are skipped. Conditions are evaluated by vcl.Eval, with
+// the request variables and, for cache conditions, the beresp variables.
+//
+// Without request settings deciding otherwise, GET and HEAD requests are
+// looked up and other requests are passed. Without cache settings deciding
+// otherwise, responses with a cacheable status, a positive TTL, no Set-Cookie
+// header and no private, no-store or no-cache directive are cached.
+func SimulateSettings(i *SimulateSettingsInput) (*SettingsSimulation, error) {
+	if i.Request == nil {
+		return nil, ErrMissingRequest
+	}
+
+	clientIP := i.ClientIP
+	if clientIP == nil {
+		clientIP = net.ParseIP("192.0.2.1")
+	}
+	conditions := newConditionSet(i.Conditions)
+	env := simulatedRequestEnv(i.Request, clientIP)
+
+	s := &SettingsSimulation{
+		Action:        RequestSettingActionLookup,
+		Host:          i.Request.Host,
+		HashKeys:      append([]string(nil), defaultHashKeys...),
+		XForwardedFor: RequestSettingXFFAppend,
+	}
+	if m := i.Request.Method; m != http.MethodGet && m != http.MethodHead && m != "FASTLYPURGE" {
+		s.Action = RequestSettingActionPass
+	}
+
+	requestSettings := append([]*RequestSetting(nil), i.RequestSettings...)
+	sort.SliceStable(requestSettings, func(a, b int) bool {
+		return settingLess(conditions, requestSettings[a].RequestCondition, requestSettings[b].RequestCondition, requestSettings[a].Name, requestSettings[b].Name)
+	})
+	done := false
+	for _, rs := range requestSettings {
+		sim := &SimulatedSetting{Name: rs.Name, Condition: rs.RequestCondition, Skipped: done}
+		s.RequestSettings = append(s.RequestSettings, sim)
+		if done {
+			continue
+		}
+		m, err := conditions.match(rs.RequestCondition, env, "REQUEST")
+		if err != nil {
+			return nil, fmt.Errorf("request setting %q: %s", rs.Name, err)
+		}
+		if !m {
+			continue
+		}
+		sim.Applied = true
+		done = s.applyRequestSetting(rs, i.Request, env)
+	}
+
+	s.XForwardedForHeader = simulatedXFF(s.XForwardedFor, i.Request.Header.Get("X-Forwarded-For"), clientIP)
+	if i.Response == nil || s.Redirect != "" {
+		return s, nil
+	}
+
+	ttl, cacheable := simulatedTTL(i.Response, i.DefaultTTL)
+	s.TTL = ttl
+	env.SetHeaders("beresp.http.", i.Response.Header)
+	env["beresp.status"] = int64(i.Response.StatusCode)
+	env["beresp.ttl"] = ttl
+	env["beresp.cacheable"] = cacheable
+
+	cacheSettings := append([]*CacheSetting(nil), i.CacheSettings...)
+	sort.SliceStable(cacheSettings, func(a, b int) bool {
+		return settingLess(conditions, cacheSettings[a].CacheCondition, cacheSettings[b].CacheCondition, cacheSettings[a].Name, cacheSettings[b].Name)
+	})
+	done = false
+	for _, cs := range cacheSettings {
+		sim := &SimulatedSetting{Name: cs.Name, Condition: cs.CacheCondition, Skipped: done}
+		s.CacheSettings = append(s.CacheSettings, sim)
+		if done {
+			continue
+		}
+		m, err := conditions.match(cs.CacheCondition, env, "CACHE")
+		if err != nil {
+			return nil, fmt.Errorf("cache setting %q: %s", cs.Name, err)
+		}
+		if !m {
+			continue
+		}
+		sim.Applied = true
+		s.TTL = time.Duration(cs.TTL) * time.Second
+		s.StaleTTL = time.Duration(cs.StaleTTL) * time.Second
+		env["beresp.ttl"] = s.TTL
+		if cs.Action != "" {
+			s.CacheAction = cs.Action
+			done = true
+		}
+	}
+
+	switch s.CacheAction {
+	case CacheSettingActionCache:
+		cacheable = true
+	case CacheSettingActionPass, CacheSettingActionRestart:
+		cacheable = false
+	}
+	s.Cached = s.Action == RequestSettingActionLookup && cacheable && s.TTL > 0
+	return s, nil
+}
+
+// settingLess orders settings by condition priority and name.
+func settingLess(conditions conditionSet, condA, condB, nameA, nameB string) bool {
+	pa, pb := conditions.priority(condA), conditions.priority(condB)
+	if pa != pb {
+		return pa < pb
+	}
+	return nameA < nameB
+}
+
+// applyRequestSetting applies a request setting and reports whether it ends
+// vcl_recv.
+func (s *SettingsSimulation) applyRequestSetting(rs *RequestSetting, r *http.Request, env vcl.Env) bool {
+	if rs.DefaultHost != "" && s.Host == "" {
+		s.Host = rs.DefaultHost
+		env["req.http.host"] = rs.DefaultHost
+	}
+	if rs.HashKeys != "" {
+		s.HashKeys = nil
+		for _, k := range strings.Split(rs.HashKeys, ",") {
+			if k = strings.TrimSpace(k); k != "" {
+				s.HashKeys = append(s.HashKeys, k)
+			}
+		}
+	}
+	if rs.XForwardedFor != "" {
+		s.XForwardedFor = rs.XForwardedFor
+	}
+	if rs.MaxStaleAge != 0 {
+		s.MaxStaleAge = time.Duration(rs.MaxStaleAge) * time.Second
+	}
+	s.ForceMiss = s.ForceMiss || rs.ForceMiss
+	s.BypassBusyWait = s.BypassBusyWait || rs.BypassBusyWait
+	s.TimerSupport = s.TimerSupport || rs.TimerSupport
+	s.GeoHeaders = s.GeoHeaders || rs.GeoHeaders
+
+	if rs.ForceSSL && r.URL.Scheme != "https" {
+		s.Redirect = "https://" + s.Host + r.URL.RequestURI()
+		return true
+	}
+	if rs.Action != "" {
+		s.Action = rs.Action
+		return true
+	}
+	return false
+}
+
+// simulatedRequestEnv returns the request variables of r.
+func simulatedRequestEnv(r *http.Request, clientIP net.IP) vcl.Env {
+	env := vcl.Env{
+		"req.url":          r.URL.RequestURI(),
+		"req.url.path":     r.URL.EscapedPath(),
+		"req.url.basename": path.Base(r.URL.EscapedPath()),
+		"req.url.dirname":  path.Dir(r.URL.EscapedPath()),
+		"req.url.ext":      strings.TrimPrefix(path.Ext(r.URL.EscapedPath()), "."),
+		"req.method":       r.Method,
+		"req.request":      r.Method,
+		"req.proto":        r.Proto,
+		"req.is_ssl":       r.URL.Scheme == "https",
+		"req.is_ipv6":      clientIP.To4() == nil,
+		"req.restarts":     int64(0),
+		"client.ip":        clientIP,
+	}
+	if r.URL.RawQuery != "" {
+		env["req.url.qs"] = r.URL.RawQuery
+	}
+	env.SetHeaders("req.http.", r.Header)
+	if r.Host != "" {
+		env["req.http.host"] = r.Host
+	}
+	return env
+}
+
+// simulatedXFF returns the X-Forwarded-For header resulting from mode.
+func simulatedXFF(mode RequestSettingXFF, header string, clientIP net.IP) string {
+	switch mode {
+	case RequestSettingXFFClear:
+		return ""
+	case RequestSettingXFFLeave:
+		return header
+	case RequestSettingXFFOverwrite:
+		return clientIP.String()
+	}
+	if header == "" {
+		return clientIP.String()
+	}
+	return header + ", " + clientIP.String()
+}
+
+// simulatedTTL returns the TTL of a backend response from its caching
+// headers, and whether it is cacheable by default.
+func simulatedTTL(resp *http.Response, defaultTTL time.Duration) (time.Duration, bool) {
+	if defaultTTL == 0 {
+		defaultTTL = DefaultSimulatedTTL
+	}
+	cacheable := cacheableStatuses[resp.StatusCode] && resp.Header.Get("Set-Cookie") == ""
+
+	cc := cacheControlDirectives(resp.Header.Get("Cache-Control"))
+	for _, d := range []string{"private", "no-store", "no-cache"} {
+		if _, ok := cc[d]; ok {
+			cacheable = false
+		}
+	}
+
+	sc := cacheControlDirectives(resp.Header.Get("Surrogate-Control"))
+	for _, v := range []string{sc["max-age"], cc["s-maxage"], cc["max-age"]} {
+		if n, err := strconv.Atoi(v); err == nil {
+			return time.Duration(n) * time.Second, cacheable
+		}
+	}
+	if e := resp.Header.Get("Expires"); e != "" {
+		expires, err := http.ParseTime(e)
+		if err != nil {
+			return 0, cacheable
+		}
+		now := time.Now()
+		if d := resp.Header.Get("Date"); d != "" {
+			if t, err := http.ParseTime(d); err == nil {
+				now = t
+			}
+		}
+		if ttl := expires.Sub(now); ttl > 0 {
+			return ttl.Truncate(time.Second), cacheable
+		}
+		return 0, cacheable
+	}
+	return defaultTTL, cacheable
+}
+
+// cacheControlDirectives parses a Cache-Control or Surrogate-Control header.
+func cacheControlDirectives(header string) map[string]string {
+	directives := make(map[string]string)
+	for _, d := range strings.Split(header, ",") {
+		d = strings.TrimSpace(d)
+		if d == "" {
+			continue
+		}
+		name, value := d, ""
+		if n := strings.IndexByte(d, '='); n >= 0 {
+			name, value = d[:n], strings.Trim(d[n+1:], `"`)
+		}
+		directives[strings.ToLower(name)] = value
+	}
+	return directives
+}
+
+// SimulateVersionSettingsInput is used as input to the SimulateVersionSettings
+// function.
+type SimulateVersionSettingsInput struct {
+	// ServiceID is the ID of the service (required).
+	ServiceID string
+
+	// ServiceVersion is the specific configuration version (required).
+	ServiceVersion int
+
+	// Request, ClientIP, Response and DefaultTTL are as in
+	// SimulateSettingsInput. Request is required.
+	Request    *http.Request
+	ClientIP   net.IP
+	Response   *http.Response
+	DefaultTTL time.Duration
+}
+
+// SimulateVersionSettings gets the request settings, cache settings and
+// conditions of a version and simulates them, see SimulateSettings.
+func (c *Client) SimulateVersionSettings(i *SimulateVersionSettingsInput) (*SettingsSimulation, error) {
+	if i.ServiceID == "" {
+		return nil, ErrMissingServiceID
+	}
+
+	if i.ServiceVersion == 0 {
+		return nil, ErrMissingServiceVersion
+	}
+
+	if i.Request == nil {
+		return nil, ErrMissingRequest
+	}
+
+	in := &SimulateSettingsInput{
+		Request:    i.Request,
+		ClientIP:   i.ClientIP,
+		Response:   i.Response,
+		DefaultTTL: i.DefaultTTL,
+	}
+	var err error
+	if in.RequestSettings, err = c.ListRequestSettings(&ListRequestSettingsInput{ServiceID: i.ServiceID, ServiceVersion: i.ServiceVersion}); err != nil {
+		return nil, err
+	}
+	if in.CacheSettings, err = c.ListCacheSettings(&ListCacheSettingsInput{ServiceID: i.ServiceID, ServiceVersion: i.ServiceVersion}); err != nil {
+		return nil, err
+	}
+	if in.Conditions, err = c.ListConditions(&ListConditionsInput{ServiceID: i.ServiceID, ServiceVersion: i.ServiceVersion}); err != nil {
+		return nil, err
+	}
+	return SimulateSettings(in)
+}
@@ -0,0 +1,198 @@
+package fastly
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func simulatedSettingsString(settings []*SimulatedSetting) string {
+	var s []string
+	for _, ss := range settings {
+		s = append(s, fmt.Sprintf("%s applied=%t skipped=%t", ss.Name, ss.Applied, ss.Skipped))
+	}
+	return strings.Join(s, "; ")
+}
+
+func TestClient_SimulateVersionSettings(t *testing.T) {
+	t.Parallel()
+
+	req, _ := http.NewRequest("GET", "http://www.example.com/static/app.css?v=2", nil)
+	req.Host = ""
+	req.Header.Set("X-Forwarded-For", "203.0.113.9")
+	resp := &http.Response{StatusCode: 200, Header: http.Header{
+		"Cache-Control": {"max-age=60"},
+		"Set-Cookie":    {"session=1"},
+	}}
+
+	var err error
+	var s *SettingsSimulation
+	record(t, "request_settings/simulate", func(c *Client) {
+		s, err = c.SimulateVersionSettings(&SimulateVersionSettingsInput{
+			ServiceID:      testServiceID,
+			ServiceVersion: 3,
+			Request:        req,
+			ClientIP:       net.ParseIP("198.51.100.7"),
+			Response:       resp,
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := simulatedSettingsString(s.RequestSettings), "defaults applied=true skipped=false; api-pass applied=false skipped=false"; got != want {
+		t.Errorf("bad request settings: %s, want %s", got, want)
+	}
+	if got, want := simulatedSettingsString(s.CacheSettings), "errors-short applied=false skipped=false; static-long applied=true skipped=false"; got != want {
+		t.Errorf("bad cache settings: %s, want %s", got, want)
+	}
+	if s.Action != RequestSettingActionLookup {
+		t.Errorf("bad action: %q", s.Action)
+	}
+	if s.Host != "www.example.com" {
+		t.Errorf("bad host: %q", s.Host)
+	}
+	if got := strings.Join(s.HashKeys, ","); got != "req.url,req.http.host,req.http.Accept-Language" {
+		t.Errorf("bad hash keys: %q", got)
+	}
+	if s.XForwardedFor != RequestSettingXFFAppend || s.XForwardedForHeader != "203.0.113.9, 198.51.100.7" {
+		t.Errorf("bad X-Forwarded-For: %q %q", s.XForwardedFor, s.XForwardedForHeader)
+	}
+	if s.MaxStaleAge != time.Minute {
+		t.Errorf("bad max stale age: %s", s.MaxStaleAge)
+	}
+	if s.CacheAction != CacheSettingActionCache || s.TTL != 24*time.Hour || s.StaleTTL != time.Hour || !s.Cached {
+		t.Errorf("bad cache result: action=%q ttl=%s stale=%s cached=%t", s.CacheAction, s.TTL, s.StaleTTL, s.Cached)
+	}
+}
+
+func TestSimulateSettings(t *testing.T) {
+	t.Parallel()
+
+	conditions := []*Condition{
+		{Name: "is_api", Type: "REQUEST", Priority: 10, Statement: `req.url ~ "^/api/"`},
+		{Name: "is_error", Type: "CACHE", Priority: 10, Statement: `beresp.status >= 500`},
+		{Name: "is_static", Type: "CACHE", Priority: 20, Statement: `req.url.ext ~ "^(css|js)$"`},
+	}
+	requestSettings := []*RequestSetting{
+		{Name: "api-pass", Action: RequestSettingActionPass, XForwardedFor: RequestSettingXFFOverwrite, RequestCondition: "is_api"},
+		{Name: "force-miss", ForceMiss: true, RequestCondition: "is_api"},
+	}
+	cacheSettings := []*CacheSetting{
+		{Name: "errors-short", Action: CacheSettingActionPass, CacheCondition: "is_error"},
+		{Name: "static-long", Action: CacheSettingActionCache, TTL: 86400, CacheCondition: "is_static"},
+	}
+
+	req, _ := http.NewRequest("GET", "https://www.example.com/api/users", nil)
+	req.Header.Set("X-Forwarded-For", "203.0.113.9")
+	s, err := SimulateSettings(&SimulateSettingsInput{
+		RequestSettings: requestSettings,
+		CacheSettings:   cacheSettings,
+		Conditions:      conditions,
+		Request:         req,
+		Response:        &http.Response{StatusCode: 503, Header: http.Header{}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := simulatedSettingsString(s.RequestSettings), "api-pass applied=true skipped=false; force-miss applied=false skipped=true"; got != want {
+		t.Errorf("bad request settings: %s, want %s", got, want)
+	}
+	if got, want := simulatedSettingsString(s.CacheSettings), "errors-short applied=true skipped=false; static-long applied=false skipped=true"; got != want {
+		t.Errorf("bad cache settings: %s, want %s", got, want)
+	}
+	if s.Action != RequestSettingActionPass || s.ForceMiss || s.XForwardedForHeader != "192.0.2.1" {
+		t.Errorf("bad request result: action=%q force_miss=%t xff=%q", s.Action, s.ForceMiss, s.XForwardedForHeader)
+	}
+	if s.CacheAction != CacheSettingActionPass || s.TTL != 0 || s.Cached {
+		t.Errorf("bad cache result: action=%q ttl=%s cached=%t", s.CacheAction, s.TTL, s.Cached)
+	}
+
+	// Without settings, the caching headers of the response decide.
+	req, _ = http.NewRequest("GET", "http://www.example.com/", nil)
+	s, err = SimulateSettings(&SimulateSettingsInput{
+		Request:  req,
+		Response: &http.Response{StatusCode: 200, Header: http.Header{"Surrogate-Control": {"max-age=300"}, "Cache-Control": {"max-age=60"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.TTL != 5*time.Minute || !s.Cached || strings.Join(s.HashKeys, ",") != "req.url,req.http.host" {
+		t.Errorf("bad default result: ttl=%s cached=%t hash=%v", s.TTL, s.Cached, s.HashKeys)
+	}
+
+	// A setting forcing TLS redirects plain HTTP requests.
+	s, err = SimulateSettings(&SimulateSettingsInput{
+		RequestSettings: []*RequestSetting{{Name: "tls", ForceSSL: true}},
+		Request:         req,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Redirect != "https://www.example.com/" {
+		t.Errorf("bad redirect: %q", s.Redirect)
+	}
+
+	// Changing the hash keys of a result does not change those of others.
+	s.HashKeys[0] = "req.http.Cookie"
+	s, err = SimulateSettings(&SimulateSettingsInput{Request: req})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(s.HashKeys, ","); got != "req.url,req.http.host" {
+		t.Errorf("bad default hash keys: %s", got)
+	}
+}
+
+func TestSimulateSettings_validation(t *testing.T) {
+	t.Parallel()
+
+	_, err := SimulateSettings(&SimulateSettingsInput{})
+	if err != ErrMissingRequest {
+		t.Errorf("bad error: %s", err)
+	}
+
+	req, _ := http.NewRequest("GET", "http://www.example.com/", nil)
+	_, err = SimulateSettings(&SimulateSettingsInput{
+		RequestSettings: []*RequestSetting{{Name: "a", RequestCondition: "missing"}},
+		Request:         req,
+	})
+	if err == nil || err.Error() != `request setting "a": unknown condition "missing"` {
+		t.Errorf("bad error: %v", err)
+	}
+
+	_, err = SimulateSettings(&SimulateSettingsInput{
+		RequestSettings: []*RequestSetting{{Name: "a", RequestCondition: "c"}},
+		Conditions:      []*Condition{{Name: "c", Type: "CACHE", Statement: "true"}},
+		Request:         req,
+	})
+	if err == nil || err.Error() != `request setting "a": condition "c" is a CACHE condition, not REQUEST` {
+		t.Errorf("bad error: %v", err)
+	}
+
+	_, err = testClient.SimulateVersionSettings(&SimulateVersionSettingsInput{
+		ServiceID: "",
+	})
+	if err != ErrMissingServiceID {
+		t.Errorf("bad error: %s", err)
+	}
+
+	_, err = testClient.SimulateVersionSettings(&SimulateVersionSettingsInput{
+		ServiceID:      "foo",
+		ServiceVersion: 0,
+	})
+	if err != ErrMissingServiceVersion {
+		t.Errorf("bad error: %s", err)
+	}
+
+	_, err = testClient.SimulateVersionSettings(&SimulateVersionSettingsInput{
+		ServiceID:      "foo",
+		ServiceVersion: 1,
+	})
+	if err != ErrMissingRequest {
+		t.Errorf("bad error: %s", err)
+	}
+}
@@ -0,0 +1,203 @@
+package fastly
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"sort"
+	"strings"
+)
+
+// earthRadiusKm is the mean radius of the Earth in kilometres.
+const earthRadiusKm = 6371.0
+
+// ShieldCandidate is a shield-capable datacenter and its distance from an
+// origin.
+type ShieldCandidate struct {
+	Datacenter Datacenter
+
+	// Distance is the great-circle distance from the origin in kilometres.
+	Distance float64
+}
+
+// ShieldQuery describes the origin to find a shield for.
+type ShieldQuery struct {
+	// Origin is the location of the origin. Only Latitude and Longtitude are
+	// used.
+	Origin *Coordinates
+
+	// Region is used when Origin is not set. It is either a datacenter code,
+	// such as "AMS", or a datacenter group, such as "Europe", in which case
+	// the centre of the group's datacenters is used.
+	Region string
+
+	// Limit is the maximum number of candidates returned. Zero means all.
+	Limit int
+}
+
+// RankShields returns the shield-capable datacenters among dcs ordered by
+// their great-circle distance from the origin described by q, nearest first.
+// A nil q is treated as an empty query.
+func RankShields(dcs []Datacenter, q *ShieldQuery) ([]*ShieldCandidate, error) {
+	if q == nil {
+		q = &ShieldQuery{}
+	}
+
+	origin := q.Origin
+	if origin == nil {
+		if q.Region == "" {
+			return nil, ErrMissingOriginRegion
+		}
+		var err error
+		if origin, err = regionCoordinates(dcs, q.Region); err != nil {
+			return nil, err
+		}
+	}
+
+	var cs []*ShieldCandidate
+	for _, dc := range dcs {
+		if dc.Shield == "" {
+			continue
+		}
+		cs = append(cs, &ShieldCandidate{
+			Datacenter: dc,
+			Distance:   greatCircleDistance(origin, &dc.Coordinates),
+		})
+	}
+	sort.SliceStable(cs, func(a, b int) bool {
+		return cs[a].Distance < cs[b].Distance
+	})
+
+	if q.Limit > 0 && len(cs) > q.Limit {
+		cs = cs[:q.Limit]
+	}
+	return cs, nil
+}
+
+// RecommendShield returns the nearest shield-capable datacenter to the origin
+// described by q. Its Datacenter.Shield is the value to use as the Shield of
+// a Backend, Director or Pool.
+//
+// The datacenter list is fetched once and cached by the client. Use
+// SetDatacenters to provide a list loaded with ReadDatacenters instead.
+func (c *Client) RecommendShield(q *ShieldQuery) (*ShieldCandidate, error) {
+	if q == nil || (q.Origin == nil && q.Region == "") {
+		return nil, ErrMissingOriginRegion
+	}
+
+	dcs, err := c.cachedDatacenters()
+	if err != nil {
+		return nil, err
+	}
+
+	cs, err := RankShields(dcs, &ShieldQuery{Origin: q.Origin, Region: q.Region, Limit: 1})
+	if err != nil {
+		return nil, err
+	}
+	if len(cs) == 0 {
+		return nil, fmt.Errorf("no shield-capable datacenter found")
+	}
+	return cs[0], nil
+}
+
+// SetDatacenters replaces the client's cached datacenter list, e.g. with one
+// loaded by ReadDatacenters for offline use.
+func (c *Client) SetDatacenters(dcs []Datacenter) {
+	c.datacentersLock.Lock()
+	c.datacenters = dcs
+	c.datacentersLock.Unlock()
+}
+
+// cachedDatacenters returns the cached datacenter list, fetching it on first
+// use.
+func (c *Client) cachedDatacenters() ([]Datacenter, error) {
+	c.datacentersLock.Lock()
+	defer c.datacentersLock.Unlock()
+
+	if c.datacenters == nil {
+		dcs, err := c.AllDatacenters()
+		if err != nil {
+			return nil, err
+		}
+		c.datacenters = dcs
+	}
+	return c.datacenters, nil
+}
+
+// WriteDatacenters writes dcs to w as JSON, in the format returned by the API,
+// so that it can be cached and read back with ReadDatacenters.
+func WriteDatacenters(w io.Writer, dcs []Datacenter) error {
+	out := make([]map[string]interface{}, len(dcs))
+	for n, dc := range dcs {
+		out[n] = map[string]interface{}{
+			"code":  dc.Code,
+			"name":  dc.Name,
+			"group": dc.Group,
+			"coordinates": map[string]float64{
+				"x":         dc.Coordinates.X,
+				"y":         dc.Coordinates.Y,
+				"latitude":  dc.Coordinates.Latitude,
+				"longitude": dc.Coordinates.Longtitude,
+			},
+			"shield": dc.Shield,
+		}
+	}
+	return json.NewEncoder(w).Encode(out)
+}
+
+// ReadDatacenters reads a datacenter list written by WriteDatacenters or
+// saved from the API's /datacenters endpoint.
+func ReadDatacenters(r io.Reader) ([]Datacenter, error) {
+	var dcs []Datacenter
+	if err := decodeBodyMap(ioutil.NopCloser(r), &dcs); err != nil {
+		return nil, err
+	}
+	return dcs, nil
+}
+
+// regionCoordinates resolves a region hint to coordinates.
+func regionCoordinates(dcs []Datacenter, region string) (*Coordinates, error) {
+	for _, dc := range dcs {
+		if strings.EqualFold(dc.Code, region) {
+			return &Coordinates{Latitude: dc.Coordinates.Latitude, Longtitude: dc.Coordinates.Longtitude}, nil
+		}
+	}
+
+	// Average the group's datacenters as unit vectors, so that groups
+	// spanning the antimeridian are centred correctly.
+	var x, y, z float64
+	var n int
+	for _, dc := range dcs {
+		if !strings.EqualFold(dc.Group, region) {
+			continue
+		}
+		lat, lon := radians(dc.Coordinates.Latitude), radians(dc.Coordinates.Longtitude)
+		x += math.Cos(lat) * math.Cos(lon)
+		y += math.Cos(lat) * math.Sin(lon)
+		z += math.Sin(lat)
+		n++
+	}
+	if n == 0 {
+		return nil, fmt.Errorf("unknown region %q: not a datacenter code or group", region)
+	}
+	return &Coordinates{
+		Latitude:   degrees(math.Atan2(z, math.Hypot(x, y))),
+		Longtitude: degrees(math.Atan2(y, x)),
+	}, nil
+}
+
+// greatCircleDistance returns the distance between a and b in kilometres,
+// using the haversine formula.
+func greatCircleDistance(a, b *Coordinates) float64 {
+	lat1, lat2 := radians(a.Latitude), radians(b.Latitude)
+	dLat := lat2 - lat1
+	dLon := radians(b.Longtitude - a.Longtitude)
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+func radians(deg float64) float64 { return deg * math.Pi / 180 }
+func degrees(rad float64) float64 { return rad * 180 / math.Pi }
@@ -0,0 +1,117 @@
+package fastly
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestClient_RecommendShield(t *testing.T) {
+	t.Parallel()
+
+	var err error
+	var sc *ShieldCandidate
+	record(t, "datacenters/list", func(c *Client) {
+		sc, err = c.RecommendShield(&ShieldQuery{
+			Origin: &Coordinates{Latitude: 52.37, Longtitude: 4.89},
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sc.Datacenter.Code != "AMS" || sc.Datacenter.Shield != "amsterdam-nl" {
+		t.Errorf("bad shield: %#v", sc.Datacenter)
+	}
+	if sc.Distance > 50 {
+		t.Errorf("bad distance: %f", sc.Distance)
+	}
+}
+
+func TestRankShields(t *testing.T) {
+	t.Parallel()
+
+	dcs := []Datacenter{
+		{Code: "AMS", Group: "Europe", Shield: "amsterdam-nl", Coordinates: Coordinates{Latitude: 52.308613, Longtitude: 4.763889}},
+		{Code: "LHR", Group: "Europe", Shield: "london-uk", Coordinates: Coordinates{Latitude: 51.4775, Longtitude: -0.461389}},
+		{Code: "FRA", Group: "Europe", Coordinates: Coordinates{Latitude: 50.033333, Longtitude: 8.570556}},
+		{Code: "IAD", Group: "United States", Shield: "iad-va-us", Coordinates: Coordinates{Latitude: 38.944533, Longtitude: -77.455811}},
+	}
+
+	// Frankfurt has no shield, so the nearest shield is Amsterdam.
+	cs, err := RankShields(dcs, &ShieldQuery{Region: "fra"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cs) != 3 || cs[0].Datacenter.Code != "AMS" || cs[1].Datacenter.Code != "LHR" || cs[2].Datacenter.Code != "IAD" {
+		t.Errorf("bad ranking: %v", cs)
+	}
+
+	cs, err = RankShields(dcs, &ShieldQuery{Region: "United States", Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cs) != 1 || cs[0].Datacenter.Code != "IAD" || cs[0].Distance > 1 {
+		t.Errorf("bad ranking: %v", cs)
+	}
+
+	if _, err = RankShields(dcs, &ShieldQuery{Region: "Mars"}); err == nil {
+		t.Error("expected an error for an unknown region")
+	}
+
+	if _, err = RankShields(dcs, &ShieldQuery{}); err != ErrMissingOriginRegion {
+		t.Errorf("bad error: %s", err)
+	}
+
+	if _, err = RankShields(dcs, nil); err != ErrMissingOriginRegion {
+		t.Errorf("bad error: %s", err)
+	}
+}
+
+func TestClient_RecommendShield_validation(t *testing.T) {
+	t.Parallel()
+
+	if _, err := testClient.RecommendShield(nil); err != ErrMissingOriginRegion {
+		t.Errorf("bad error: %s", err)
+	}
+
+	if _, err := testClient.RecommendShield(&ShieldQuery{Limit: 1}); err != ErrMissingOriginRegion {
+		t.Errorf("bad error: %s", err)
+	}
+}
+
+func TestGreatCircleDistance(t *testing.T) {
+	t.Parallel()
+
+	// London to Paris is about 344km.
+	d := greatCircleDistance(
+		&Coordinates{Latitude: 51.5074, Longtitude: -0.1278},
+		&Coordinates{Latitude: 48.8566, Longtitude: 2.3522},
+	)
+	if math.Abs(d-343.5) > 2 {
+		t.Errorf("bad distance: %f", d)
+	}
+}
+
+func TestWriteReadDatacenters(t *testing.T) {
+	t.Parallel()
+
+	dcs := []Datacenter{{
+		Code:        "AMS",
+		Name:        "Amsterdam",
+		Group:       "Europe",
+		Shield:      "amsterdam-nl",
+		Coordinates: Coordinates{Latitude: 52.308613, Longtitude: 4.763889},
+	}}
+
+	var buf bytes.Buffer
+	if err := WriteDatacenters(&buf, dcs); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadDatacenters(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0] != dcs[0] {
+		t.Errorf("bad datacenters: %#v", got)
+	}
+}
@@ -0,0 +1,506 @@
+package vcl
+
+// Node is a node of the syntax tree.
+type Node interface {
+	// Pos returns the position of the first token of the node.
+	Pos() Pos
+}
+
+// Decl is a top-level declaration.
+type Decl interface {
+	Node
+	declNode()
+}
+
+// Stmt is a statement in a subroutine.
+type Stmt interface {
+	Node
+	stmtNode()
+}
+
+// Expr is an expression.
+type Expr interface {
+	Node
+	exprNode()
+}
+
+// File is a parsed VCL source.
+type File struct {
+	// Name is the name the source was parsed under.
+	Name string
+
+	Decls    []Decl
+	Comments []*Comment
+}
+
+// Subroutines returns the subroutines declared in the file, in order.
+func (f *File) Subroutines() []*SubDecl {
+	var subs []*SubDecl
+	for _, d := range f.Decls {
+		if s, ok := d.(*SubDecl); ok {
+			subs = append(subs, s)
+		}
+	}
+	return subs
+}
+
+// Declarations.
+type (
+	// IncludeDecl is an include statement, e.g. `include "main";`. It is
+	// also a statement, as includes may appear inside subroutines.
+	IncludeDecl struct {
+		At   Pos
+		Path string
+	}
+
+	// ImportDecl is an import statement, e.g. `import boltsort;`.
+	ImportDecl struct {
+		At   Pos
+		Name string
+	}
+
+	// SubDecl is a subroutine, e.g. `sub vcl_recv { ... }`.
+	SubDecl struct {
+		At      Pos
+		Name    string
+		NamePos Pos
+
+		// ReturnType is the return type of a function, e.g. "BOOL", or
+		// empty for a subroutine.
+		ReturnType string
+
+		Body *Block
+	}
+
+	// ACLDecl is an ACL, e.g. `acl internal { "10.0.0.0"/8; }`.
+	ACLDecl struct {
+		At      Pos
+		Name    string
+		Entries []*ACLEntry
+	}
+
+	// BackendDecl is a backend, e.g. `backend F_origin { .host = "..."; }`.
+	BackendDecl struct {
+		At         Pos
+		Name       string
+		Properties []*Property
+	}
+
+	// DirectorDecl is a director, e.g.
+	// `director pool random { { .backend = F_a; .weight = 1; } }`.
+	DirectorDecl struct {
+		At         Pos
+		Name       string
+		Type       string
+		Properties []*Property
+
+		// Backends are the members of the director.
+		Backends []*Object
+	}
+
+	// TableDecl is a table, e.g. `table redirects STRING { "/a": "/b", }`.
+	TableDecl struct {
+		At   Pos
+		Name string
+
+		// Type is the type of the values, "STRING" when not given.
+		Type string
+
+		Entries []*TableEntry
+	}
+
+	// ObjectDecl is a penaltybox or ratecounter declaration.
+	ObjectDecl struct {
+		At   Pos
+		Kind string
+		Name string
+	}
+)
+
+// ACLEntry is an entry of an ACL.
+type ACLEntry struct {
+	At      Pos
+	Negated bool
+	IP      string
+
+	// Subnet is the prefix length, or -1 when not given.
+	Subnet int
+}
+
+// TableEntry is an entry of a table.
+type TableEntry struct {
+	At    Pos
+	Key   string
+	Value Expr
+}
+
+// Property is a property of a backend or director, e.g. `.host = "..."`.
+// Either Value or Object is set.
+type Property struct {
+	At     Pos
+	Name   string
+	Value  Expr
+	Object *Object
+}
+
+// Object is a block of properties, such as a backend probe or a director
+// member.
+type Object struct {
+	At         Pos
+	Properties []*Property
+}
+
+// Property returns the property with the given name, or nil.
+func (o *Object) Property(name string) *Property {
+	for _, p := range o.Properties {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// Statements.
+type (
+	// Block is a list of statements in braces.
+	Block struct {
+		At    Pos
+		Stmts []Stmt
+		End   Pos
+	}
+
+	// SetStmt is a set statement, e.g. `set req.http.X = "1";`.
+	SetStmt struct {
+		At     Pos
+		Target *Ident
+		Op     string
+		Value  Expr
+	}
+
+	// AddStmt is an add statement, e.g. `add resp.http.Set-Cookie = "a";`.
+	AddStmt struct {
+		At     Pos
+		Target *Ident
+		Op     string
+		Value  Expr
+	}
+
+	// UnsetStmt is an unset or remove statement.
+	UnsetStmt struct {
+		At      Pos
+		Keyword string
+		Target  *Ident
+	}
+
+	// CallStmt is a call statement, e.g. `call my_sub;`.
+	CallStmt struct {
+		At      Pos
+		Name    string
+		NamePos Pos
+	}
+
+	// ReturnStmt is a return statement. Value is nil for a bare return, an
+	// *Ident for an action such as `return(pass);` or any expression in a
+	// function.
+	ReturnStmt struct {
+		At    Pos
+		Value Expr
+	}
+
+	// IfStmt is an if statement. Else is nil, an *IfStmt or a *Block.
+	IfStmt struct {
+		At   Pos
+		Cond Expr
+		Then *Block
+		Else Stmt
+	}
+
+	// DeclareStmt declares a local variable, e.g.
+	// `declare local var.n INTEGER;`.
+	DeclareStmt struct {
+		At   Pos
+		Name *Ident
+		Type string
+	}
+
+	// ErrorStmt is an error statement, e.g. `error 404 "Not found";`. Code
+	// and Msg may be nil.
+	ErrorStmt struct {
+		At   Pos
+		Code Expr
+		Msg  Expr
+	}
+
+	// RestartStmt is a restart statement.
+	RestartStmt struct {
+		At Pos
+	}
+
+	// EsiStmt is an esi statement.
+	EsiStmt struct {
+		At Pos
+	}
+
+	// SyntheticStmt is a synthetic or synthetic.base64 statement.
+	SyntheticStmt struct {
+		At     Pos
+		Base64 bool
+		Value  Expr
+	}
+
+	// LogStmt is a log statement.
+	LogStmt struct {
+		At    Pos
+		Value Expr
+	}
+
+	// GotoStmt is a goto statement.
+	GotoStmt struct {
+		At    Pos
+		Label string
+	}
+
+	// LabelStmt is a goto label, e.g. `retry:`.
+	LabelStmt struct {
+		At   Pos
+		Name string
+	}
+)
+
+// Expressions.
+type (
+	// Ident is a name, such as a variable, a backend or an action.
+	Ident struct {
+		At   Pos
+		Name string
+	}
+
+	// StringLit is a string literal.
+	StringLit struct {
+		At    Pos
+		Value string
+		Long  bool
+	}
+
+	// NumberLit is an integer, float or percentage literal.
+	NumberLit struct {
+		At   Pos
+		Text string
+	}
+
+	// RTimeLit is a relative time literal, e.g. `10s`.
+	RTimeLit struct {
+		At   Pos
+		Text string
+	}
+
+	// BinaryExpr is a binary expression, e.g. `a == b`.
+	BinaryExpr struct {
+		X     Expr
+		Op    string
+		OpPos Pos
+		Y     Expr
+	}
+
+	// UnaryExpr is a unary expression, e.g. `!a` or `-1`.
+	UnaryExpr struct {
+		At Pos
+		Op string
+		X  Expr
+	}
+
+	// CallExpr is a function call, e.g. `std.tolower(req.url)`.
+	CallExpr struct {
+		Func *Ident
+		Args []Expr
+	}
+
+	// ParenExpr is a parenthesized expression.
+	ParenExpr struct {
+		At Pos
+		X  Expr
+	}
+
+	// ConcatExpr is the implicit concatenation of adjacent expressions, e.g.
+	// `"a" req.url "b"`.
+	ConcatExpr struct {
+		Parts []Expr
+	}
+)
+
+func (d *IncludeDecl) Pos() Pos  { return d.At }
+func (d *ImportDecl) Pos() Pos   { return d.At }
+func (d *SubDecl) Pos() Pos      { return d.At }
+func (d *ACLDecl) Pos() Pos      { return d.At }
+func (d *BackendDecl) Pos() Pos  { return d.At }
+func (d *DirectorDecl) Pos() Pos { return d.At }
+func (d *TableDecl) Pos() Pos    { return d.At }
+func (d *ObjectDecl) Pos() Pos   { return d.At }
+func (e *ACLEntry) Pos() Pos     { return e.At }
+func (e *TableEntry) Pos() Pos   { return e.At }
+func (p *Property) Pos() Pos     { return p.At }
+func (o *Object) Pos() Pos       { return o.At }
+
+func (s *Block) Pos() Pos         { return s.At }
+func (s *SetStmt) Pos() Pos       { return s.At }
+func (s *AddStmt) Pos() Pos       { return s.At }
+func (s *UnsetStmt) Pos() Pos     { return s.At }
+func (s *CallStmt) Pos() Pos      { return s.At }
+func (s *ReturnStmt) Pos() Pos    { return s.At }
+func (s *IfStmt) Pos() Pos        { return s.At }
+func (s *DeclareStmt) Pos() Pos   { return s.At }
+func (s *ErrorStmt) Pos() Pos     { return s.At }
+func (s *RestartStmt) Pos() Pos   { return s.At }
+func (s *EsiStmt) Pos() Pos       { return s.At }
+func (s *SyntheticStmt) Pos() Pos { return s.At }
+func (s *LogStmt) Pos() Pos       { return s.At }
+func (s *GotoStmt) Pos() Pos      { return s.At }
+func (s *LabelStmt) Pos() Pos     { return s.At }
+
+func (e *Ident) Pos() Pos      { return e.At }
+func (e *StringLit) Pos() Pos  { return e.At }
+func (e *NumberLit) Pos() Pos  { return e.At }
+func (e *RTimeLit) Pos() Pos   { return e.At }
+func (e *BinaryExpr) Pos() Pos { return e.X.Pos() }
+func (e *UnaryExpr) Pos() Pos  { return e.At }
+func (e *CallExpr) Pos() Pos   { return e.Func.At }
+func (e *ParenExpr) Pos() Pos  { return e.At }
+func (e *ConcatExpr) Pos() Pos { return e.Parts[0].Pos() }
+
+func (*IncludeDecl) declNode()  {}
+func (*ImportDecl) declNode()   {}
+func (*SubDecl) declNode()      {}
+func (*ACLDecl) declNode()      {}
+func (*BackendDecl) declNode()  {}
+func (*DirectorDecl) declNode() {}
+func (*TableDecl) declNode()    {}
+func (*ObjectDecl) declNode()   {}
+
+func (*IncludeDecl) stmtNode()   {}
+func (*Block) stmtNode()         {}
+func (*SetStmt) stmtNode()       {}
+func (*AddStmt) stmtNode()       {}
+func (*UnsetStmt) stmtNode()     {}
+func (*CallStmt) stmtNode()      {}
+func (*ReturnStmt) stmtNode()    {}
+func (*IfStmt) stmtNode()        {}
+func (*DeclareStmt) stmtNode()   {}
+func (*ErrorStmt) stmtNode()     {}
+func (*RestartStmt) stmtNode()   {}
+func (*EsiStmt) stmtNode()       {}
+func (*SyntheticStmt) stmtNode() {}
+func (*LogStmt) stmtNode()       {}
+func (*GotoStmt) stmtNode()      {}
+func (*LabelStmt) stmtNode()     {}
+
+func (*Ident) exprNode()      {}
+func (*StringLit) exprNode()  {}
+func (*NumberLit) exprNode()  {}
+func (*RTimeLit) exprNode()   {}
+func (*BinaryExpr) exprNode() {}
+func (*UnaryExpr) exprNode()  {}
+func (*CallExpr) exprNode()   {}
+func (*ParenExpr) exprNode()  {}
+func (*ConcatExpr) exprNode() {}
+
+// Inspect traverses the tree rooted at node in depth-first order, calling f
+// for each node. If f returns false, the children of the node are skipped.
+func Inspect(node Node, f func(Node) bool) {
+	if node == nil || !f(node) {
+		return
+	}
+
+	switch n := node.(type) {
+	case *File:
+		for _, d := range n.Decls {
+			Inspect(d, f)
+		}
+	case *SubDecl:
+		Inspect(n.Body, f)
+	case *ACLDecl:
+		for _, e := range n.Entries {
+			Inspect(e, f)
+		}
+	case *BackendDecl:
+		for _, p := range n.Properties {
+			Inspect(p, f)
+		}
+	case *DirectorDecl:
+		for _, p := range n.Properties {
+			Inspect(p, f)
+		}
+		for _, o := range n.Backends {
+			Inspect(o, f)
+		}
+	case *TableDecl:
+		for _, e := range n.Entries {
+			Inspect(e, f)
+		}
+	case *TableEntry:
+		inspectExpr(n.Value, f)
+	case *Property:
+		inspectExpr(n.Value, f)
+		if n.Object != nil {
+			Inspect(n.Object, f)
+		}
+	case *Object:
+		for _, p := range n.Properties {
+			Inspect(p, f)
+		}
+	case *Block:
+		for _, s := range n.Stmts {
+			Inspect(s, f)
+		}
+	case *SetStmt:
+		Inspect(n.Target, f)
+		inspectExpr(n.Value, f)
+	case *AddStmt:
+		Inspect(n.Target, f)
+		inspectExpr(n.Value, f)
+	case *UnsetStmt:
+		Inspect(n.Target, f)
+	case *ReturnStmt:
+		inspectExpr(n.Value, f)
+	case *IfStmt:
+		inspectExpr(n.Cond, f)
+		Inspect(n.Then, f)
+		if n.Else != nil {
+			Inspect(n.Else, f)
+		}
+	case *DeclareStmt:
+		Inspect(n.Name, f)
+	case *ErrorStmt:
+		inspectExpr(n.Code, f)
+		inspectExpr(n.Msg, f)
+	case *SyntheticStmt:
+		inspectExpr(n.Value, f)
+	case *LogStmt:
+		inspectExpr(n.Value, f)
+	case *BinaryExpr:
+		inspectExpr(n.X, f)
+		inspectExpr(n.Y, f)
+	case *UnaryExpr:
+		inspectExpr(n.X, f)
+	case *CallExpr:
+		Inspect(n.Func, f)
+		for _, a := range n.Args {
+			inspectExpr(a, f)
+		}
+	case *ParenExpr:
+		inspectExpr(n.X, f)
+	case *ConcatExpr:
+		for _, p := range n.Parts {
+			inspectExpr(p, f)
+		}
+	}
+}
+
+// inspectExpr is Inspect for an expression which may be nil.
+func inspectExpr(e Expr, f func(Node) bool) {
+	if e != nil {
+		Inspect(e, f)
+	}
+}
+
+// Pos returns the position of the start of the file.
+func (f *File) Pos() Pos { return Pos{Line: 1, Column: 1} }
@@ -0,0 +1,281 @@
+package vcl
+
+import (
+	"fmt"
+	"strings"
+)
+
+// checker type checks expressions against the variable and function
+// catalogues.
+type checker struct {
+	scope Scope
+	errs  ErrorList
+}
+
+func (c *checker) errorf(pos Pos, format string, args ...interface{}) {
+	c.errs = append(c.errs, &Error{Pos: pos, Msg: fmt.Sprintf(format, args...)})
+}
+
+// CheckExpr type checks an expression evaluated in the subroutines of scope.
+// It reports unknown variables and functions, variables not available in
+// every subroutine of the scope and operands of the wrong type. The type of the
+// expression is returned, or "" when it cannot be determined.
+func CheckExpr(x Expr, scope Scope) (Type, ErrorList) {
+	c := &checker{scope: scope}
+	t := c.expr(x)
+	return t, c.errs
+}
+
+// CheckCondition parses and checks a condition, such as the statement of a
+// Fastly condition, evaluated in the subroutines of scope. A condition must
+// be a boolean or a string, which is true when it is set. The error is an
+// ErrorList, or nil when the condition is valid.
+func CheckCondition(src string, scope Scope) error {
+	x, err := ParseExpr(src)
+	if err != nil {
+		return err
+	}
+
+	t, errs := CheckExpr(x, scope)
+	if t != "" && !testable(t) {
+		errs = append(errs, &Error{Pos: x.Pos(), Msg: fmt.Sprintf("condition must be BOOL or STRING, not %s", t)})
+	}
+	return errs.Err()
+}
+
+// testable reports whether a value of type t can be used as a condition.
+func testable(t Type) bool {
+	return t == TypeBool || t == TypeString
+}
+
+func numeric(t Type) bool {
+	return t == TypeInteger || t == TypeFloat
+}
+
+// expr returns the type of x, or "" when it is unknown because of an error.
+func (c *checker) expr(x Expr) Type {
+	switch x := x.(type) {
+	case *StringLit:
+		return TypeString
+	case *NumberLit:
+		if strings.ContainsAny(x.Text, ".%") {
+			return TypeFloat
+		}
+		return TypeInteger
+	case *RTimeLit:
+		return TypeRTime
+	case *Ident:
+		return c.variable(x)
+	case *ParenExpr:
+		return c.expr(x.X)
+	case *ConcatExpr:
+		for _, p := range x.Parts {
+			c.expr(p)
+		}
+		return TypeString
+	case *CallExpr:
+		return c.call(x)
+	case *UnaryExpr:
+		t := c.expr(x.X)
+		if t == "" {
+			return ""
+		}
+		if x.Op == "!" {
+			if !testable(t) {
+				c.errorf(x.At, "operator ! not defined on %s", t)
+			}
+			return TypeBool
+		}
+		if !numeric(t) && t != TypeRTime {
+			c.errorf(x.At, "operator %s not defined on %s", x.Op, t)
+			return ""
+		}
+		return t
+	case *BinaryExpr:
+		return c.binary(x)
+	}
+	return ""
+}
+
+// variable returns the type of a variable, reporting unknown ones and ones
+// unavailable in the scope.
+func (c *checker) variable(id *Ident) Type {
+	if id.Name == "true" || id.Name == "false" {
+		return TypeBool
+	}
+	v := LookupVariable(id.Name)
+	if v == nil {
+		c.errorf(id.At, "unknown variable %s", id.Name)
+		return ""
+	}
+	if missing := c.scope &^ v.Scopes; missing != 0 {
+		c.errorf(id.At, "variable %s is not available in %s", id.Name, missing)
+	}
+	return v.Type
+}
+
+// call checks the arguments of a function call and returns its type.
+func (c *checker) call(x *CallExpr) Type {
+	f, ok := Functions[x.Func.Name]
+	if !ok {
+		c.errorf(x.Func.At, "unknown function %s", x.Func.Name)
+		for _, a := range x.Args {
+			c.expr(a)
+		}
+		return ""
+	}
+
+	if n := len(x.Args); n > len(f.Params) || n < len(f.Params)-f.Optional {
+		c.errorf(x.Func.At, "%s takes %s, got %d", f.Name, arity(f), n)
+	}
+	for n, a := range x.Args {
+		if n >= len(f.Params) {
+			c.expr(a)
+			continue
+		}
+		want := f.Params[n]
+		if want == TypeID {
+			if _, ok := a.(*Ident); !ok {
+				c.errorf(a.Pos(), "argument %d of %s must be a name", n+1, f.Name)
+			}
+			continue
+		}
+		got := c.expr(a)
+		if got != "" && !assignable(want, got, a) {
+			c.errorf(a.Pos(), "argument %d of %s must be %s, not %s", n+1, f.Name, want, got)
+		}
+	}
+	return f.Return
+}
+
+// arity describes the number of parameters of f.
+func arity(f *Function) string {
+	n := len(f.Params)
+	switch {
+	case f.Optional > 0:
+		return fmt.Sprintf("%d to %d arguments", n-f.Optional, n)
+	case n == 1:
+		return "1 argument"
+	}
+	return fmt.Sprintf("%d arguments", n)
+}
+
+// assignable reports whether a value x of type got can be used where want is
+// expected.
+func assignable(want, got Type, x Expr) bool {
+	switch {
+	case want == got, want == TypeString:
+		return true
+	case want == TypeFloat:
+		return got == TypeInteger
+	case want == TypeIP:
+		_, lit := x.(*StringLit)
+		return lit
+	}
+	return false
+}
+
+// binary checks the operands of a binary expression and returns its type.
+func (c *checker) binary(x *BinaryExpr) Type {
+	// The right operand of ~ may name an ACL rather than a variable.
+	if x.Op == "~" || x.Op == "!~" {
+		l := c.expr(x.X)
+		if id, ok := x.Y.(*Ident); ok && LookupVariable(id.Name) == nil {
+			if l != "" && l != TypeIP {
+				c.errorf(x.OpPos, "ACL match requires an IP, not %s", l)
+			}
+			return TypeBool
+		}
+		r := c.expr(x.Y)
+		if l == "" || r == "" {
+			return TypeBool
+		}
+		if l == TypeIP {
+			c.errorf(x.OpPos, "IP must be matched against an ACL")
+		} else if r != TypeString {
+			c.errorf(x.Y.Pos(), "regular expression must be a STRING, not %s", r)
+		}
+		return TypeBool
+	}
+
+	l := c.expr(x.X)
+
+	// A backend may be compared with the name of a backend or director.
+	if l == TypeBackend && (x.Op == "==" || x.Op == "!=") {
+		if id, ok := x.Y.(*Ident); ok && LookupVariable(id.Name) == nil {
+			return TypeBool
+		}
+	}
+
+	r := c.expr(x.Y)
+
+	switch x.Op {
+	case "&&", "||":
+		if l != "" && !testable(l) {
+			c.errorf(x.X.Pos(), "operator %s not defined on %s", x.Op, l)
+		}
+		if r != "" && !testable(r) {
+			c.errorf(x.Y.Pos(), "operator %s not defined on %s", x.Op, r)
+		}
+		return TypeBool
+
+	case "==", "!=":
+		if l != "" && r != "" && !comparableTypes(l, r, x.X, x.Y) {
+			c.errorf(x.OpPos, "mismatched types %s and %s", l, r)
+		}
+		return TypeBool
+
+	case "<", ">", "<=", ">=":
+		if l == "" || r == "" {
+			return TypeBool
+		}
+		switch {
+		case !numeric(l) && l != TypeRTime && l != TypeTime:
+			c.errorf(x.OpPos, "operator %s not defined on %s", x.Op, l)
+		case !comparableTypes(l, r, x.X, x.Y):
+			c.errorf(x.OpPos, "mismatched types %s and %s", l, r)
+		}
+		return TypeBool
+
+	case "+", "-":
+		if l == "" || r == "" {
+			return ""
+		}
+		switch {
+		case l == TypeString || r == TypeString:
+			if x.Op == "+" {
+				return TypeString
+			}
+		case numeric(l) && numeric(r):
+			if l == TypeFloat || r == TypeFloat {
+				return TypeFloat
+			}
+			return TypeInteger
+		case l == TypeTime && r == TypeRTime:
+			return TypeTime
+		case l == TypeRTime && r == TypeRTime:
+			return TypeRTime
+		}
+		c.errorf(x.OpPos, "operator %s not defined on %s and %s", x.Op, l, r)
+		return ""
+	}
+	return ""
+}
+
+// comparableTypes reports whether values of types l and r may be compared. A
+// string literal may be compared with an IP.
+func comparableTypes(l, r Type, lx, rx Expr) bool {
+	switch {
+	case l == r:
+		return true
+	case numeric(l) && numeric(r):
+		return true
+	case l == TypeIP && r == TypeString:
+		_, lit := rx.(*StringLit)
+		return lit
+	case l == TypeString && r == TypeIP:
+		_, lit := lx.(*StringLit)
+		return lit
+	}
+	return false
+}
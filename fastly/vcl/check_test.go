@@ -0,0 +1,76 @@
+package vcl
+
+import (
+	"testing"
+)
+
+func TestCheckCondition(t *testing.T) {
+	t.Parallel()
+
+	valid := []struct {
+		src   string
+		scope Scope
+	}{
+		{`req.url ~ "^/api/"`, ScopeRecv},
+		{`req.http.Cookie`, ScopeRecv},
+		{`!req.http.Cookie && req.method == "GET"`, ScopeAll},
+		{`client.ip ~ internal`, ScopeRecv},
+		{`client.ip == "192.0.2.1"`, ScopeRecv},
+		{`req.backend == F_origin`, ScopeRecv},
+		{`beresp.status >= 500 && beresp.ttl < 10s`, ScopeFetch},
+		{`resp.status == 404 || resp.http.X-Cache ~ "MISS"`, ScopeDeliver | ScopeLog},
+		{`std.strlen(req.url) > 100`, ScopeRecv},
+		{`table.contains(redirects, req.url.path)`, ScopeRecv},
+		{`client.geo.latitude > 50`, ScopeRecv},
+		{`backend.F_origin.healthy`, ScopeRecv},
+		{`bereq.url ~ "\.css$"`, ScopeMiss | ScopePass},
+	}
+	for _, c := range valid {
+		if err := CheckCondition(c.src, c.scope); err != nil {
+			t.Errorf("%q: unexpected error %s", c.src, err)
+		}
+	}
+
+	invalid := []struct {
+		src   string
+		scope Scope
+		want  string
+	}{
+		{`req.url ==`, ScopeRecv, `1:11: unexpected end of file, expected expression`},
+		{`req.nope == "a"`, ScopeRecv, `1:1: unknown variable req.nope`},
+		{`beresp.status == 200`, ScopeRecv, `1:1: variable beresp.status is not available in recv`},
+		{`resp.status == 200`, ScopeDeliver | ScopeFetch, `1:1: variable resp.status is not available in fetch`},
+		{`resp.status == "200"`, ScopeDeliver, `1:13: mismatched types INTEGER and STRING`},
+		{`req.url > "a"`, ScopeRecv, `1:9: operator > not defined on STRING`},
+		{`beresp.ttl > 10`, ScopeFetch, `1:12: mismatched types RTIME and INTEGER`},
+		{`req.url ~ internal`, ScopeRecv, `1:9: ACL match requires an IP, not STRING`},
+		{`client.ip ~ "^10\."`, ScopeRecv, `1:11: IP must be matched against an ACL`},
+		{`req.restarts`, ScopeRecv, `1:1: condition must be BOOL or STRING, not INTEGER`},
+		{`!req.restarts`, ScopeRecv, `1:1: operator ! not defined on INTEGER`},
+		{`std.nope(req.url)`, ScopeRecv, `1:1: unknown function std.nope`},
+		{`std.prefixof(req.url)`, ScopeRecv, `1:1: std.prefixof takes 2 arguments, got 1`},
+		{`randombool(req.url, 10)`, ScopeRecv, `1:12: argument 1 of randombool must be INTEGER, not STRING`},
+		{`table.contains("t", req.url)`, ScopeRecv, `1:16: argument 1 of table.contains must be a name`},
+	}
+	for _, c := range invalid {
+		err := CheckCondition(c.src, c.scope)
+		if err == nil {
+			t.Errorf("%q: expected an error", c.src)
+			continue
+		}
+		if got := err.Error(); got != c.want {
+			t.Errorf("%q: got %q, want %q", c.src, got, c.want)
+		}
+	}
+}
+
+func TestScope(t *testing.T) {
+	t.Parallel()
+
+	if s := (ScopeMiss | ScopePass).String(); s != "miss, pass" {
+		t.Errorf("bad scope %q", s)
+	}
+	if ScopeOf("vcl_fetch") != ScopeFetch || ScopeOf("custom") != 0 {
+		t.Error("bad ScopeOf")
+	}
+}
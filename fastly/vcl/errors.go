@@ -0,0 +1,55 @@
+package vcl
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Error is an error at a position of a VCL source.
+type Error struct {
+	// File is the name of the source, if known.
+	File string
+
+	Pos Pos
+	Msg string
+}
+
+// Error fulfills the error interface.
+func (e *Error) Error() string {
+	if e.File != "" {
+		return fmt.Sprintf("%s:%s: %s", e.File, e.Pos, e.Msg)
+	}
+	return fmt.Sprintf("%s: %s", e.Pos, e.Msg)
+}
+
+// ErrorList is a list of errors.
+type ErrorList []*Error
+
+// Error fulfills the error interface.
+func (l ErrorList) Error() string {
+	msgs := make([]string, len(l))
+	for n, e := range l {
+		msgs[n] = e.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Sort sorts the list by file, then position.
+func (l ErrorList) Sort() {
+	sort.SliceStable(l, func(a, b int) bool {
+		if l[a].File != l[b].File {
+			return l[a].File < l[b].File
+		}
+		return l[a].Pos.Offset < l[b].Pos.Offset
+	})
+}
+
+// Err returns the sorted list as an error, or nil when it is empty.
+func (l ErrorList) Err() error {
+	if len(l) == 0 {
+		return nil
+	}
+	l.Sort()
+	return l
+}
@@ -0,0 +1,493 @@
+package vcl
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Env holds the values of variables for Eval, by name. Values are strings,
+// bools, int64s, float64s, time.Durations (RTIME), time.Times, net.IPs, and
+// []*net.IPNet for ACLs. A variable missing from the environment is not set,
+// and evaluates to nil.
+//
+// Header variables are looked up case insensitively, see SetHeaders.
+type Env map[string]interface{}
+
+// SetHeaders sets the header variables with prefix, such as "req.http.", to
+// the values of h. Multiple values of a header are joined by commas.
+func (e Env) SetHeaders(prefix string, h http.Header) {
+	for name, values := range h {
+		e[prefix+strings.ToLower(name)] = strings.Join(values, ", ")
+	}
+}
+
+// Lookup returns the value of a variable.
+func (e Env) Lookup(name string) (interface{}, bool) {
+	if v, ok := e[name]; ok {
+		return v, true
+	}
+	for prefix := range headerPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			v, ok := e[prefix+strings.ToLower(name[len(prefix):])]
+			return v, ok
+		}
+	}
+	return nil, false
+}
+
+// evaluator evaluates expressions in an environment.
+type evaluator struct {
+	env Env
+}
+
+// evalError is raised with panic to abort an evaluation.
+type evalError struct {
+	err *Error
+}
+
+func (ev *evaluator) errorf(pos Pos, format string, args ...interface{}) {
+	panic(evalError{&Error{Pos: pos, Msg: fmt.Sprintf(format, args...)}})
+}
+
+// Eval evaluates an expression with the variables of env. Regular expressions
+// are Go regular expressions, which accept most of the PCRE syntax used by
+// Fastly. Only the functions of the std namespace working on strings, regsub
+// and regsuball are supported. The error is an *Error.
+func Eval(x Expr, env Env) (v interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			e, ok := r.(evalError)
+			if !ok {
+				panic(r)
+			}
+			err = e.err
+		}
+	}()
+	ev := &evaluator{env: env}
+	return ev.expr(x), nil
+}
+
+// EvalCondition parses and evaluates a condition, such as the statement of a
+// Fastly condition. A string condition is true when it is set.
+func EvalCondition(src string, env Env) (bool, error) {
+	x, err := ParseExpr(src)
+	if err != nil {
+		return false, err
+	}
+	v, err := Eval(x, env)
+	if err != nil {
+		return false, err
+	}
+	return truth(v), nil
+}
+
+// truth reports whether v is true as a condition.
+func truth(v interface{}) bool {
+	switch v := v.(type) {
+	case bool:
+		return v
+	case string:
+		return true
+	}
+	return false
+}
+
+func (ev *evaluator) expr(x Expr) interface{} {
+	switch x := x.(type) {
+	case *StringLit:
+		return x.Value
+	case *NumberLit:
+		return ev.number(x)
+	case *RTimeLit:
+		d, err := ParseRTime(x.Text)
+		if err != nil {
+			ev.errorf(x.At, "%s", err)
+		}
+		return d
+	case *Ident:
+		if x.Name == "true" || x.Name == "false" {
+			return x.Name == "true"
+		}
+		v, _ := ev.env.Lookup(x.Name)
+		return v
+	case *ParenExpr:
+		return ev.expr(x.X)
+	case *ConcatExpr:
+		var b strings.Builder
+		for _, p := range x.Parts {
+			b.WriteString(ToString(ev.expr(p)))
+		}
+		return b.String()
+	case *CallExpr:
+		return ev.call(x)
+	case *UnaryExpr:
+		v := ev.expr(x.X)
+		switch x.Op {
+		case "!":
+			return !truth(v)
+		case "-":
+			switch v := v.(type) {
+			case int64:
+				return -v
+			case float64:
+				return -v
+			case time.Duration:
+				return -v
+			}
+		case "+":
+			return v
+		}
+		ev.errorf(x.At, "operator %s not defined on %s", x.Op, typeOf(v))
+	case *BinaryExpr:
+		return ev.binary(x)
+	}
+	ev.errorf(x.Pos(), "cannot evaluate %T", x)
+	return nil
+}
+
+func (ev *evaluator) number(x *NumberLit) interface{} {
+	text := strings.TrimSuffix(x.Text, "%")
+	if !strings.Contains(x.Text, ".") && text == x.Text {
+		if n, err := strconv.ParseInt(text, 0, 64); err == nil {
+			return n
+		}
+	}
+	f, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		ev.errorf(x.At, "invalid number %s", x.Text)
+	}
+	return f
+}
+
+// ParseRTime parses a relative time literal, such as "10s" or "1.5h".
+func ParseRTime(text string) (time.Duration, error) {
+	units := map[string]time.Duration{
+		"ms": time.Millisecond,
+		"s":  time.Second,
+		"m":  time.Minute,
+		"h":  time.Hour,
+		"d":  24 * time.Hour,
+		"y":  365 * 24 * time.Hour,
+	}
+	for _, u := range rtimeUnits {
+		if strings.HasSuffix(text, u) {
+			f, err := strconv.ParseFloat(strings.TrimSuffix(text, u), 64)
+			if err != nil {
+				break
+			}
+			return time.Duration(math.Round(f * float64(units[u]))), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid relative time %s", text)
+}
+
+func (ev *evaluator) binary(x *BinaryExpr) interface{} {
+	switch x.Op {
+	case "&&":
+		return truth(ev.expr(x.X)) && truth(ev.expr(x.Y))
+	case "||":
+		return truth(ev.expr(x.X)) || truth(ev.expr(x.Y))
+	case "~", "!~":
+		return ev.match(x) == (x.Op == "~")
+	}
+
+	l := ev.expr(x.X)
+	var r interface{}
+	if id, ok := x.Y.(*Ident); ok && (x.Op == "==" || x.Op == "!=") && LookupVariable(id.Name) == nil {
+		// A backend compared with the name of a backend or director.
+		if _, set := ev.env.Lookup(id.Name); !set && id.Name != "true" && id.Name != "false" {
+			r = id.Name
+		} else {
+			r = ev.expr(x.Y)
+		}
+	} else {
+		r = ev.expr(x.Y)
+	}
+
+	switch x.Op {
+	case "==", "!=":
+		return equal(l, r) == (x.Op == "==")
+	case "<", ">", "<=", ">=":
+		c, ok := compare(l, r)
+		if !ok {
+			if l == nil || r == nil {
+				return false
+			}
+			ev.errorf(x.OpPos, "operator %s not defined on %s and %s", x.Op, typeOf(l), typeOf(r))
+		}
+		switch x.Op {
+		case "<":
+			return c < 0
+		case ">":
+			return c > 0
+		case "<=":
+			return c <= 0
+		}
+		return c >= 0
+	case "+", "-":
+		return ev.arith(x, l, r)
+	}
+	ev.errorf(x.OpPos, "unknown operator %s", x.Op)
+	return nil
+}
+
+// match evaluates a regular expression or ACL match.
+func (ev *evaluator) match(x *BinaryExpr) bool {
+	l := ev.expr(x.X)
+	if id, ok := x.Y.(*Ident); ok && LookupVariable(id.Name) == nil {
+		acl, ok := ev.env[id.Name].([]*net.IPNet)
+		if !ok {
+			ev.errorf(id.At, "unknown ACL %s", id.Name)
+		}
+		ip, _ := l.(net.IP)
+		for _, n := range acl {
+			if ip != nil && n.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}
+
+	r := ev.expr(x.Y)
+	pattern, ok := r.(string)
+	if !ok {
+		ev.errorf(x.Y.Pos(), "regular expression must be a STRING, not %s", typeOf(r))
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		ev.errorf(x.Y.Pos(), "invalid regular expression: %s", err)
+	}
+	if l == nil {
+		return false
+	}
+	return re.MatchString(ToString(l))
+}
+
+func (ev *evaluator) arith(x *BinaryExpr, l, r interface{}) interface{} {
+	_, ls := l.(string)
+	_, rs := r.(string)
+	if x.Op == "+" && (ls || rs) {
+		return ToString(l) + ToString(r)
+	}
+
+	sign := int64(1)
+	if x.Op == "-" {
+		sign = -1
+	}
+	switch l := l.(type) {
+	case int64:
+		switch r := r.(type) {
+		case int64:
+			return l + sign*r
+		case float64:
+			return float64(l) + float64(sign)*r
+		}
+	case float64:
+		switch r := r.(type) {
+		case int64:
+			return l + float64(sign*r)
+		case float64:
+			return l + float64(sign)*r
+		}
+	case time.Duration:
+		if r, ok := r.(time.Duration); ok {
+			return l + time.Duration(sign)*r
+		}
+	case time.Time:
+		if r, ok := r.(time.Duration); ok {
+			return l.Add(time.Duration(sign) * r)
+		}
+	}
+	ev.errorf(x.OpPos, "operator %s not defined on %s and %s", x.Op, typeOf(l), typeOf(r))
+	return nil
+}
+
+func (ev *evaluator) call(x *CallExpr) interface{} {
+	args := make([]interface{}, len(x.Args))
+	for n, a := range x.Args {
+		args[n] = ev.expr(a)
+	}
+	str := func(n int) string {
+		if n >= len(args) {
+			ev.errorf(x.Func.At, "%s takes more than %d arguments", x.Func.Name, len(args))
+		}
+		return ToString(args[n])
+	}
+	regsub := func(all bool) string {
+		s, err := Regsub(str(0), str(1), str(2), all)
+		if err != nil {
+			ev.errorf(x.Args[1].Pos(), "%s", err)
+		}
+		return s
+	}
+
+	switch x.Func.Name {
+	case "std.tolower":
+		return strings.ToLower(str(0))
+	case "std.toupper":
+		return strings.ToUpper(str(0))
+	case "std.strlen":
+		return int64(len(str(0)))
+	case "std.atoi":
+		n, _ := strconv.ParseInt(str(0), 10, 64)
+		return n
+	case "std.prefixof":
+		return strings.HasPrefix(str(0), str(1))
+	case "std.suffixof":
+		return strings.HasSuffix(str(0), str(1))
+	case "std.strstr":
+		if n := strings.Index(str(0), str(1)); n >= 0 {
+			return str(0)[n:]
+		}
+		return nil
+	case "regsub":
+		return regsub(false)
+	case "regsuball":
+		return regsub(true)
+	}
+	ev.errorf(x.Func.At, "function %s cannot be evaluated", x.Func.Name)
+	return nil
+}
+
+// backreference matches the references to groups in a substitution.
+var backreference = regexp.MustCompile(`\\([0-9])`)
+
+// Regsub replaces the first match of pattern in s, or every match when all is
+// set, with repl as the VCL regsub and regsuball functions do. The repl string
+// refers to groups as \1 to \9.
+func Regsub(s, pattern, repl string, all bool) (string, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", fmt.Errorf("invalid regular expression: %s", err)
+	}
+	repl = backreference.ReplaceAllString(repl, "$${$1}")
+	if all {
+		return re.ReplaceAllString(s, repl), nil
+	}
+	if loc := re.FindStringSubmatchIndex(s); loc != nil {
+		return s[:loc[0]] + string(re.ExpandString(nil, repl, s, loc)) + s[loc[1]:], nil
+	}
+	return s, nil
+}
+
+// equal reports whether two values are equal. A value which is not set is
+// only equal to another one which is not set.
+func equal(l, r interface{}) bool {
+	if l == nil || r == nil {
+		return l == nil && r == nil
+	}
+	if c, ok := compare(l, r); ok {
+		return c == 0
+	}
+	switch l := l.(type) {
+	case net.IP:
+		if s, ok := r.(string); ok {
+			return l.Equal(net.ParseIP(s))
+		}
+		if r, ok := r.(net.IP); ok {
+			return l.Equal(r)
+		}
+	case string:
+		if ip, ok := r.(net.IP); ok {
+			return ip.Equal(net.ParseIP(l))
+		}
+		return l == r
+	case bool:
+		return l == r
+	}
+	return false
+}
+
+// compare orders two numbers, times or relative times.
+func compare(l, r interface{}) (int, bool) {
+	sign := func(d float64) int {
+		switch {
+		case d < 0:
+			return -1
+		case d > 0:
+			return 1
+		}
+		return 0
+	}
+	switch l := l.(type) {
+	case int64:
+		switch r := r.(type) {
+		case int64:
+			return sign(float64(l - r)), true
+		case float64:
+			return sign(float64(l) - r), true
+		}
+	case float64:
+		switch r := r.(type) {
+		case int64:
+			return sign(l - float64(r)), true
+		case float64:
+			return sign(l - r), true
+		}
+	case time.Duration:
+		if r, ok := r.(time.Duration); ok {
+			return sign(float64(l - r)), true
+		}
+	case time.Time:
+		if r, ok := r.(time.Time); ok {
+			return sign(float64(l.Sub(r))), true
+		}
+	}
+	return 0, false
+}
+
+// ToString converts a value returned by Eval to a string as VCL does. A value
+// which is not set is converted to "".
+func ToString(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', 3, 64)
+	case time.Duration:
+		return strconv.FormatFloat(v.Seconds(), 'f', 3, 64)
+	case time.Time:
+		return v.UTC().Format(http.TimeFormat)
+	case net.IP:
+		return v.String()
+	}
+	return fmt.Sprint(v)
+}
+
+// typeOf returns the VCL type of a value.
+func typeOf(v interface{}) Type {
+	switch v.(type) {
+	case string:
+		return TypeString
+	case bool:
+		return TypeBool
+	case int64:
+		return TypeInteger
+	case float64:
+		return TypeFloat
+	case time.Duration:
+		return TypeRTime
+	case time.Time:
+		return TypeTime
+	case net.IP:
+		return TypeIP
+	case nil:
+		return "NOTSET"
+	}
+	return Type(fmt.Sprintf("%T", v))
+}
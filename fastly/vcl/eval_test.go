@@ -0,0 +1,77 @@
+package vcl
+
+import (
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestEvalCondition(t *testing.T) {
+	t.Parallel()
+
+	_, internal, _ := net.ParseCIDR("10.0.0.0/8")
+	env := Env{
+		"req.url":      "/api/v1/users?id=3",
+		"req.url.path": "/api/v1/users",
+		"req.method":   "GET",
+		"client.ip":    net.ParseIP("10.1.2.3"),
+		"beresp.ttl":   2 * time.Minute,
+		"resp.status":  int64(404),
+		"req.backend":  "F_origin",
+		"internal":     []*net.IPNet{internal},
+	}
+	env.SetHeaders("req.http.", http.Header{"X-Api-Key": {"abc"}, "Accept": {"text/html", "*/*"}})
+
+	cases := []struct {
+		src  string
+		want bool
+	}{
+		{`req.url ~ "^/api/"`, true},
+		{`req.url !~ "^/api/"`, false},
+		{`req.http.x-api-key`, true},
+		{`req.http.Cookie`, false},
+		{`!req.http.Cookie && req.method == "GET"`, true},
+		{`req.http.Cookie == ""`, false},
+		{`req.http.Accept == "text/html, */*"`, true},
+		{`client.ip ~ internal`, true},
+		{`client.ip == "10.1.2.3"`, true},
+		{`req.backend == F_origin`, true},
+		{`beresp.ttl > 1m && beresp.ttl <= 120s`, true},
+		{`resp.status >= 500 || resp.status == 404`, true},
+		{`std.strlen(req.url.path) == 13`, true},
+		{`std.tolower(req.http.X-API-Key) ~ "^ABC$"`, false},
+		{`regsub(req.url.path, "^/api/(v[0-9]+)/.*", "\1") == "v1"`, true},
+		{`std.prefixof(req.url, "/api") && (req.method == "POST" || req.method == "GET")`, true},
+	}
+	for _, c := range cases {
+		got, err := EvalCondition(c.src, env)
+		if err != nil {
+			t.Errorf("%q: unexpected error %s", c.src, err)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("%q: got %t, want %t", c.src, got, c.want)
+		}
+	}
+
+	errs := []struct {
+		src  string
+		want string
+	}{
+		{`client.ip ~ nope`, `1:13: unknown ACL nope`},
+		{`req.url ~ "("`, "1:11: invalid regular expression: error parsing regexp: missing closing ): `(`"},
+		{`table.contains(t, req.url)`, `1:1: function table.contains cannot be evaluated`},
+		{`req.url > 1`, `1:9: operator > not defined on STRING and INTEGER`},
+	}
+	for _, c := range errs {
+		_, err := EvalCondition(c.src, env)
+		if err == nil {
+			t.Errorf("%q: expected error", c.src)
+			continue
+		}
+		if err.Error() != c.want {
+			t.Errorf("%q: got error %q, want %q", c.src, err, c.want)
+		}
+	}
+}
@@ -0,0 +1,329 @@
+// Package vcl implements a lexer and parser for Fastly VCL, so that VCL can be
+// checked for syntax errors before it is uploaded.
+package vcl
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Pos is a position in a VCL source.
+type Pos struct {
+	// Offset is the byte offset, starting at 0.
+	Offset int
+
+	// Line is the line number, starting at 1.
+	Line int
+
+	// Column is the byte offset on the line, starting at 1.
+	Column int
+}
+
+// String returns the position as "line:column".
+func (p Pos) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// Kind is the kind of a token.
+type Kind int
+
+const (
+	// EOF is the end of the source.
+	EOF Kind = iota
+
+	// Illegal is a character which cannot start a token.
+	Illegal
+
+	// Identifier is an identifier, such as "sub" or "req.http.X-Forwarded-For".
+	Identifier
+
+	// String is a string literal, either "..." or {"..."}. Its Value is
+	// the content without delimiters.
+	String
+
+	// Number is an integer, float or percentage literal, such as "42",
+	// "1.5" or "50%".
+	Number
+
+	// RTime is a relative time literal, such as "10s" or "100ms".
+	RTime
+
+	// Punct is an operator or punctuation, such as "{", "==" or "+=".
+	Punct
+)
+
+var kindNames = map[Kind]string{
+	EOF:        "end of file",
+	Illegal:    "illegal character",
+	Identifier: "identifier",
+	String:     "string",
+	Number:     "number",
+	RTime:      "relative time",
+	Punct:      "punctuation",
+}
+
+// String returns the name of the kind.
+func (k Kind) String() string {
+	return kindNames[k]
+}
+
+// Token is a lexical token.
+type Token struct {
+	Kind Kind
+	Pos  Pos
+
+	// Text is the token as written in the source.
+	Text string
+
+	// Value is the content of a String token.
+	Value string
+}
+
+// String returns a description of the token for error messages.
+func (t Token) String() string {
+	switch t.Kind {
+	case EOF:
+		return "end of file"
+	case String:
+		return fmt.Sprintf("string %s", t.Text)
+	}
+	return fmt.Sprintf("%q", t.Text)
+}
+
+// Comment is a comment in a VCL source.
+type Comment struct {
+	Pos Pos
+
+	// Text is the comment including its delimiters.
+	Text string
+}
+
+// puncts are the operators and punctuation, longest first.
+var puncts = []string{
+	"<<=", ">>=", "&&=", "||=",
+	"==", "!=", "!~", "<=", ">=", "&&", "||", "+=", "-=", "*=", "/=", "%=", "|=", "&=", "^=", "<<", ">>",
+	"{", "}", "(", ")", ";", ",", ".", ":", "=", "~", "<", ">", "!", "+", "-", "*", "/", "%", "|", "&", "^",
+}
+
+// rtimeUnits are the units of relative time literals, longest first.
+var rtimeUnits = []string{"ms", "s", "m", "h", "d", "y"}
+
+// Lexer splits a VCL source into tokens.
+type Lexer struct {
+	src  string
+	pos  Pos
+	errs ErrorList
+
+	// Comments are the comments seen so far.
+	Comments []*Comment
+}
+
+// NewLexer returns a lexer for src.
+func NewLexer(src string) *Lexer {
+	return &Lexer{src: src, pos: Pos{Line: 1, Column: 1}}
+}
+
+// Errors returns the errors found so far.
+func (l *Lexer) Errors() ErrorList {
+	return l.errs
+}
+
+// Tokenize returns every token of src up to and including EOF, along with
+// any lexical errors.
+func Tokenize(src string) ([]Token, error) {
+	l := NewLexer(src)
+	var toks []Token
+	for {
+		t := l.Next()
+		toks = append(toks, t)
+		if t.Kind == EOF {
+			break
+		}
+	}
+	return toks, l.errs.Err()
+}
+
+func (l *Lexer) peek(n int) byte {
+	if l.pos.Offset+n < len(l.src) {
+		return l.src[l.pos.Offset+n]
+	}
+	return 0
+}
+
+func (l *Lexer) advance(n int) {
+	for ; n > 0 && l.pos.Offset < len(l.src); n-- {
+		if l.src[l.pos.Offset] == '\n' {
+			l.pos.Line++
+			l.pos.Column = 1
+		} else {
+			l.pos.Column++
+		}
+		l.pos.Offset++
+	}
+}
+
+func (l *Lexer) errorf(pos Pos, format string, args ...interface{}) {
+	l.errs = append(l.errs, &Error{Pos: pos, Msg: fmt.Sprintf(format, args...)})
+}
+
+// skip skips whitespace and comments, recording the comments.
+func (l *Lexer) skip() {
+	for l.pos.Offset < len(l.src) {
+		c := l.peek(0)
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			l.advance(1)
+		case c == '#' || c == '/' && l.peek(1) == '/':
+			start := l.pos
+			end := strings.IndexByte(l.src[start.Offset:], '\n')
+			if end < 0 {
+				end = len(l.src) - start.Offset
+			}
+			l.Comments = append(l.Comments, &Comment{Pos: start, Text: l.src[start.Offset : start.Offset+end]})
+			l.advance(end)
+		case c == '/' && l.peek(1) == '*':
+			start := l.pos
+			end := strings.Index(l.src[start.Offset+2:], "*/")
+			if end < 0 {
+				l.errorf(start, "unterminated comment")
+				end = len(l.src) - start.Offset
+			} else {
+				end += 4
+			}
+			l.Comments = append(l.Comments, &Comment{Pos: start, Text: l.src[start.Offset : start.Offset+end]})
+			l.advance(end)
+		default:
+			return
+		}
+	}
+}
+
+// Next returns the next token.
+func (l *Lexer) Next() Token {
+	l.skip()
+	start := l.pos
+	if start.Offset >= len(l.src) {
+		return Token{Kind: EOF, Pos: start}
+	}
+
+	c := l.peek(0)
+	switch {
+	case isIdentStart(c):
+		n := 1
+		for isIdentChar(l.peek(n)) {
+			n++
+		}
+		// A trailing ':' or '-' is not part of an identifier.
+		for n > 1 && (l.src[start.Offset+n-1] == ':' || l.src[start.Offset+n-1] == '-') {
+			n--
+		}
+		return l.token(Identifier, start, n)
+
+	case isDigit(c):
+		return l.number(start)
+
+	case c == '"':
+		end := strings.IndexAny(l.src[start.Offset+1:], "\"\n")
+		if end < 0 || l.src[start.Offset+1+end] == '\n' {
+			l.errorf(start, "unterminated string")
+			if end < 0 {
+				end = len(l.src) - start.Offset - 1
+			}
+			t := l.token(String, start, end+1)
+			t.Value = t.Text[1:]
+			return t
+		}
+		t := l.token(String, start, end+2)
+		t.Value = t.Text[1 : len(t.Text)-1]
+		return t
+
+	case c == '{' && l.longStringDelimiter() != "":
+		delim := l.longStringDelimiter()
+		open := "{" + delim[:len(delim)-1] + "\""
+		closing := "\"" + delim[:len(delim)-1] + "}"
+		end := strings.Index(l.src[start.Offset+len(open):], closing)
+		if end < 0 {
+			l.errorf(start, "unterminated long string")
+			t := l.token(String, start, len(l.src)-start.Offset)
+			t.Value = t.Text[len(open):]
+			return t
+		}
+		t := l.token(String, start, len(open)+end+len(closing))
+		t.Value = t.Text[len(open) : len(t.Text)-len(closing)]
+		return t
+	}
+
+	for _, p := range puncts {
+		if strings.HasPrefix(l.src[start.Offset:], p) {
+			return l.token(Punct, start, len(p))
+		}
+	}
+
+	l.errorf(start, "illegal character %q", c)
+	return l.token(Illegal, start, 1)
+}
+
+// longStringDelimiter returns the delimiter of a long string starting at the
+// current position, including the quote, e.g. `"` for {"..."} or `xyz"` for
+// {xyz"..."xyz}, or "" when there is none.
+func (l *Lexer) longStringDelimiter() string {
+	rest := l.src[l.pos.Offset+1:]
+	n := 0
+	for n < len(rest) && (rest[n] >= 'a' && rest[n] <= 'z' || rest[n] >= 'A' && rest[n] <= 'Z' || rest[n] == '_') {
+		n++
+	}
+	if n < len(rest) && rest[n] == '"' {
+		return rest[:n+1]
+	}
+	return ""
+}
+
+// number lexes a number, percentage or relative time.
+func (l *Lexer) number(start Pos) Token {
+	n := 0
+	for isDigit(l.peek(n)) {
+		n++
+	}
+	if l.peek(n) == '.' && isDigit(l.peek(n+1)) {
+		n++
+		for isDigit(l.peek(n)) {
+			n++
+		}
+	}
+
+	rest := l.src[start.Offset+n:]
+	for _, u := range rtimeUnits {
+		if strings.HasPrefix(rest, u) && !isIdentChar(l.peek(n+len(u))) {
+			return l.token(RTime, start, n+len(u))
+		}
+	}
+	if l.peek(n) == '%' {
+		return l.token(Number, start, n+1)
+	}
+	if isIdentStart(l.peek(n)) {
+		m := n
+		for isIdentChar(l.peek(m)) {
+			m++
+		}
+		l.errorf(start, "invalid number %q", l.src[start.Offset:start.Offset+m])
+		return l.token(Number, start, m)
+	}
+	return l.token(Number, start, n)
+}
+
+func (l *Lexer) token(kind Kind, start Pos, n int) Token {
+	l.advance(n)
+	return Token{Kind: kind, Pos: start, Text: l.src[start.Offset:l.pos.Offset]}
+}
+
+func isIdentStart(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_'
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || isDigit(c) || c == '.' || c == '-' || c == ':'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
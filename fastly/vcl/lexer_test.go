@@ -0,0 +1,81 @@
+package vcl
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	t.Parallel()
+
+	src := `# comment
+set req.http.X-Foo = {"a "quoted" b"} 10s 50% 1.5;
+if (req.url ~ "^/a" && !req.http.Y) { return(pass); } /* block */
+table t { "k": "v" }`
+
+	toks, err := Tokenize(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, tok := range toks {
+		got = append(got, tok.Kind.String()+" "+tok.Text)
+	}
+	want := []string{
+		"identifier set", "identifier req.http.X-Foo", "punctuation =", `string {"a "quoted" b"}`,
+		"relative time 10s", "number 50%", "number 1.5", "punctuation ;",
+		"identifier if", "punctuation (", "identifier req.url", "punctuation ~", `string "^/a"`,
+		"punctuation &&", "punctuation !", "identifier req.http.Y", "punctuation )", "punctuation {",
+		"identifier return", "punctuation (", "identifier pass", "punctuation )", "punctuation ;", "punctuation }",
+		"identifier table", "identifier t", "punctuation {", `string "k"`, "punctuation :", `string "v"`, "punctuation }",
+		"end of file ",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("bad tokens:\n%s", strings.Join(got, "\n"))
+	}
+
+	if toks[3].Value != `a "quoted" b` {
+		t.Errorf("bad long string value %q", toks[3].Value)
+	}
+	if p := toks[1].Pos; p.Line != 2 || p.Column != 5 || p.Offset != 14 {
+		t.Errorf("bad position %+v", p)
+	}
+
+	l := NewLexer(src)
+	for l.Next().Kind != EOF {
+	}
+	if len(l.Comments) != 2 || l.Comments[0].Text != "# comment" || l.Comments[1].Text != "/* block */" {
+		t.Errorf("bad comments %+v", l.Comments)
+	}
+}
+
+func TestTokenize_delimitedLongString(t *testing.T) {
+	t.Parallel()
+
+	toks, err := Tokenize(`{xyz"a"} b"xyz}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if toks[0].Kind != String || toks[0].Value != `a"} b` {
+		t.Errorf("bad token %+v", toks[0])
+	}
+}
+
+func TestTokenize_errors(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"\"abc\n\"":   "1:1: unterminated string",
+		"/* abc":      "1:1: unterminated comment",
+		"set x = 1;@": "1:11: illegal character '@'",
+		"\n 12abc":    "2:2: invalid number \"12abc\"",
+		`{"abc`:       "1:1: unterminated long string",
+	}
+	for src, want := range cases {
+		_, err := Tokenize(src)
+		if err == nil || !strings.HasPrefix(err.Error(), want) {
+			t.Errorf("%q: got %v, want %s", src, err, want)
+		}
+	}
+}
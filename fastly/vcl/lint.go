@@ -0,0 +1,48 @@
+package vcl
+
+import "fmt"
+
+// BuiltinSubroutines are the subroutines Fastly calls during request
+// processing.
+var BuiltinSubroutines = []string{
+	"vcl_recv", "vcl_hash", "vcl_hit", "vcl_miss", "vcl_pass",
+	"vcl_fetch", "vcl_error", "vcl_deliver", "vcl_log",
+}
+
+// isBuiltinSubroutine reports whether name is a builtin subroutine.
+func isBuiltinSubroutine(name string) bool {
+	for _, b := range BuiltinSubroutines {
+		if b == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Lint checks parsed files which are used together, such as the VCLs of a
+// service version. It reports calls to subroutines which none of the files
+// define and custom subroutines defined more than once. Builtin subroutines
+// may be defined in several files, as Fastly concatenates them.
+func Lint(files ...*File) ErrorList {
+	var errs ErrorList
+
+	defined := make(map[string]bool)
+	for _, f := range files {
+		for _, sub := range f.Subroutines() {
+			if defined[sub.Name] && !isBuiltinSubroutine(sub.Name) {
+				errs = append(errs, &Error{File: f.Name, Pos: sub.NamePos, Msg: fmt.Sprintf("subroutine %s redefined", sub.Name)})
+			}
+			defined[sub.Name] = true
+		}
+	}
+
+	for _, f := range files {
+		Inspect(f, func(n Node) bool {
+			if call, ok := n.(*CallStmt); ok && !defined[call.Name] && !isBuiltinSubroutine(call.Name) {
+				errs = append(errs, &Error{File: f.Name, Pos: call.NamePos, Msg: fmt.Sprintf("call to unknown subroutine %s", call.Name)})
+			}
+			return true
+		})
+	}
+	return errs
+}
@@ -0,0 +1,656 @@
+package vcl
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// assignOps are the operators of set and add statements.
+var assignOps = map[string]bool{
+	"=": true, "+=": true, "-=": true, "*=": true, "/=": true, "%=": true,
+	"|=": true, "&=": true, "^=": true, "<<=": true, ">>=": true, "&&=": true, "||=": true,
+}
+
+// comparisonOps are the comparison operators.
+var comparisonOps = map[string]bool{
+	"==": true, "!=": true, "~": true, "!~": true, "<": true, ">": true, "<=": true, ">=": true,
+}
+
+// declKeywords are the keywords starting a top-level declaration.
+var declKeywords = map[string]bool{
+	"include": true, "import": true, "sub": true, "acl": true, "backend": true,
+	"director": true, "table": true, "penaltybox": true, "ratecounter": true,
+}
+
+// bailout is raised by parser.errorf to abandon the current declaration or
+// statement.
+type bailout struct{}
+
+type parser struct {
+	name string
+	toks []Token
+	i    int
+	tok  Token
+	errs ErrorList
+}
+
+// Parse parses a VCL source. name is used in error messages. The returned file
+// holds everything which could be parsed even when there are errors, and the
+// error is an ErrorList.
+func Parse(name, src string) (*File, error) {
+	p, comments := newParser(name, src)
+	f := &File{Name: name, Comments: comments}
+	for p.tok.Kind != EOF {
+		if d := p.decl(); d != nil {
+			f.Decls = append(f.Decls, d)
+		}
+	}
+	return f, p.errs.Err()
+}
+
+// ParseStmts parses a list of statements, such as the content of a snippet
+// inserted into a subroutine. The returned block holds every statement which
+// could be parsed even when there are errors.
+func ParseStmts(name, src string) (*Block, error) {
+	p, _ := newParser(name, src)
+	b := &Block{At: p.tok.Pos}
+	for p.tok.Kind != EOF {
+		if p.is("}") {
+			p.errs = append(p.errs, &Error{File: name, Pos: p.tok.Pos, Msg: "unexpected \"}\", expected statement"})
+			p.next()
+			continue
+		}
+		if s := p.stmt(); s != nil {
+			b.Stmts = append(b.Stmts, s)
+		}
+	}
+	b.End = p.tok.Pos
+	return b, p.errs.Err()
+}
+
+// ParseExpr parses a single expression, such as the statement of a
+// condition. The expression is nil when there are errors.
+func ParseExpr(src string) (x Expr, err error) {
+	p, _ := newParser("", src)
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(bailout); !ok {
+				panic(r)
+			}
+			x = nil
+		}
+		if err = p.errs.Err(); err != nil {
+			x = nil
+		}
+	}()
+
+	x = p.expr()
+	if p.tok.Kind != EOF {
+		p.errorf(p.tok.Pos, "unexpected %s after expression", p.tok)
+	}
+	return x, nil
+}
+
+// newParser tokenizes src, returning a parser positioned on the first token
+// along with the comments of the source.
+func newParser(name, src string) (*parser, []*Comment) {
+	l := NewLexer(src)
+	p := &parser{name: name}
+	for {
+		t := l.Next()
+		p.toks = append(p.toks, t)
+		if t.Kind == EOF {
+			break
+		}
+	}
+	for _, e := range l.Errors() {
+		e.File = name
+		p.errs = append(p.errs, e)
+	}
+	p.tok = p.toks[0]
+	return p, l.Comments
+}
+
+func (p *parser) next() {
+	if p.i < len(p.toks)-1 {
+		p.i++
+	}
+	p.tok = p.toks[p.i]
+}
+
+func (p *parser) peek() Token {
+	if p.i < len(p.toks)-1 {
+		return p.toks[p.i+1]
+	}
+	return p.tok
+}
+
+func (p *parser) errorf(pos Pos, format string, args ...interface{}) {
+	p.errs = append(p.errs, &Error{File: p.name, Pos: pos, Msg: fmt.Sprintf(format, args...)})
+	panic(bailout{})
+}
+
+func (p *parser) is(punct string) bool {
+	return p.tok.Kind == Punct && p.tok.Text == punct
+}
+
+func (p *parser) isKeyword(kw string) bool {
+	return p.tok.Kind == Identifier && p.tok.Text == kw
+}
+
+// expect consumes the punctuation punct, failing when it is not next.
+func (p *parser) expect(punct string) Pos {
+	pos := p.tok.Pos
+	if !p.is(punct) {
+		p.errorf(pos, "unexpected %s, expected %q", p.tok, punct)
+	}
+	p.next()
+	return pos
+}
+
+// ident consumes an identifier.
+func (p *parser) ident(what string) *Ident {
+	if p.tok.Kind != Identifier {
+		p.errorf(p.tok.Pos, "unexpected %s, expected %s", p.tok, what)
+	}
+	id := &Ident{At: p.tok.Pos, Name: p.tok.Text}
+	p.next()
+	return id
+}
+
+// str consumes a string literal.
+func (p *parser) str(what string) *StringLit {
+	if p.tok.Kind != String {
+		p.errorf(p.tok.Pos, "unexpected %s, expected %s", p.tok, what)
+	}
+	s := &StringLit{At: p.tok.Pos, Value: p.tok.Value, Long: p.tok.Text[0] == '{'}
+	p.next()
+	return s
+}
+
+// semi consumes the semicolon ending a statement.
+func (p *parser) semi() {
+	p.expect(";")
+}
+
+// recoverDecl skips to the next declaration after an error in the
+// declaration starting at token start.
+func (p *parser) recoverDecl(start int) {
+	depth := 0
+	for p.tok.Kind != EOF {
+		switch {
+		case depth == 0 && p.i > start && p.tok.Kind == Identifier && declKeywords[p.tok.Text]:
+			return
+		case p.is("{"):
+			depth++
+		case p.is("}"):
+			depth--
+			if depth <= 0 {
+				p.next()
+				return
+			}
+		}
+		p.next()
+	}
+}
+
+// recoverStmt skips to the end of the statement after an error: past the next
+// semicolon or block, or up to the brace closing the enclosing block.
+func (p *parser) recoverStmt() {
+	depth := 0
+	for p.tok.Kind != EOF {
+		switch {
+		case depth == 0 && p.is(";"):
+			p.next()
+			return
+		case depth == 0 && p.is("}"):
+			return
+		case p.is("{"):
+			depth++
+		case p.is("}"):
+			depth--
+			if depth == 0 {
+				p.next()
+				return
+			}
+		}
+		p.next()
+	}
+}
+
+// decl parses a declaration, returning nil after an error.
+func (p *parser) decl() (d Decl) {
+	start := p.i
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(bailout); !ok {
+				panic(r)
+			}
+			p.recoverDecl(start)
+			d = nil
+		}
+	}()
+
+	if p.tok.Kind != Identifier || !declKeywords[p.tok.Text] {
+		p.errorf(p.tok.Pos, "unexpected %s, expected declaration", p.tok)
+	}
+
+	at := p.tok.Pos
+	kw := p.tok.Text
+	p.next()
+
+	switch kw {
+	case "include":
+		path := p.str("include path")
+		p.semi()
+		return &IncludeDecl{At: at, Path: path.Value}
+
+	case "import":
+		name := p.ident("module name")
+		p.semi()
+		return &ImportDecl{At: at, Name: name.Name}
+
+	case "sub":
+		name := p.ident("subroutine name")
+		sub := &SubDecl{At: at, Name: name.Name, NamePos: name.At}
+		if p.tok.Kind == Identifier {
+			sub.ReturnType = p.tok.Text
+			p.next()
+		}
+		sub.Body = p.block()
+		return sub
+
+	case "acl":
+		acl := &ACLDecl{At: at, Name: p.ident("ACL name").Name}
+		p.expect("{")
+		for !p.is("}") {
+			acl.Entries = append(acl.Entries, p.aclEntry())
+		}
+		p.next()
+		return acl
+
+	case "backend":
+		b := &BackendDecl{At: at, Name: p.ident("backend name").Name}
+		b.Properties = p.object().Properties
+		return b
+
+	case "director":
+		d := &DirectorDecl{At: at, Name: p.ident("director name").Name}
+		d.Type = p.ident("director type").Name
+		p.expect("{")
+		for !p.is("}") {
+			if p.is("{") {
+				d.Backends = append(d.Backends, p.object())
+				continue
+			}
+			d.Properties = append(d.Properties, p.property())
+		}
+		p.next()
+		return d
+
+	case "table":
+		t := &TableDecl{At: at, Name: p.ident("table name").Name, Type: "STRING"}
+		if p.tok.Kind == Identifier {
+			t.Type = p.tok.Text
+			p.next()
+		}
+		p.expect("{")
+		for !p.is("}") {
+			e := &TableEntry{At: p.tok.Pos, Key: p.str("table key").Value}
+			p.expect(":")
+			e.Value = p.primary()
+			t.Entries = append(t.Entries, e)
+			if !p.is("}") {
+				p.expect(",")
+			}
+		}
+		p.next()
+		return t
+
+	default: // penaltybox, ratecounter
+		o := &ObjectDecl{At: at, Kind: kw, Name: p.ident(kw + " name").Name}
+		p.expect("{")
+		p.expect("}")
+		return o
+	}
+}
+
+// aclEntry parses an ACL entry such as `!"192.0.2.0"/24;`.
+func (p *parser) aclEntry() *ACLEntry {
+	e := &ACLEntry{At: p.tok.Pos, Subnet: -1}
+	if p.is("!") {
+		e.Negated = true
+		p.next()
+	}
+	e.IP = p.str("IP address").Value
+	if p.is("/") {
+		p.next()
+		if p.tok.Kind != Number {
+			p.errorf(p.tok.Pos, "unexpected %s, expected subnet", p.tok)
+		}
+		n, err := strconv.Atoi(p.tok.Text)
+		if err != nil {
+			p.errorf(p.tok.Pos, "invalid subnet %s", p.tok.Text)
+		}
+		e.Subnet = n
+		p.next()
+	}
+	p.semi()
+	return e
+}
+
+// object parses a block of properties.
+func (p *parser) object() *Object {
+	o := &Object{At: p.expect("{")}
+	for !p.is("}") {
+		o.Properties = append(o.Properties, p.property())
+	}
+	p.next()
+	return o
+}
+
+// property parses a property such as `.port = "443";` or
+// `.probe = { ... }`.
+func (p *parser) property() *Property {
+	prop := &Property{At: p.expect(".")}
+	prop.Name = p.ident("property name").Name
+	p.expect("=")
+	if p.is("{") {
+		prop.Object = p.object()
+		if p.is(";") {
+			p.next()
+		}
+		return prop
+	}
+	prop.Value = p.value()
+	p.semi()
+	return prop
+}
+
+// block parses statements in braces.
+func (p *parser) block() *Block {
+	b := &Block{At: p.expect("{")}
+	for !p.is("}") {
+		if p.tok.Kind == EOF {
+			p.errorf(p.tok.Pos, "unexpected end of file, expected \"}\"")
+		}
+		if s := p.stmt(); s != nil {
+			b.Stmts = append(b.Stmts, s)
+		}
+	}
+	b.End = p.tok.Pos
+	p.next()
+	return b
+}
+
+// stmt parses a statement, returning nil after an error.
+func (p *parser) stmt() (s Stmt) {
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(bailout); !ok {
+				panic(r)
+			}
+			p.recoverStmt()
+			s = nil
+		}
+	}()
+
+	if p.tok.Kind != Identifier {
+		p.errorf(p.tok.Pos, "unexpected %s, expected statement", p.tok)
+	}
+
+	at := p.tok.Pos
+	kw := p.tok.Text
+	if t := p.peek(); t.Kind == Punct && t.Text == ":" {
+		p.next()
+		p.next()
+		return &LabelStmt{At: at, Name: kw}
+	}
+	p.next()
+
+	switch kw {
+	case "set", "add":
+		target := p.ident("variable")
+		if p.tok.Kind != Punct || !assignOps[p.tok.Text] {
+			p.errorf(p.tok.Pos, "unexpected %s, expected assignment operator", p.tok)
+		}
+		op := p.tok.Text
+		p.next()
+		value := p.value()
+		p.semi()
+		if kw == "add" {
+			return &AddStmt{At: at, Target: target, Op: op, Value: value}
+		}
+		return &SetStmt{At: at, Target: target, Op: op, Value: value}
+
+	case "unset", "remove":
+		target := p.ident("variable")
+		p.semi()
+		return &UnsetStmt{At: at, Keyword: kw, Target: target}
+
+	case "call":
+		name := p.ident("subroutine name")
+		p.semi()
+		return &CallStmt{At: at, Name: name.Name, NamePos: name.At}
+
+	case "return":
+		r := &ReturnStmt{At: at}
+		if !p.is(";") {
+			r.Value = p.expr()
+			if paren, ok := r.Value.(*ParenExpr); ok {
+				r.Value = paren.X
+			}
+		}
+		p.semi()
+		return r
+
+	case "if":
+		return p.ifStmt(at)
+
+	case "declare":
+		if !p.isKeyword("local") {
+			p.errorf(p.tok.Pos, "unexpected %s, expected \"local\"", p.tok)
+		}
+		p.next()
+		d := &DeclareStmt{At: at, Name: p.ident("variable name")}
+		d.Type = p.ident("type").Name
+		p.semi()
+		return d
+
+	case "error":
+		e := &ErrorStmt{At: at}
+		if !p.is(";") {
+			e.Code = p.unary()
+		}
+		if !p.is(";") {
+			e.Msg = p.value()
+		}
+		p.semi()
+		return e
+
+	case "restart":
+		p.semi()
+		return &RestartStmt{At: at}
+
+	case "esi":
+		p.semi()
+		return &EsiStmt{At: at}
+
+	case "synthetic", "synthetic.base64":
+		v := p.value()
+		p.semi()
+		return &SyntheticStmt{At: at, Base64: kw == "synthetic.base64", Value: v}
+
+	case "log":
+		v := p.value()
+		p.semi()
+		return &LogStmt{At: at, Value: v}
+
+	case "goto":
+		label := p.ident("label")
+		p.semi()
+		return &GotoStmt{At: at, Label: label.Name}
+
+	case "include":
+		path := p.str("include path")
+		p.semi()
+		return &IncludeDecl{At: at, Path: path.Value}
+	}
+
+	p.errorf(at, "unexpected %q, expected statement", kw)
+	return nil
+}
+
+// ifStmt parses the rest of an if statement after the keyword.
+func (p *parser) ifStmt(at Pos) *IfStmt {
+	s := &IfStmt{At: at}
+	p.expect("(")
+	s.Cond = p.expr()
+	p.expect(")")
+	s.Then = p.block()
+
+	switch {
+	case p.isKeyword("else"):
+		p.next()
+		if p.isKeyword("if") {
+			elseAt := p.tok.Pos
+			p.next()
+			s.Else = p.ifStmt(elseAt)
+		} else {
+			s.Else = p.block()
+		}
+	case p.isKeyword("elseif"), p.isKeyword("elsif"), p.isKeyword("elif"):
+		elseAt := p.tok.Pos
+		p.next()
+		s.Else = p.ifStmt(elseAt)
+	}
+	return s
+}
+
+// expr parses an expression: || binds loosest, then &&, then !, then the
+// comparisons, then + and -.
+func (p *parser) expr() Expr {
+	x := p.and()
+	for p.is("||") {
+		op := p.tok
+		p.next()
+		x = &BinaryExpr{X: x, Op: op.Text, OpPos: op.Pos, Y: p.and()}
+	}
+	return x
+}
+
+func (p *parser) and() Expr {
+	x := p.not()
+	for p.is("&&") {
+		op := p.tok
+		p.next()
+		x = &BinaryExpr{X: x, Op: op.Text, OpPos: op.Pos, Y: p.not()}
+	}
+	return x
+}
+
+func (p *parser) not() Expr {
+	if p.is("!") {
+		at := p.tok.Pos
+		p.next()
+		return &UnaryExpr{At: at, Op: "!", X: p.not()}
+	}
+	return p.comparison()
+}
+
+func (p *parser) comparison() Expr {
+	x := p.value()
+	if p.tok.Kind == Punct && comparisonOps[p.tok.Text] {
+		op := p.tok
+		p.next()
+		x = &BinaryExpr{X: x, Op: op.Text, OpPos: op.Pos, Y: p.value()}
+	}
+	return x
+}
+
+// value parses an additive expression, with adjacent operands concatenated.
+func (p *parser) value() Expr {
+	x := p.additive()
+	var parts []Expr
+	for p.startsOperand() {
+		if parts == nil {
+			parts = []Expr{x}
+		}
+		parts = append(parts, p.additive())
+	}
+	if parts != nil {
+		return &ConcatExpr{Parts: parts}
+	}
+	return x
+}
+
+// startsOperand reports whether the next token can start an operand of an
+// implicit concatenation.
+func (p *parser) startsOperand() bool {
+	switch p.tok.Kind {
+	case Identifier, String, Number, RTime:
+		return true
+	}
+	return p.is("(")
+}
+
+func (p *parser) additive() Expr {
+	x := p.unary()
+	for p.is("+") || p.is("-") {
+		op := p.tok
+		p.next()
+		x = &BinaryExpr{X: x, Op: op.Text, OpPos: op.Pos, Y: p.unary()}
+	}
+	return x
+}
+
+func (p *parser) unary() Expr {
+	if p.is("-") || p.is("+") {
+		op := p.tok
+		p.next()
+		return &UnaryExpr{At: op.Pos, Op: op.Text, X: p.unary()}
+	}
+	return p.primary()
+}
+
+// primary parses a literal, a variable, a function call or a parenthesized
+// expression.
+func (p *parser) primary() Expr {
+	t := p.tok
+	switch t.Kind {
+	case String:
+		return p.str("string")
+	case Number:
+		p.next()
+		return &NumberLit{At: t.Pos, Text: t.Text}
+	case RTime:
+		p.next()
+		return &RTimeLit{At: t.Pos, Text: t.Text}
+	case Identifier:
+		p.next()
+		id := &Ident{At: t.Pos, Name: t.Text}
+		if !p.is("(") {
+			return id
+		}
+		p.next()
+		call := &CallExpr{Func: id}
+		for !p.is(")") {
+			call.Args = append(call.Args, p.expr())
+			if !p.is(")") {
+				p.expect(",")
+			}
+		}
+		p.next()
+		return call
+	}
+
+	if p.is("(") {
+		p.next()
+		x := &ParenExpr{At: t.Pos, X: p.expr()}
+		p.expect(")")
+		return x
+	}
+
+	p.errorf(t.Pos, "unexpected %s, expected expression", t)
+	return nil
+}
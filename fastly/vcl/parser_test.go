@@ -0,0 +1,267 @@
+package vcl
+
+import (
+	"strings"
+	"testing"
+)
+
+const testVCL = `import boltsort;
+include "shared";
+
+acl internal {
+  "10.0.0.0"/8;
+  !"10.1.0.0"/16;
+  "2001:db8::1";
+}
+
+backend F_origin {
+  .host = "example.com";
+  .port = "443";
+  .probe = {
+    .request = "HEAD / HTTP/1.1" "Host: example.com" "Connection: close";
+    .interval = 60s;
+  }
+}
+
+director pool random {
+  .quorum = 50%;
+  { .backend = F_origin; .weight = 1; }
+}
+
+table redirects {
+  "/old": "/new",
+  "/a": "/b",
+}
+
+sub vcl_recv {
+#FASTLY recv
+  declare local var.n INTEGER;
+  set req.http.X-Path = "/prefix" req.url.path;
+  unset req.http.Cookie;
+  if (client.ip ~ internal && req.url ~ "^/admin") {
+    call admin;
+  } else if (req.http.Y == "1") {
+    set req.backend = F_origin;
+  } elsif (!req.http.Z) {
+    error 403 "Forbidden";
+  } else {
+    return(pass);
+  }
+  return(lookup);
+}
+
+sub admin {
+  set var.n = std.atoi(req.http.N) + 1;
+}
+
+sub is_internal BOOL {
+  return (client.ip ~ internal);
+}
+`
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	f, err := Parse("main", testVCL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(f.Decls) != 9 {
+		t.Fatalf("expected 9 declarations, got %d", len(f.Decls))
+	}
+
+	acl := f.Decls[2].(*ACLDecl)
+	if acl.Name != "internal" || len(acl.Entries) != 3 {
+		t.Fatalf("bad ACL %+v", acl)
+	}
+	if e := acl.Entries[1]; !e.Negated || e.IP != "10.1.0.0" || e.Subnet != 16 {
+		t.Errorf("bad ACL entry %+v", e)
+	}
+	if e := acl.Entries[2]; e.Subnet != -1 {
+		t.Errorf("bad ACL entry %+v", e)
+	}
+
+	backend := f.Decls[3].(*BackendDecl)
+	probe := backend.Properties[2]
+	if probe.Name != "probe" || probe.Object == nil {
+		t.Fatalf("bad probe %+v", probe)
+	}
+	if c, ok := probe.Object.Property("request").Value.(*ConcatExpr); !ok || len(c.Parts) != 3 {
+		t.Errorf("bad probe request %#v", probe.Object.Property("request").Value)
+	}
+
+	director := f.Decls[4].(*DirectorDecl)
+	if director.Type != "random" || len(director.Properties) != 1 || len(director.Backends) != 1 {
+		t.Errorf("bad director %+v", director)
+	}
+
+	table := f.Decls[5].(*TableDecl)
+	if table.Type != "STRING" || len(table.Entries) != 2 || table.Entries[1].Key != "/a" {
+		t.Errorf("bad table %+v", table)
+	}
+
+	subs := f.Subroutines()
+	if len(subs) != 3 || subs[2].ReturnType != "BOOL" {
+		t.Fatalf("bad subroutines %+v", subs)
+	}
+
+	recv := subs[0]
+	if recv.Pos().Line != 29 || recv.NamePos.Column != 5 {
+		t.Errorf("bad positions %s %s", recv.Pos(), recv.NamePos)
+	}
+	if len(recv.Body.Stmts) != 5 {
+		t.Fatalf("expected 5 statements, got %d", len(recv.Body.Stmts))
+	}
+
+	set := recv.Body.Stmts[1].(*SetStmt)
+	if set.Target.Name != "req.http.X-Path" || set.Op != "=" {
+		t.Errorf("bad set %+v", set)
+	}
+
+	ifs := recv.Body.Stmts[3].(*IfStmt)
+	cond := ifs.Cond.(*BinaryExpr)
+	if cond.Op != "&&" || cond.X.(*BinaryExpr).Op != "~" {
+		t.Errorf("bad condition %+v", cond)
+	}
+	elif := ifs.Else.(*IfStmt).Else.(*IfStmt)
+	if _, ok := elif.Cond.(*UnaryExpr); !ok {
+		t.Errorf("bad elsif condition %#v", elif.Cond)
+	}
+	if _, ok := elif.Else.(*Block); !ok {
+		t.Errorf("bad else %#v", elif.Else)
+	}
+
+	ret := recv.Body.Stmts[4].(*ReturnStmt)
+	if id, ok := ret.Value.(*Ident); !ok || id.Name != "lookup" {
+		t.Errorf("bad return %#v", ret.Value)
+	}
+
+	var calls []string
+	Inspect(f, func(n Node) bool {
+		if c, ok := n.(*CallExpr); ok {
+			calls = append(calls, c.Func.Name)
+		}
+		if c, ok := n.(*CallStmt); ok {
+			calls = append(calls, c.Name)
+		}
+		return true
+	})
+	if strings.Join(calls, ",") != "admin,std.atoi" {
+		t.Errorf("bad calls %v", calls)
+	}
+}
+
+func TestParse_errors(t *testing.T) {
+	t.Parallel()
+
+	src := `sub vcl_recv {
+  set req.http.X = ;
+  unset;
+  if (req.url == "/") {
+    sett req.url = "/a";
+  }
+  return(pass);
+}
+
+backend b { .host "x"; }
+
+sub vcl_deliver {
+  set resp.http.Y = "1"
+}
+`
+	f, err := Parse("main", src)
+	el, ok := err.(ErrorList)
+	if !ok {
+		t.Fatalf("expected an ErrorList, got %v", err)
+	}
+
+	want := []string{
+		`main:2:20: unexpected ";", expected expression`,
+		`main:3:8: unexpected ";", expected variable`,
+		`main:5:5: unexpected "sett", expected statement`,
+		`main:10:19: unexpected string "x", expected "="`,
+		`main:14:1: unexpected "}", expected ";"`,
+	}
+	var got []string
+	for _, e := range el {
+		got = append(got, e.Error())
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("bad errors:\n%s", strings.Join(got, "\n"))
+	}
+
+	// Declarations after errors are still parsed.
+	subs := f.Subroutines()
+	if len(subs) != 2 || subs[1].Name != "vcl_deliver" {
+		t.Fatalf("bad subroutines %+v", subs)
+	}
+	if n := len(subs[0].Body.Stmts); n != 2 {
+		t.Errorf("expected 2 statements in vcl_recv, got %d", n)
+	}
+}
+
+func TestParseStmts(t *testing.T) {
+	t.Parallel()
+
+	b, err := ParseStmts("snippet", "set req.http.A = \"1\";\nretry:\ngoto retry;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b.Stmts) != 3 {
+		t.Fatalf("expected 3 statements, got %d", len(b.Stmts))
+	}
+	if l, ok := b.Stmts[1].(*LabelStmt); !ok || l.Name != "retry" {
+		t.Errorf("bad label %#v", b.Stmts[1])
+	}
+
+	if _, err := ParseStmts("snippet", "set req.http.A = \"1\"; }"); err == nil {
+		t.Error("expected an error for a stray brace")
+	}
+}
+
+func TestParseExpr(t *testing.T) {
+	t.Parallel()
+
+	x, err := ParseExpr(`req.http.A == "1" || req.http.B && !(resp.status >= 500)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	or := x.(*BinaryExpr)
+	if or.Op != "||" || or.Y.(*BinaryExpr).Op != "&&" {
+		t.Errorf("bad precedence %#v", x)
+	}
+
+	for _, src := range []string{`req.url ==`, `(req.url`, `req.url == "a" )`} {
+		if x, err := ParseExpr(src); err == nil || x != nil {
+			t.Errorf("%q: expected an error", src)
+		}
+	}
+}
+
+func TestLint(t *testing.T) {
+	t.Parallel()
+
+	main, err := Parse("main", `sub vcl_recv { call shared; call missing; call vcl_hash; }
+sub vcl_deliver { }`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shared, err := Parse("shared", `sub shared { } sub vcl_recv { } sub shared { }`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errs := Lint(main, shared)
+	want := []string{
+		"shared:1:37: subroutine shared redefined",
+		"main:1:34: call to unknown subroutine missing",
+	}
+	var got []string
+	for _, e := range errs {
+		got = append(got, e.Error())
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("bad errors:\n%s", strings.Join(got, "\n"))
+	}
+}
@@ -0,0 +1,282 @@
+package vcl
+
+import (
+	"strings"
+)
+
+// Type is the type of a VCL value.
+type Type string
+
+const (
+	// TypeString is a string.
+	TypeString Type = "STRING"
+
+	// TypeBool is a boolean.
+	TypeBool Type = "BOOL"
+
+	// TypeInteger is an integer.
+	TypeInteger Type = "INTEGER"
+
+	// TypeFloat is a floating point number.
+	TypeFloat Type = "FLOAT"
+
+	// TypeTime is an absolute time.
+	TypeTime Type = "TIME"
+
+	// TypeRTime is a relative time.
+	TypeRTime Type = "RTIME"
+
+	// TypeIP is an IP address.
+	TypeIP Type = "IP"
+
+	// TypeBackend is a backend or director.
+	TypeBackend Type = "BACKEND"
+
+	// TypeID is a bare name such as a table or ACL, used as a function
+	// parameter.
+	TypeID Type = "ID"
+)
+
+// Scope is a set of subroutines in which a variable is available.
+type Scope uint
+
+const (
+	// ScopeRecv is vcl_recv.
+	ScopeRecv Scope = 1 << iota
+
+	// ScopeHash is vcl_hash.
+	ScopeHash
+
+	// ScopeHit is vcl_hit.
+	ScopeHit
+
+	// ScopeMiss is vcl_miss.
+	ScopeMiss
+
+	// ScopePass is vcl_pass.
+	ScopePass
+
+	// ScopeFetch is vcl_fetch.
+	ScopeFetch
+
+	// ScopeError is vcl_error.
+	ScopeError
+
+	// ScopeDeliver is vcl_deliver.
+	ScopeDeliver
+
+	// ScopeLog is vcl_log.
+	ScopeLog
+
+	// ScopeAll is every subroutine.
+	ScopeAll = ScopeRecv | ScopeHash | ScopeHit | ScopeMiss | ScopePass | ScopeFetch | ScopeError | ScopeDeliver | ScopeLog
+)
+
+var scopeNames = []string{"recv", "hash", "hit", "miss", "pass", "fetch", "error", "deliver", "log"}
+
+// String returns the names of the subroutines of the scope, such as
+// "miss, pass".
+func (s Scope) String() string {
+	var names []string
+	for n, name := range scopeNames {
+		if s&(1<<uint(n)) != 0 {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// ScopeOf returns the scope of a builtin subroutine, such as ScopeRecv for
+// "vcl_recv", or 0 for other subroutines.
+func ScopeOf(sub string) Scope {
+	for n, name := range scopeNames {
+		if sub == "vcl_"+name {
+			return 1 << uint(n)
+		}
+	}
+	return 0
+}
+
+// Variable describes a predefined VCL variable.
+type Variable struct {
+	Name string
+	Type Type
+
+	// Scopes are the subroutines in which the variable is available.
+	Scopes Scope
+}
+
+// Function describes a VCL function.
+type Function struct {
+	Name   string
+	Return Type
+
+	// Params are the types of the parameters. A TypeString parameter
+	// accepts any value, as values are converted to strings implicitly.
+	Params []Type
+
+	// Optional is the number of trailing parameters which may be omitted.
+	Optional int
+}
+
+const (
+	scopeBereq = ScopeMiss | ScopePass | ScopeFetch
+	scopeObj   = ScopeHit | ScopeError
+	scopeResp  = ScopeDeliver | ScopeLog
+)
+
+// Variables is the catalogue of predefined variables, by name. It may be
+// extended before checking. Header variables such as req.http.Host are not
+// listed, see LookupVariable.
+var Variables = map[string]*Variable{}
+
+// headerPrefixes are the prefixes of header variables and the scopes of the
+// variables with each prefix.
+var headerPrefixes = map[string]Scope{
+	"req.http.":    ScopeAll,
+	"bereq.http.":  scopeBereq,
+	"beresp.http.": ScopeFetch,
+	"obj.http.":    scopeObj,
+	"resp.http.":   scopeResp,
+}
+
+func init() {
+	add := func(scope Scope, t Type, names ...string) {
+		for _, name := range names {
+			Variables[name] = &Variable{Name: name, Type: t, Scopes: scope}
+		}
+	}
+
+	add(ScopeAll, TypeString,
+		"req.url", "req.url.path", "req.url.qs", "req.url.basename", "req.url.dirname", "req.url.ext",
+		"req.method", "req.request", "req.proto", "req.xid", "req.service_id", "req.topurl",
+		"req.body", "req.postbody", "req.vcl", "req.hash",
+		"client.identity", "client.as.name",
+		"client.geo.country_code", "client.geo.country_code3", "client.geo.country_name",
+		"client.geo.continent_code", "client.geo.region", "client.geo.city", "client.geo.postal_code",
+		"client.geo.conn_speed", "client.geo.conn_type", "client.geo.proxy_type", "client.geo.proxy_description",
+		"server.identity", "server.hostname", "server.datacenter", "server.region",
+		"fastly_info.state", "fastly.error",
+		"tls.client.protocol", "tls.client.cipher", "tls.client.servername", "tls.client.ja3_md5",
+		"now.sec", "time.start.sec",
+	)
+	add(ScopeAll, TypeBool,
+		"req.is_ssl", "req.is_ipv6", "req.is_esi_subreq", "req.is_purge", "req.esi", "req.backend.healthy",
+		"req.enable_range_on_pass", "req.enable_segmented_caching",
+		"client.class.bot", "client.class.browser",
+		"client.platform.mobile", "client.platform.tablet", "client.platform.desktop",
+		"fastly_info.is_h2", "fastly_info.is_h3", "fastly_info.edge.is_tls",
+	)
+	add(ScopeAll, TypeInteger,
+		"req.restarts", "req.header_bytes_read", "req.vcl.version", "req.vcl.generation",
+		"client.port", "client.requests", "client.as.number",
+		"client.geo.metro_code", "client.geo.area_code", "client.geo.gmt_offset", "client.geo.utc_offset",
+		"server.port", "fastly.ff.visits_this_service",
+	)
+	add(ScopeAll, TypeFloat, "client.geo.latitude", "client.geo.longitude")
+	add(ScopeAll, TypeRTime, "req.grace", "req.max_stale_if_error", "req.max_stale_while_revalidate", "time.elapsed")
+	add(ScopeAll, TypeTime, "now", "time.start")
+	add(ScopeAll, TypeIP, "client.ip", "server.ip")
+	add(ScopeAll, TypeBackend, "req.backend")
+	add(ScopeLog, TypeInteger, "req.body_bytes_read", "req.bytes_read")
+	add(ScopeHash|ScopeHit|ScopeMiss|ScopePass|ScopeFetch|ScopeError|ScopeDeliver|ScopeLog, TypeString, "req.digest")
+
+	add(scopeBereq, TypeString, "bereq.url", "bereq.url.path", "bereq.url.qs", "bereq.method", "bereq.request", "bereq.proto")
+	add(scopeBereq, TypeBool, "bereq.is_clustering")
+
+	add(ScopeFetch, TypeString, "beresp.response", "beresp.proto", "beresp.backend.name")
+	add(ScopeFetch, TypeInteger, "beresp.status", "beresp.backend.port", "beresp.backend.requests")
+	add(ScopeFetch, TypeBool,
+		"beresp.cacheable", "beresp.do_esi", "beresp.do_stream", "beresp.gzip", "beresp.brotli",
+		"beresp.hipaa", "beresp.pci",
+	)
+	add(ScopeFetch, TypeRTime,
+		"beresp.ttl", "beresp.grace", "beresp.stale_if_error", "beresp.stale_while_revalidate", "beresp.saintmode",
+	)
+	add(ScopeFetch, TypeIP, "beresp.backend.ip")
+
+	add(scopeObj, TypeString, "obj.response", "obj.proto")
+	add(scopeObj, TypeInteger, "obj.status")
+	add(scopeObj, TypeBool, "obj.cacheable", "obj.is_pci")
+	add(ScopeHit, TypeRTime, "obj.ttl", "obj.grace", "obj.age", "obj.lastuse", "obj.entered", "obj.stale_if_error")
+	add(ScopeHit|ScopeDeliver, TypeInteger, "obj.hits")
+
+	add(scopeResp, TypeString, "resp.response", "resp.proto")
+	add(scopeResp, TypeInteger, "resp.status")
+	add(scopeResp, TypeBool, "resp.is_locally_generated", "resp.stale", "resp.stale.is_error", "resp.stale.is_revalidating")
+	add(scopeResp, TypeRTime, "time.to_first_byte")
+	add(ScopeLog, TypeInteger, "resp.bytes_written", "resp.body_bytes_written", "resp.header_bytes_written")
+	add(ScopeLog, TypeBool, "resp.completed")
+
+	fn := func(name string, ret Type, optional int, params ...Type) {
+		Functions[name] = &Function{Name: name, Return: ret, Params: params, Optional: optional}
+	}
+	s, i := TypeString, TypeInteger
+	fn("std.tolower", s, 0, s)
+	fn("std.toupper", s, 0, s)
+	fn("std.strlen", i, 0, s)
+	fn("std.atoi", i, 0, s)
+	fn("std.atof", TypeFloat, 0, s)
+	fn("std.strstr", s, 0, s, s)
+	fn("std.prefixof", TypeBool, 0, s, s)
+	fn("std.suffixof", TypeBool, 0, s, s)
+	fn("std.ip", TypeIP, 0, s, s)
+	fn("std.str2ip", TypeIP, 0, s, s)
+	fn("std.integer2time", TypeTime, 0, i)
+	fn("std.time", TypeTime, 0, s, TypeTime)
+	fn("substr", s, 1, s, i, i)
+	fn("regsub", s, 0, s, s, s)
+	fn("regsuball", s, 0, s, s, s)
+	fn("subfield", s, 1, s, s, s)
+	fn("querystring.get", s, 0, s, s)
+	fn("table.lookup", s, 1, TypeID, s, s)
+	fn("table.contains", TypeBool, 0, TypeID, s)
+	fn("table.lookup_bool", TypeBool, 0, TypeID, s, TypeBool)
+	fn("table.lookup_integer", i, 0, TypeID, s, i)
+	fn("table.lookup_rtime", TypeRTime, 0, TypeID, s, TypeRTime)
+	fn("table.lookup_ip", TypeIP, 0, TypeID, s, TypeIP)
+	fn("digest.hash_md5", s, 0, s)
+	fn("digest.hash_sha1", s, 0, s)
+	fn("digest.hash_sha256", s, 0, s)
+	fn("digest.base64", s, 0, s)
+	fn("digest.base64_decode", s, 0, s)
+	fn("urlencode", s, 0, s)
+	fn("urldecode", s, 0, s)
+	fn("json.escape", s, 0, s)
+	fn("randombool", TypeBool, 0, i, i)
+	fn("randomint", i, 0, i, i)
+	fn("addr.is_ipv4", TypeBool, 0, TypeIP)
+	fn("addr.is_ipv6", TypeBool, 0, TypeIP)
+	fn("strftime", s, 0, s, TypeTime)
+	fn("parse_time_delta", i, 0, s)
+	fn("http_status_matches", TypeBool, 0, i, s)
+	fn("uuid.version4", s, 0)
+	fn("accept.language_lookup", s, 0, s, s, s)
+	fn("accept.encoding_lookup", s, 0, s, s, s)
+	fn("accept.charset_lookup", s, 0, s, s, s)
+	fn("accept.media_lookup", s, 0, s, s, s, s)
+}
+
+// Functions is the catalogue of functions, by name. It may be extended before
+// checking.
+var Functions = map[string]*Function{}
+
+// LookupVariable returns the predefined variable name, or nil. Header
+// variables such as req.http.Host are strings, and backend.<name>.healthy and
+// director.<name>.healthy are booleans available everywhere.
+func LookupVariable(name string) *Variable {
+	if v, ok := Variables[name]; ok {
+		return v
+	}
+	for prefix, scope := range headerPrefixes {
+		if strings.HasPrefix(name, prefix) && len(name) > len(prefix) {
+			return &Variable{Name: name, Type: TypeString, Scopes: scope}
+		}
+	}
+	for _, prefix := range []string{"backend.", "director."} {
+		if strings.HasPrefix(name, prefix) && strings.HasSuffix(name, ".healthy") && len(name) > len(prefix)+len(".healthy") {
+			return &Variable{Name: name, Type: TypeBool, Scopes: ScopeAll}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,560 @@
+package fastly
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/fastly/go-fastly/v3/fastly/vcl"
+)
+
+// VCLSectionKind is the kind of a section of generated VCL.
+type VCLSectionKind string
+
+const (
+	// VCLSectionDeclaration is a top-level declaration other than a
+	// subroutine, such as a backend, table or pragma.
+	VCLSectionDeclaration VCLSectionKind = "declaration"
+
+	// VCLSectionSubroutine is a subroutine.
+	VCLSectionSubroutine VCLSectionKind = "subroutine"
+
+	// VCLSectionMacro is the code Fastly generates for a #FASTLY macro, between
+	// the "#--FASTLY RECV BEGIN" and "#--FASTLY RECV END" comments.
+	VCLSectionMacro VCLSectionKind = "macro"
+
+	// VCLSectionSnippet is the content of a snippet.
+	VCLSectionSnippet VCLSectionKind = "snippet"
+
+	// VCLSectionHeader is the code generated for a header.
+	VCLSectionHeader VCLSectionKind = "header"
+
+	// VCLSectionCondition is the block guarded by a condition.
+	VCLSectionCondition VCLSectionKind = "condition"
+)
+
+// VCLSection is a section of generated VCL.
+type VCLSection struct {
+	Kind VCLSectionKind
+
+	// Name identifies the section within its kind: the name of the
+	// subroutine, of the object the section was generated for, or the macro,
+	// such as "recv". Declarations are named by their keyword and name, such
+	// as "backend F_origin".
+	Name string
+
+	// Priority is the priority of a snippet, header or condition.
+	Priority int
+
+	// ConditionType is the type of a condition, such as "REQUEST".
+	ConditionType string
+
+	// StartLine and EndLine are the first and last lines of the section,
+	// starting at 1.
+	StartLine int
+	EndLine   int
+
+	// Content is the text of the section.
+	Content string
+
+	// Sections are the sections nested in this one.
+	Sections []*VCLSection
+
+	// Backend, Condition, Header and Snippet are the object the section was
+	// generated from, once linked by GeneratedVCL.Link.
+	Backend   *Backend
+	Condition *Condition
+	Header    *Header
+	Snippet   *Snippet
+
+	depth int
+}
+
+// key identifies the section among its siblings for diffs.
+func (s *VCLSection) key() string {
+	switch s.Kind {
+	case VCLSectionSubroutine:
+		return "sub " + s.Name
+	case VCLSectionDeclaration:
+		return s.Name
+	case VCLSectionCondition:
+		return fmt.Sprintf("%s condition %s", strings.ToLower(s.ConditionType), s.Name)
+	}
+	return fmt.Sprintf("%s %s", s.Kind, s.Name)
+}
+
+// GeneratedVCL is generated VCL split into sections.
+type GeneratedVCL struct {
+	Content  string
+	Sections []*VCLSection
+}
+
+// Walk calls fn for every section in order, nested sections after their
+// parent. Nested sections are skipped when fn returns false.
+func (g *GeneratedVCL) Walk(fn func(s *VCLSection) bool) {
+	var walk func(ss []*VCLSection)
+	walk = func(ss []*VCLSection) {
+		for _, s := range ss {
+			if fn(s) {
+				walk(s.Sections)
+			}
+		}
+	}
+	walk(g.Sections)
+}
+
+// Find returns the sections of the given kind and name, at any depth.
+func (g *GeneratedVCL) Find(kind VCLSectionKind, name string) []*VCLSection {
+	var found []*VCLSection
+	g.Walk(func(s *VCLSection) bool {
+		if s.Kind == kind && s.Name == name {
+			found = append(found, s)
+		}
+		return true
+	})
+	return found
+}
+
+// Subroutine returns the subroutine with the given name, or nil.
+func (g *GeneratedVCL) Subroutine(name string) *VCLSection {
+	for _, s := range g.Sections {
+		if s.Kind == VCLSectionSubroutine && s.Name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+var (
+	vclMacroComment        = regexp.MustCompile(`^#--FASTLY\s+(\w+)\s+(BEGIN|END)\s*$`)
+	vclSnippetComment      = regexp.MustCompile(`^#\s*Snippet\s+(.+?)\s*:\s*(-?\d+)\s*$`)
+	vclHeaderComment       = regexp.MustCompile(`^#\s*Header rewrite\s+(.+?)\s*:\s*(-?\d+)\s*$`)
+	vclConditionComment    = regexp.MustCompile(`^#\s*(Request|Prefetch|Cache|Response) Condition:\s*(.*?)\s*Prio:\s*(-?\d+)\s*$`)
+	vclEndConditionComment = regexp.MustCompile(`(?i)^#\s*end condition\s*$`)
+)
+
+// vclEvent is a token or comment of generated VCL.
+type vclEvent struct {
+	tok     vcl.Token
+	comment *vcl.Comment
+}
+
+func (e vclEvent) pos() vcl.Pos {
+	if e.comment != nil {
+		return e.comment.Pos
+	}
+	return e.tok.Pos
+}
+
+// endLine returns the line on which the token or comment ends.
+func (e vclEvent) endLine() int {
+	if e.comment != nil {
+		return e.comment.Pos.Line + strings.Count(e.comment.Text, "\n")
+	}
+	return e.tok.Pos.Line + strings.Count(e.tok.Text, "\n")
+}
+
+// ParseGeneratedVCL splits generated VCL, as returned by GetGeneratedVCL, into
+// top-level declarations and subroutines. Within subroutines, the code of each
+// #FASTLY macro is a section, which is further split into the snippets,
+// headers and conditions Fastly marks with comments. Sections guarded by a
+// condition are nested in it.
+func ParseGeneratedVCL(content string) (*GeneratedVCL, error) {
+	l := vcl.NewLexer(content)
+	var toks []vcl.Token
+	for {
+		t := l.Next()
+		if t.Kind == vcl.EOF {
+			break
+		}
+		toks = append(toks, t)
+	}
+	if err := l.Errors().Err(); err != nil {
+		return nil, err
+	}
+
+	// Merge tokens and comments in source order.
+	events := make([]vclEvent, 0, len(toks)+len(l.Comments))
+	ci := 0
+	for _, t := range toks {
+		for ci < len(l.Comments) && l.Comments[ci].Pos.Offset < t.Pos.Offset {
+			events = append(events, vclEvent{comment: l.Comments[ci]})
+			ci++
+		}
+		events = append(events, vclEvent{tok: t})
+	}
+	for ; ci < len(l.Comments); ci++ {
+		events = append(events, vclEvent{comment: l.Comments[ci]})
+	}
+
+	lines := strings.Split(content, "\n")
+	g := &GeneratedVCL{Content: content}
+	p := &generatedVCLParser{lines: lines}
+
+	for n := 0; n < len(events); {
+		e := events[n]
+		if e.comment != nil {
+			n++
+			continue
+		}
+		end := p.declarationEnd(events, n)
+		var s *VCLSection
+		if e.tok.Text == "sub" && n+1 < len(events) && events[n+1].comment == nil {
+			s = &VCLSection{Kind: VCLSectionSubroutine, Name: events[n+1].tok.Text}
+			p.subroutine(s, events[n:end])
+		} else {
+			name := e.tok.Text
+			if n+1 < end && events[n+1].comment == nil && events[n+1].tok.Kind != vcl.Punct {
+				name += " " + events[n+1].tok.Text
+			}
+			s = &VCLSection{Kind: VCLSectionDeclaration, Name: name}
+		}
+		s.StartLine = e.tok.Pos.Line
+		s.EndLine = events[end-1].endLine()
+		p.fill(s)
+		g.Sections = append(g.Sections, s)
+		n = end
+	}
+	return g, nil
+}
+
+type generatedVCLParser struct {
+	lines []string
+}
+
+// declarationEnd returns the index after the last event of the top-level
+// declaration starting at events[start]: its closing brace, or its
+// semicolon when it has no body.
+func (p *generatedVCLParser) declarationEnd(events []vclEvent, start int) int {
+	depth := 0
+	for n := start; n < len(events); n++ {
+		t := events[n].tok
+		if events[n].comment != nil || t.Kind != vcl.Punct {
+			continue
+		}
+		switch t.Text {
+		case "{":
+			depth++
+		case "}":
+			depth--
+			if depth <= 0 {
+				return n + 1
+			}
+		case ";":
+			if depth == 0 {
+				return n + 1
+			}
+		}
+	}
+	return len(events)
+}
+
+// fill sets the content of s and its nested sections from their lines.
+func (p *generatedVCLParser) fill(s *VCLSection) {
+	if s.EndLine < s.StartLine {
+		s.EndLine = s.StartLine
+	}
+	s.Content = strings.Join(p.lines[s.StartLine-1:s.EndLine], "\n")
+	for _, c := range s.Sections {
+		p.fill(c)
+	}
+}
+
+// subroutine splits the events of a subroutine into nested sections.
+func (p *generatedVCLParser) subroutine(sub *VCLSection, events []vclEvent) {
+	// stack holds the open sections, the subroutine first.
+	stack := []*VCLSection{sub}
+	depth, last := 0, events[0].endLine()
+
+	open := func(s *VCLSection, line int) {
+		parent := stack[len(stack)-1]
+		s.StartLine = line
+		s.depth = depth
+		parent.Sections = append(parent.Sections, s)
+		stack = append(stack, s)
+	}
+	// closeUntil closes open sections, ending them at line, until stop
+	// returns true for the top one, which is left open.
+	closeUntil := func(line int, stop func(s *VCLSection) bool) {
+		for len(stack) > 1 && !stop(stack[len(stack)-1]) {
+			stack[len(stack)-1].EndLine = line
+			stack = stack[:len(stack)-1]
+		}
+	}
+	isMacro := func(s *VCLSection) bool { return s.Kind == VCLSectionMacro }
+
+	for _, e := range events {
+		line := e.pos().Line
+
+		if e.comment == nil {
+			switch e.tok.Text {
+			case "{":
+				depth++
+			case "}":
+				depth--
+				// Objects nested in a block end with it.
+				closeUntil(last, func(s *VCLSection) bool { return isMacro(s) || s.depth <= depth })
+			}
+			last = e.endLine()
+			continue
+		}
+
+		text := strings.TrimSpace(e.comment.Text)
+		if m := vclMacroComment.FindStringSubmatch(text); m != nil {
+			if m[2] == "BEGIN" {
+				closeUntil(last, isMacro)
+				open(&VCLSection{Kind: VCLSectionMacro, Name: strings.ToLower(m[1])}, line)
+			} else {
+				closeUntil(last, isMacro)
+				if top := stack[len(stack)-1]; top != sub {
+					top.EndLine = line
+					stack = stack[:len(stack)-1]
+				}
+			}
+		} else if vclEndConditionComment.MatchString(text) {
+			closeUntil(last, func(s *VCLSection) bool { return isMacro(s) || s.Kind == VCLSectionCondition })
+			if top := stack[len(stack)-1]; top.Kind == VCLSectionCondition {
+				top.EndLine = line
+				stack = stack[:len(stack)-1]
+			}
+		} else if s := generatedVCLObject(text); s != nil {
+			// An object ends where the next one at the same depth starts.
+			closeUntil(last, func(s *VCLSection) bool { return isMacro(s) || s.depth < depth })
+			open(s, line)
+		}
+		last = e.endLine()
+	}
+	closeUntil(last, func(*VCLSection) bool { return false })
+}
+
+// generatedVCLObject returns a new section for a comment marking the code of a
+// snippet, header or condition, or nil.
+func generatedVCLObject(text string) *VCLSection {
+	if m := vclSnippetComment.FindStringSubmatch(text); m != nil {
+		prio, _ := strconv.Atoi(m[2])
+		return &VCLSection{Kind: VCLSectionSnippet, Name: m[1], Priority: prio}
+	}
+	if m := vclHeaderComment.FindStringSubmatch(text); m != nil {
+		prio, _ := strconv.Atoi(m[2])
+		return &VCLSection{Kind: VCLSectionHeader, Name: m[1], Priority: prio}
+	}
+	if m := vclConditionComment.FindStringSubmatch(text); m != nil {
+		prio, _ := strconv.Atoi(m[3])
+		return &VCLSection{Kind: VCLSectionCondition, Name: m[2], Priority: prio, ConditionType: strings.ToUpper(m[1])}
+	}
+	return nil
+}
+
+// GeneratedVCLObjects are the objects of a service version which generated
+// VCL sections are linked to.
+type GeneratedVCLObjects struct {
+	Backends   []*Backend
+	Conditions []*Condition
+	Headers    []*Header
+	Snippets   []*Snippet
+}
+
+// Link sets the object each section was generated from. Snippets and headers
+// are matched by name and priority, conditions by name, type and priority and
+// backend declarations by their generated name, such as F_origin for a
+// backend named "origin".
+func (g *GeneratedVCL) Link(o *GeneratedVCLObjects) {
+	g.Walk(func(s *VCLSection) bool {
+		switch s.Kind {
+		case VCLSectionDeclaration:
+			for _, b := range o.Backends {
+				if s.Name == "backend "+GeneratedBackendName(b.Name) {
+					s.Backend = b
+				}
+			}
+		case VCLSectionSnippet:
+			for _, sn := range o.Snippets {
+				if sn.Name == s.Name && sn.Priority == s.Priority {
+					s.Snippet = sn
+				}
+			}
+		case VCLSectionHeader:
+			for _, h := range o.Headers {
+				if h.Name == s.Name && int(h.Priority) == s.Priority {
+					s.Header = h
+				}
+			}
+		case VCLSectionCondition:
+			for _, c := range o.Conditions {
+				if c.Name == s.Name && c.Type == s.ConditionType && c.Priority == s.Priority {
+					s.Condition = c
+				}
+			}
+		}
+		return true
+	})
+}
+
+// GeneratedBackendName returns the name of a backend in generated VCL: the
+// name prefixed with "F_" and with characters other than letters, digits and
+// underscores replaced by underscores.
+func GeneratedBackendName(name string) string {
+	b := []byte("F_" + name)
+	for n := 2; n < len(b); n++ {
+		c := b[n]
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_') {
+			b[n] = '_'
+		}
+	}
+	return string(b)
+}
+
+// AnalyzeGeneratedVCLInput is used as input to the AnalyzeGeneratedVCL
+// function.
+type AnalyzeGeneratedVCLInput struct {
+	// ServiceID is the ID of the service (required).
+	ServiceID string
+
+	// ServiceVersion is the specific configuration version (required).
+	ServiceVersion int
+}
+
+// AnalyzeGeneratedVCL gets the generated VCL of a version, splits it into
+// sections and links them to the backends, conditions, headers and snippets of
+// the version.
+func (c *Client) AnalyzeGeneratedVCL(i *AnalyzeGeneratedVCLInput) (*GeneratedVCL, error) {
+	if i.ServiceID == "" {
+		return nil, ErrMissingServiceID
+	}
+
+	if i.ServiceVersion == 0 {
+		return nil, ErrMissingServiceVersion
+	}
+
+	v, err := c.GetGeneratedVCL(&GetGeneratedVCLInput{ServiceID: i.ServiceID, ServiceVersion: i.ServiceVersion})
+	if err != nil {
+		return nil, err
+	}
+	g, err := ParseGeneratedVCL(v.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	o := &GeneratedVCLObjects{}
+	if o.Backends, err = c.ListBackends(&ListBackendsInput{ServiceID: i.ServiceID, ServiceVersion: i.ServiceVersion}); err != nil {
+		return nil, err
+	}
+	if o.Conditions, err = c.ListConditions(&ListConditionsInput{ServiceID: i.ServiceID, ServiceVersion: i.ServiceVersion}); err != nil {
+		return nil, err
+	}
+	if o.Headers, err = c.ListHeaders(&ListHeadersInput{ServiceID: i.ServiceID, ServiceVersion: i.ServiceVersion}); err != nil {
+		return nil, err
+	}
+	if o.Snippets, err = c.ListSnippets(&ListSnippetsInput{ServiceID: i.ServiceID, ServiceVersion: i.ServiceVersion}); err != nil {
+		return nil, err
+	}
+	g.Link(o)
+	return g, nil
+}
+
+// VCLSectionChange is the kind of a change between two generated VCLs.
+type VCLSectionChange string
+
+const (
+	// VCLSectionAdded is a section only in the newer VCL.
+	VCLSectionAdded VCLSectionChange = "added"
+
+	// VCLSectionRemoved is a section only in the older VCL.
+	VCLSectionRemoved VCLSectionChange = "removed"
+
+	// VCLSectionChanged is a section whose own content differs. Changes to
+	// nested sections are reported separately.
+	VCLSectionChanged VCLSectionChange = "changed"
+)
+
+// VCLSectionDiff is a difference between two generated VCLs.
+type VCLSectionDiff struct {
+	// Path locates the section, such as
+	// "sub vcl_recv/macro recv/snippet my-snippet".
+	Path string
+
+	Change VCLSectionChange
+
+	// From and To are the section in the older and newer VCL. One of them is
+	// nil for added and removed sections.
+	From *VCLSection
+	To   *VCLSection
+}
+
+// DiffGeneratedVCL compares two generated VCLs section by section. Sections
+// are matched by kind and name within their parent, so a snippet whose
+// priority changed is reported as changed. A section with nested sections is
+// reported as changed only when its content outside of them differs.
+func DiffGeneratedVCL(from, to *GeneratedVCL) []*VCLSectionDiff {
+	return diffVCLSections("", from.Sections, to.Sections)
+}
+
+func diffVCLSections(path string, from, to []*VCLSection) []*VCLSectionDiff {
+	fromKeys, toKeys := vclSectionKeys(from), vclSectionKeys(to)
+	toByKey := make(map[string]*VCLSection, len(to))
+	for n, s := range to {
+		toByKey[toKeys[n]] = s
+	}
+	seen := make(map[string]bool, len(from))
+
+	var diffs []*VCLSectionDiff
+	for n, f := range from {
+		key := fromKeys[n]
+		seen[key] = true
+		p := path + key
+		t, ok := toByKey[key]
+		if !ok {
+			diffs = append(diffs, &VCLSectionDiff{Path: p, Change: VCLSectionRemoved, From: f})
+			continue
+		}
+		if f.Content == t.Content {
+			continue
+		}
+		if ownVCLContent(f) != ownVCLContent(t) {
+			diffs = append(diffs, &VCLSectionDiff{Path: p, Change: VCLSectionChanged, From: f, To: t})
+		}
+		diffs = append(diffs, diffVCLSections(p+"/", f.Sections, t.Sections)...)
+	}
+	for n, t := range to {
+		if !seen[toKeys[n]] {
+			diffs = append(diffs, &VCLSectionDiff{Path: path + toKeys[n], Change: VCLSectionAdded, To: t})
+		}
+	}
+	return diffs
+}
+
+// vclSectionKeys returns the keys of sections, numbering duplicates.
+func vclSectionKeys(ss []*VCLSection) []string {
+	keys := make([]string, len(ss))
+	count := make(map[string]int, len(ss))
+	for n, s := range ss {
+		k := s.key()
+		count[k]++
+		if count[k] > 1 {
+			k = fmt.Sprintf("%s#%d", k, count[k])
+		}
+		keys[n] = k
+	}
+	return keys
+}
+
+// ownVCLContent returns the content of s without its nested sections, with
+// leading whitespace removed so that reindentation is not a change.
+func ownVCLContent(s *VCLSection) string {
+	lines := strings.Split(s.Content, "\n")
+	var own []string
+	for n, line := range lines {
+		nested := false
+		for _, c := range s.Sections {
+			if l := s.StartLine + n; l >= c.StartLine && l <= c.EndLine {
+				nested = true
+				break
+			}
+		}
+		if !nested {
+			own = append(own, strings.TrimSpace(line))
+		}
+	}
+	return strings.Join(own, "\n")
+}
@@ -0,0 +1,189 @@
+package fastly
+
+import (
+	"strings"
+	"testing"
+)
+
+const testGeneratedVCL = `pragma optional_param geoip_opt_in true;
+
+backend F_origin_example_com {
+    .connect_timeout = 1s;
+    .port = "443";
+    .host = "origin.example.com";
+}
+
+sub vcl_recv {
+#--FASTLY RECV BEGIN
+  if (req.restarts == 0) {
+    if (!req.http.Fastly-SSL) {
+      set req.http.Fastly-SSL = if(req.is_ssl, "1", "0");
+    }
+  }
+  # Snippet strip-cookies : 100
+  unset req.http.Cookie;
+  # Header rewrite Add Debug : 10
+  set req.http.X-Debug = "1";
+
+  # Request Condition: is_api Prio: 10
+  if( req.url ~ "^/api" ) {
+    # Header rewrite API Version : 20
+    set req.http.X-API = "v2";
+  }
+  #end condition
+#--FASTLY RECV END
+  return(lookup);
+}
+
+sub vcl_deliver {
+#--FASTLY DELIVER BEGIN
+  # Response Condition: not_found Prio: 5
+  if( resp.status == 404 ) {
+    set resp.http.Cache-Control = "no-store";
+  }
+  #end condition
+#--FASTLY DELIVER END
+  return(deliver);
+}
+`
+
+func TestParseGeneratedVCL(t *testing.T) {
+	t.Parallel()
+
+	g, err := ParseGeneratedVCL(testGeneratedVCL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	g.Walk(func(s *VCLSection) bool {
+		got = append(got, string(s.Kind)+" "+s.Name)
+		return true
+	})
+	want := []string{
+		"declaration pragma optional_param",
+		"declaration backend F_origin_example_com",
+		"subroutine vcl_recv",
+		"macro recv",
+		"snippet strip-cookies",
+		"header Add Debug",
+		"condition is_api",
+		"header API Version",
+		"subroutine vcl_deliver",
+		"macro deliver",
+		"condition not_found",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("bad sections:\n%s", strings.Join(got, "\n"))
+	}
+
+	recv := g.Subroutine("vcl_recv")
+	if recv == nil || recv.StartLine != 9 || recv.EndLine != 29 {
+		t.Fatalf("bad vcl_recv %+v", recv)
+	}
+	macro := recv.Sections[0]
+	if macro.StartLine != 10 || macro.EndLine != 27 || len(macro.Sections) != 3 {
+		t.Fatalf("bad macro %+v", macro)
+	}
+
+	snippet := g.Find(VCLSectionSnippet, "strip-cookies")[0]
+	if snippet.Priority != 100 || snippet.Content != "  # Snippet strip-cookies : 100\n  unset req.http.Cookie;" {
+		t.Errorf("bad snippet %+v", snippet)
+	}
+
+	cond := g.Find(VCLSectionCondition, "is_api")[0]
+	if cond.ConditionType != "REQUEST" || cond.Priority != 10 || cond.StartLine != 21 || cond.EndLine != 26 {
+		t.Errorf("bad condition %+v", cond)
+	}
+	nested := cond.Sections[0]
+	if nested.Name != "API Version" || nested.StartLine != 23 || nested.EndLine != 24 {
+		t.Errorf("bad nested header %+v", nested)
+	}
+
+	if _, err := ParseGeneratedVCL(`sub vcl_recv { set req.http.X = "a`); err == nil {
+		t.Error("expected an error for invalid VCL")
+	}
+}
+
+func TestDiffGeneratedVCL(t *testing.T) {
+	t.Parallel()
+
+	from, err := ParseGeneratedVCL(testGeneratedVCL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changed := strings.NewReplacer(
+		`set req.http.X-API = "v2";`, `set req.http.X-API = "v3";`,
+		"  # Snippet strip-cookies : 100\n  unset req.http.Cookie;\n", "  # Snippet strip-cookies : 50\n  unset req.http.Cookie;\n",
+		"  return(deliver);", "  set resp.http.X-Served = \"1\";\n  return(deliver);",
+	).Replace(testGeneratedVCL)
+	to, err := ParseGeneratedVCL(changed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, d := range DiffGeneratedVCL(from, to) {
+		got = append(got, string(d.Change)+" "+d.Path)
+	}
+	want := []string{
+		"changed sub vcl_recv/macro recv/snippet strip-cookies",
+		"changed sub vcl_recv/macro recv/request condition is_api/header API Version",
+		"changed sub vcl_deliver",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("bad diff:\n%s", strings.Join(got, "\n"))
+	}
+
+	if d := DiffGeneratedVCL(from, from); len(d) != 0 {
+		t.Errorf("expected no differences, got %d", len(d))
+	}
+}
+
+func TestClient_AnalyzeGeneratedVCL(t *testing.T) {
+	t.Parallel()
+
+	var g *GeneratedVCL
+	var err error
+	record(t, "generated_vcl/analyze", func(c *Client) {
+		g, err = c.AnalyzeGeneratedVCL(&AnalyzeGeneratedVCLInput{
+			ServiceID:      testServiceID,
+			ServiceVersion: 3,
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if b := g.Find(VCLSectionDeclaration, "backend F_origin_example_com")[0].Backend; b == nil || b.Name != "origin.example.com" {
+		t.Errorf("bad backend %+v", b)
+	}
+	if s := g.Find(VCLSectionSnippet, "strip-cookies")[0].Snippet; s == nil || s.ID != "62Yd1WfiCBPENLloXfXmlO" {
+		t.Errorf("bad snippet %+v", s)
+	}
+	if h := g.Find(VCLSectionHeader, "API Version")[0].Header; h == nil || h.RequestCondition != "is_api" {
+		t.Errorf("bad header %+v", h)
+	}
+	if c := g.Find(VCLSectionCondition, "not_found")[0].Condition; c == nil || c.Type != "RESPONSE" {
+		t.Errorf("bad condition %+v", c)
+	}
+}
+
+func TestClient_AnalyzeGeneratedVCL_validation(t *testing.T) {
+	var err error
+	_, err = testClient.AnalyzeGeneratedVCL(&AnalyzeGeneratedVCLInput{
+		ServiceID: "",
+	})
+	if err != ErrMissingServiceID {
+		t.Errorf("bad error: %s", err)
+	}
+
+	_, err = testClient.AnalyzeGeneratedVCL(&AnalyzeGeneratedVCLInput{
+		ServiceID:      "foo",
+		ServiceVersion: 0,
+	})
+	if err != ErrMissingServiceVersion {
+		t.Errorf("bad error: %s", err)
+	}
+}
@@ -0,0 +1,42 @@
+package fastly
+
+import (
+	"github.com/fastly/go-fastly/v3/fastly/vcl"
+)
+
+// LintVCL checks VCLs and snippets used together in a service version for
+// syntax errors and calls to unknown subroutines, without sending them to the
+// API. Snippets of type init or none are parsed as declarations and the others
+// as the statements of their subroutine.
+//
+// The returned error is a vcl.ErrorList naming the VCL or snippet of each
+// error, or nil when there are none.
+func LintVCL(vcls []*VCL, snippets []*Snippet) error {
+	var errs vcl.ErrorList
+	files := make([]*vcl.File, 0, len(vcls)+len(snippets))
+	add := func(f *vcl.File, err error) {
+		if el, ok := err.(vcl.ErrorList); ok {
+			errs = append(errs, el...)
+		}
+		files = append(files, f)
+	}
+
+	for _, v := range vcls {
+		add(vcl.Parse(v.Name, v.Content))
+	}
+
+	for _, s := range snippets {
+		name := "snippet::" + s.Name
+		switch s.Type {
+		case SnippetTypeInit, SnippetTypeNone:
+			add(vcl.Parse(name, s.Content))
+		default:
+			body, err := vcl.ParseStmts(name, s.Content)
+			sub := &vcl.SubDecl{At: body.At, Name: "vcl_" + string(s.Type), NamePos: body.At, Body: body}
+			add(&vcl.File{Name: name, Decls: []vcl.Decl{sub}}, err)
+		}
+	}
+
+	errs = append(errs, vcl.Lint(files...)...)
+	return errs.Err()
+}
@@ -0,0 +1,42 @@
+package fastly
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fastly/go-fastly/v3/fastly/vcl"
+)
+
+func TestLintVCL(t *testing.T) {
+	t.Parallel()
+
+	vcls := []*VCL{
+		{Name: "main", Content: "include \"shared\";\nsub vcl_recv {\n#FASTLY recv\n  call normalize;\n  return(lookup);\n}\n"},
+		{Name: "shared", Content: "sub normalize {\n  set req.url = std.tolower(req.url);\n}\n"},
+	}
+	snippets := []*Snippet{
+		{Name: "helpers", Type: SnippetTypeInit, Content: "sub strip { unset req.http.Cookie; }"},
+		{Name: "recv", Type: SnippetTypeRecv, Content: "call strip;\ncall missing;"},
+	}
+
+	if err := LintVCL(vcls, snippets[:1]); err != nil {
+		t.Fatalf("expected no errors, got %s", err)
+	}
+
+	err := LintVCL(append(vcls, &VCL{Name: "broken", Content: "sub vcl_deliver {\n  set resp.status 200;\n}"}), snippets)
+	el, ok := err.(vcl.ErrorList)
+	if !ok {
+		t.Fatalf("expected a vcl.ErrorList, got %v", err)
+	}
+	want := []string{
+		`broken:2:19: unexpected "200", expected assignment operator`,
+		"snippet::recv:2:6: call to unknown subroutine missing",
+	}
+	var got []string
+	for _, e := range el {
+		got = append(got, e.Error())
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("bad errors:\n%s", strings.Join(got, "\n"))
+	}
+}
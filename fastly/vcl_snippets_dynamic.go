@@ -0,0 +1,204 @@
+package fastly
+
+import (
+	"fmt"
+)
+
+// FindDynamicSnippetInput is used as input to the FindDynamicSnippet function.
+type FindDynamicSnippetInput struct {
+	// ServiceID is the ID of the service (required).
+	ServiceID string
+
+	// Name is the name of the snippet (required).
+	Name string
+}
+
+// FindDynamicSnippet returns the dynamic snippet with the given name on the
+// active version of a service. Its ID can be used with GetDynamicSnippet and
+// UpdateDynamicSnippet, which do not depend on a version.
+func (c *Client) FindDynamicSnippet(i *FindDynamicSnippetInput) (*Snippet, error) {
+	if i.ServiceID == "" {
+		return nil, ErrMissingServiceID
+	}
+
+	if i.Name == "" {
+		return nil, ErrMissingName
+	}
+
+	s, err := c.GetService(&GetServiceInput{ID: i.ServiceID})
+	if err != nil {
+		return nil, err
+	}
+	if s.ActiveVersion == 0 {
+		return nil, ErrNoActiveVersion
+	}
+
+	snippet, err := c.GetSnippet(&GetSnippetInput{
+		ServiceID:      i.ServiceID,
+		ServiceVersion: int(s.ActiveVersion),
+		Name:           i.Name,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if snippet.Dynamic != 1 {
+		return nil, ErrSnippetNotDynamic
+	}
+	return snippet, nil
+}
+
+// UpdateDynamicSnippetByNameInput is used as input to the
+// UpdateDynamicSnippetByName function.
+type UpdateDynamicSnippetByNameInput struct {
+	// ServiceID is the ID of the service (required).
+	ServiceID string
+
+	// Name is the name of the snippet (required).
+	Name string
+
+	// Content is the new VCL code of the snippet (required).
+	Content string
+
+	// ExpectedContent, if set, is the content the snippet must have for the
+	// update to proceed, to avoid overwriting a concurrent change. The check
+	// is best-effort: the snippet is read and then updated in two requests,
+	// so a change made between them is still overwritten.
+	ExpectedContent *string
+}
+
+// UpdateDynamicSnippetByName replaces the content of the dynamic snippet with
+// the given name on the active version. The previous content is recorded by
+// the client, see RollbackDynamicSnippet.
+func (c *Client) UpdateDynamicSnippetByName(i *UpdateDynamicSnippetByNameInput) (*DynamicSnippet, error) {
+	if i.ServiceID == "" {
+		return nil, ErrMissingServiceID
+	}
+
+	if i.Name == "" {
+		return nil, ErrMissingName
+	}
+
+	if i.Content == "" {
+		return nil, ErrMissingContent
+	}
+
+	snippet, err := c.FindDynamicSnippet(&FindDynamicSnippetInput{ServiceID: i.ServiceID, Name: i.Name})
+	if err != nil {
+		return nil, err
+	}
+
+	previous, err := c.GetDynamicSnippet(&GetDynamicSnippetInput{ServiceID: i.ServiceID, ID: snippet.ID})
+	if err != nil {
+		return nil, err
+	}
+	if i.ExpectedContent != nil && previous.Content != *i.ExpectedContent {
+		return nil, ErrDynamicSnippetChanged
+	}
+
+	updated, err := c.UpdateDynamicSnippet(&UpdateDynamicSnippetInput{
+		ServiceID: i.ServiceID,
+		ID:        snippet.ID,
+		Content:   i.Content,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c.snippetHistoryLock.Lock()
+	defer c.snippetHistoryLock.Unlock()
+	if c.snippetHistory == nil {
+		c.snippetHistory = make(map[string][]*DynamicSnippet)
+	}
+	key := snippetHistoryKey(i.ServiceID, i.Name)
+	c.snippetHistory[key] = append(c.snippetHistory[key], previous)
+	return updated, nil
+}
+
+// DynamicSnippetHistory returns the previous contents of a dynamic snippet
+// recorded by UpdateDynamicSnippetByName, oldest first.
+func (c *Client) DynamicSnippetHistory(serviceID, name string) []*DynamicSnippet {
+	c.snippetHistoryLock.Lock()
+	defer c.snippetHistoryLock.Unlock()
+	h := c.snippetHistory[snippetHistoryKey(serviceID, name)]
+	return append([]*DynamicSnippet(nil), h...)
+}
+
+// RollbackDynamicSnippetInput is used as input to the RollbackDynamicSnippet
+// function.
+type RollbackDynamicSnippetInput struct {
+	// ServiceID is the ID of the service (required).
+	ServiceID string
+
+	// Name is the name of the snippet (required).
+	Name string
+
+	// Steps is the number of updates to undo. Defaults to 1.
+	Steps int
+}
+
+// RollbackDynamicSnippet restores the content a dynamic snippet had before the
+// last updates made by UpdateDynamicSnippetByName with this client. The undone
+// updates are removed from the history, so successive rollbacks go further
+// back.
+func (c *Client) RollbackDynamicSnippet(i *RollbackDynamicSnippetInput) (*DynamicSnippet, error) {
+	if i.ServiceID == "" {
+		return nil, ErrMissingServiceID
+	}
+
+	if i.Name == "" {
+		return nil, ErrMissingName
+	}
+
+	steps := i.Steps
+	if steps <= 0 {
+		steps = 1
+	}
+
+	key := snippetHistoryKey(i.ServiceID, i.Name)
+	c.snippetHistoryLock.Lock()
+	h := c.snippetHistory[key]
+	if len(h) < steps {
+		c.snippetHistoryLock.Unlock()
+		return nil, NewFieldError("Steps").Message(fmt.Sprintf("%d updates of snippet %q recorded, cannot roll back %d", len(h), i.Name, steps))
+	}
+	undone := append([]*DynamicSnippet(nil), h[len(h)-steps:]...)
+	c.snippetHistoryLock.Unlock()
+
+	// The lock is not held during the request, so other updates may be
+	// recorded meanwhile; only the undone entries are removed afterwards.
+	target := undone[0]
+	restored, err := c.UpdateDynamicSnippet(&UpdateDynamicSnippetInput{
+		ServiceID: i.ServiceID,
+		ID:        target.ID,
+		Content:   target.Content,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c.snippetHistoryLock.Lock()
+	defer c.snippetHistoryLock.Unlock()
+	var kept []*DynamicSnippet
+	for _, s := range c.snippetHistory[key] {
+		if !containsDynamicSnippet(undone, s) {
+			kept = append(kept, s)
+		}
+	}
+	c.snippetHistory[key] = kept
+	return restored, nil
+}
+
+// containsDynamicSnippet reports whether snippets holds s.
+func containsDynamicSnippet(snippets []*DynamicSnippet, s *DynamicSnippet) bool {
+	for _, ds := range snippets {
+		if ds == s {
+			return true
+		}
+	}
+	return false
+}
+
+// snippetHistoryKey returns the key of a snippet in the client history.
+func snippetHistoryKey(serviceID, name string) string {
+	return serviceID + "/" + name
+}
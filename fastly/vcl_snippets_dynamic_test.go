@@ -0,0 +1,180 @@
+package fastly
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestClient_UpdateDynamicSnippetByName(t *testing.T) {
+	t.Parallel()
+
+	v1 := `set req.http.X-Patch = "1";`
+	v2 := `set req.http.X-Patch = "2";`
+	v3 := `set req.http.X-Patch = "3";`
+
+	record(t, "vcl_snippets/update_dynamic_by_name", func(c *Client) {
+		ds, err := c.UpdateDynamicSnippetByName(&UpdateDynamicSnippetByNameInput{
+			ServiceID: testServiceID,
+			Name:      "hotpatch",
+			Content:   v2,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ds.Content != v2 || ds.ID != "2Ixzsm1YNjy9csTVNIKSjp" {
+			t.Errorf("bad snippet: %+v", ds)
+		}
+
+		if _, err := c.UpdateDynamicSnippetByName(&UpdateDynamicSnippetByNameInput{
+			ServiceID:       testServiceID,
+			Name:            "hotpatch",
+			Content:         v3,
+			ExpectedContent: String(v2),
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = c.UpdateDynamicSnippetByName(&UpdateDynamicSnippetByNameInput{
+			ServiceID:       testServiceID,
+			Name:            "hotpatch",
+			Content:         v1,
+			ExpectedContent: String(v2),
+		})
+		if err != ErrDynamicSnippetChanged {
+			t.Errorf("bad error: %v", err)
+		}
+
+		h := c.DynamicSnippetHistory(testServiceID, "hotpatch")
+		if len(h) != 2 || h[0].Content != v1 || h[1].Content != v2 {
+			t.Fatalf("bad history: %+v", h)
+		}
+
+		ds, err = c.RollbackDynamicSnippet(&RollbackDynamicSnippetInput{
+			ServiceID: testServiceID,
+			Name:      "hotpatch",
+			Steps:     2,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ds.Content != v1 {
+			t.Errorf("bad content: %q", ds.Content)
+		}
+		if h := c.DynamicSnippetHistory(testServiceID, "hotpatch"); len(h) != 0 {
+			t.Errorf("expected an empty history, got %d entries", len(h))
+		}
+
+		_, err = c.RollbackDynamicSnippet(&RollbackDynamicSnippetInput{
+			ServiceID: testServiceID,
+			Name:      "hotpatch",
+		})
+		if _, ok := err.(*FieldError); !ok {
+			t.Errorf("bad error: %v", err)
+		}
+	})
+}
+
+// beforeRequest is an http.RoundTripper calling a function before each request.
+type beforeRequest struct {
+	next http.RoundTripper
+	fn   func(req *http.Request)
+}
+
+func (br *beforeRequest) RoundTrip(req *http.Request) (*http.Response, error) {
+	br.fn(req)
+	return br.next.RoundTrip(req)
+}
+
+func TestClient_RollbackDynamicSnippet_unlocked(t *testing.T) {
+	t.Parallel()
+
+	v1 := `set req.http.X-Patch = "1";`
+	key := snippetHistoryKey(testServiceID, "hotpatch")
+	first := &DynamicSnippet{ID: "2Ixzsm1YNjy9csTVNIKSjp", Content: v1}
+	concurrent := &DynamicSnippet{ID: "2Ixzsm1YNjy9csTVNIKSjp", Content: `set req.http.X-Patch = "2";`}
+
+	record(t, "vcl_snippets/rollback_dynamic", func(c *Client) {
+		c.snippetHistory = map[string][]*DynamicSnippet{key: {first}}
+		c.HTTPClient.Transport = &beforeRequest{
+			next: c.HTTPClient.Transport,
+			fn: func(req *http.Request) {
+				// Record an update from another goroutine while the rollback
+				// request is in flight.
+				done := make(chan struct{})
+				go func() {
+					c.snippetHistoryLock.Lock()
+					c.snippetHistory[key] = append(c.snippetHistory[key], concurrent)
+					c.snippetHistoryLock.Unlock()
+					close(done)
+				}()
+				select {
+				case <-done:
+				case <-time.After(5 * time.Second):
+					t.Fatal("history locked during the rollback request")
+				}
+			},
+		}
+
+		ds, err := c.RollbackDynamicSnippet(&RollbackDynamicSnippetInput{
+			ServiceID: testServiceID,
+			Name:      "hotpatch",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ds.Content != v1 {
+			t.Errorf("bad content: %q", ds.Content)
+		}
+		if h := c.DynamicSnippetHistory(testServiceID, "hotpatch"); len(h) != 1 || h[0] != concurrent {
+			t.Errorf("bad history: %+v", h)
+		}
+	})
+}
+
+func TestClient_FindDynamicSnippet_notDynamic(t *testing.T) {
+	t.Parallel()
+
+	var err error
+	record(t, "vcl_snippets/find_dynamic_not_dynamic", func(c *Client) {
+		_, err = c.FindDynamicSnippet(&FindDynamicSnippetInput{
+			ServiceID: testServiceID,
+			Name:      "hotpatch",
+		})
+	})
+	if err != ErrSnippetNotDynamic {
+		t.Errorf("bad error: %v", err)
+	}
+}
+
+func TestClient_DynamicSnippetsByName_validation(t *testing.T) {
+	var err error
+	_, err = testClient.FindDynamicSnippet(&FindDynamicSnippetInput{
+		ServiceID: "",
+	})
+	if err != ErrMissingServiceID {
+		t.Errorf("bad error: %s", err)
+	}
+
+	_, err = testClient.FindDynamicSnippet(&FindDynamicSnippetInput{
+		ServiceID: "foo",
+	})
+	if err != ErrMissingName {
+		t.Errorf("bad error: %s", err)
+	}
+
+	_, err = testClient.UpdateDynamicSnippetByName(&UpdateDynamicSnippetByNameInput{
+		ServiceID: "foo",
+		Name:      "bar",
+	})
+	if err != ErrMissingContent {
+		t.Errorf("bad error: %s", err)
+	}
+
+	_, err = testClient.RollbackDynamicSnippet(&RollbackDynamicSnippetInput{
+		ServiceID: "foo",
+	})
+	if err != ErrMissingName {
+		t.Errorf("bad error: %s", err)
+	}
+}
@@ -0,0 +1,268 @@
+package fastly
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fastly/go-fastly/v3/fastly/vcl"
+)
+
+// snippetTypeOrder lists the snippet types in the order their subroutines
+// appear in generated VCL.
+var snippetTypeOrder = []SnippetType{
+	SnippetTypeInit, SnippetTypeRecv, SnippetTypeHash, SnippetTypeHit, SnippetTypeMiss, SnippetTypePass,
+	SnippetTypeFetch, SnippetTypeError, SnippetTypeDeliver, SnippetTypeLog, SnippetTypeNone,
+}
+
+// SnippetWarningKind is the kind of a problem found by OrderSnippets.
+type SnippetWarningKind string
+
+const (
+	// SnippetWarningDuplicatePriority is reported for snippets of the same
+	// type sharing a priority, whose relative order then depends on their
+	// names.
+	SnippetWarningDuplicatePriority SnippetWarningKind = "duplicate_priority"
+
+	// SnippetWarningUnreachable is reported for snippets placed after one
+	// that always returns, which therefore never run.
+	SnippetWarningUnreachable SnippetWarningKind = "unreachable"
+
+	// SnippetWarningConflictingReturns is reported when snippets of the same
+	// type return different actions.
+	SnippetWarningConflictingReturns SnippetWarningKind = "conflicting_returns"
+
+	// SnippetWarningInvalid is reported for snippets whose content cannot be
+	// parsed.
+	SnippetWarningInvalid SnippetWarningKind = "invalid"
+)
+
+// SnippetWarning is a problem with the ordering of snippets.
+type SnippetWarning struct {
+	Kind SnippetWarningKind
+	Type SnippetType
+
+	// Snippets are the names of the snippets involved, in execution order.
+	Snippets []string
+
+	Message string
+}
+
+// String returns the warning message.
+func (w *SnippetWarning) String() string {
+	return w.Message
+}
+
+// SnippetTypeOrder is the execution order of the snippets of one type.
+type SnippetTypeOrder struct {
+	Type     SnippetType
+	Snippets []*Snippet
+}
+
+// SnippetOrder is the effective order of a list of snippets.
+type SnippetOrder struct {
+	// Types lists the snippets of each type present, in the order the types
+	// appear in generated VCL.
+	Types []*SnippetTypeOrder
+
+	Warnings []*SnippetWarning
+}
+
+// Snippets returns the snippets of type t in execution order.
+func (o *SnippetOrder) Snippets(t SnippetType) []*Snippet {
+	for _, to := range o.Types {
+		if to.Type == t {
+			return to.Snippets
+		}
+	}
+	return nil
+}
+
+// OrderSnippets returns the order in which snippets, such as those returned by
+// ListSnippets, are placed in generated VCL. Within a type, snippets are
+// placed by increasing priority and snippets sharing a priority by name, as
+// Fastly does.
+//
+// The content of the snippets is parsed to find return statements. A warning is
+// reported for snippets following one which returns unconditionally, as they
+// never run, and for snippets of a type returning different actions. The
+// content of dynamic snippets is not returned by ListSnippets, so they are only
+// checked when their content was set, for example from GetDynamicSnippet.
+func OrderSnippets(snippets []*Snippet) *SnippetOrder {
+	byType := make(map[SnippetType][]*Snippet)
+	for _, s := range snippets {
+		byType[s.Type] = append(byType[s.Type], s)
+	}
+
+	o := &SnippetOrder{}
+	types := append([]SnippetType(nil), snippetTypeOrder...)
+	for t := range byType {
+		if !containsSnippetType(types, t) {
+			types = append(types, t)
+		}
+	}
+
+	for _, t := range types {
+		ss := byType[t]
+		if len(ss) == 0 {
+			continue
+		}
+		sort.SliceStable(ss, func(a, b int) bool {
+			if ss[a].Priority != ss[b].Priority {
+				return ss[a].Priority < ss[b].Priority
+			}
+			return ss[a].Name < ss[b].Name
+		})
+		o.Types = append(o.Types, &SnippetTypeOrder{Type: t, Snippets: ss})
+		o.Warnings = append(o.Warnings, duplicateSnippetPriorities(t, ss)...)
+		if t != SnippetTypeInit && t != SnippetTypeNone {
+			o.Warnings = append(o.Warnings, snippetReturnWarnings(t, ss)...)
+		}
+	}
+	return o
+}
+
+func containsSnippetType(types []SnippetType, t SnippetType) bool {
+	for _, tt := range types {
+		if tt == t {
+			return true
+		}
+	}
+	return false
+}
+
+// duplicateSnippetPriorities reports the priorities shared by several of the
+// ordered snippets ss.
+func duplicateSnippetPriorities(t SnippetType, ss []*Snippet) []*SnippetWarning {
+	var warnings []*SnippetWarning
+	for start := 0; start < len(ss); {
+		end := start + 1
+		for end < len(ss) && ss[end].Priority == ss[start].Priority {
+			end++
+		}
+		if end-start > 1 {
+			names := snippetNames(ss[start:end])
+			warnings = append(warnings, &SnippetWarning{
+				Kind:     SnippetWarningDuplicatePriority,
+				Type:     t,
+				Snippets: names,
+				Message:  fmt.Sprintf("%s snippets %s share priority %d and run in name order", t, strings.Join(names, ", "), ss[start].Priority),
+			})
+		}
+		start = end
+	}
+	return warnings
+}
+
+// snippetReturnWarnings reports the snippets of ss which never run because an
+// earlier one always returns, and different actions returned by the snippets.
+func snippetReturnWarnings(t SnippetType, ss []*Snippet) []*SnippetWarning {
+	var warnings []*SnippetWarning
+	var returning []string
+	actions := make(map[string][]string)
+	var order []string
+
+	for n, s := range ss {
+		if s.Content == "" {
+			continue
+		}
+		b, err := vcl.ParseStmts("snippet::"+s.Name, s.Content)
+		if err != nil {
+			warnings = append(warnings, &SnippetWarning{
+				Kind:     SnippetWarningInvalid,
+				Type:     t,
+				Snippets: []string{s.Name},
+				Message:  fmt.Sprintf("%s snippet %s cannot be parsed: %s", t, s.Name, strings.Replace(err.Error(), "\n", "; ", -1)),
+			})
+			continue
+		}
+
+		vcl.Inspect(b, func(node vcl.Node) bool {
+			if r, ok := node.(*vcl.ReturnStmt); ok {
+				if id, ok := r.Value.(*vcl.Ident); ok {
+					if _, seen := actions[id.Name]; !seen {
+						order = append(order, id.Name)
+					}
+					actions[id.Name] = appendName(actions[id.Name], s.Name)
+				}
+			}
+			return true
+		})
+
+		if returning == nil && alwaysReturns(b) {
+			returning = []string{s.Name}
+			if rest := ss[n+1:]; len(rest) > 0 {
+				returning = append(returning, snippetNames(rest)...)
+				warnings = append(warnings, &SnippetWarning{
+					Kind:     SnippetWarningUnreachable,
+					Type:     t,
+					Snippets: returning,
+					Message:  fmt.Sprintf("%s snippet %s always returns, so %s never run", t, s.Name, strings.Join(returning[1:], ", ")),
+				})
+			}
+		}
+	}
+
+	if len(order) > 1 {
+		var parts, names []string
+		for _, a := range order {
+			parts = append(parts, fmt.Sprintf("%s (%s)", a, strings.Join(actions[a], ", ")))
+			for _, name := range actions[a] {
+				names = appendName(names, name)
+			}
+		}
+		warnings = append(warnings, &SnippetWarning{
+			Kind:     SnippetWarningConflictingReturns,
+			Type:     t,
+			Snippets: names,
+			Message:  fmt.Sprintf("%s snippets return different actions: %s", t, strings.Join(parts, ", ")),
+		})
+	}
+	return warnings
+}
+
+// alwaysReturns reports whether a block returns whichever branch is taken.
+func alwaysReturns(b *vcl.Block) bool {
+	for _, s := range b.Stmts {
+		switch s := s.(type) {
+		case *vcl.ReturnStmt, *vcl.ErrorStmt, *vcl.RestartStmt:
+			return true
+		case *vcl.IfStmt:
+			if ifAlwaysReturns(s) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func ifAlwaysReturns(s *vcl.IfStmt) bool {
+	if !alwaysReturns(s.Then) {
+		return false
+	}
+	switch e := s.Else.(type) {
+	case *vcl.Block:
+		return alwaysReturns(e)
+	case *vcl.IfStmt:
+		return ifAlwaysReturns(e)
+	}
+	return false
+}
+
+func snippetNames(ss []*Snippet) []string {
+	names := make([]string, len(ss))
+	for n, s := range ss {
+		names[n] = s.Name
+	}
+	return names
+}
+
+// appendName appends name to names unless it is already present.
+func appendName(names []string, name string) []string {
+	for _, n := range names {
+		if n == name {
+			return names
+		}
+	}
+	return append(names, name)
+}
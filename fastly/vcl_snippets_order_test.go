@@ -0,0 +1,70 @@
+package fastly
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOrderSnippets(t *testing.T) {
+	t.Parallel()
+
+	snippets := []*Snippet{
+		{Name: "redirect", Type: SnippetTypeRecv, Priority: 100, Content: `if (req.url ~ "^/old") { error 801; }`},
+		{Name: "auth", Type: SnippetTypeRecv, Priority: 10, Content: `if (!req.http.Authorization) { return(pass); }`},
+		{Name: "api", Type: SnippetTypeRecv, Priority: 100, Content: `return(lookup);`},
+		{Name: "cleanup", Type: SnippetTypeRecv, Priority: 200, Content: `unset req.http.Cookie;`},
+		{Name: "tables", Type: SnippetTypeInit, Priority: 100, Content: `table t { "a": "b" }`},
+		{Name: "headers", Type: SnippetTypeDeliver, Priority: 100, Content: `set resp.http.X-Served = "1";`},
+		{Name: "broken", Type: SnippetTypeFetch, Priority: 100, Content: `set beresp.ttl = ;`},
+	}
+
+	o := OrderSnippets(snippets)
+
+	var types []string
+	for _, to := range o.Types {
+		types = append(types, string(to.Type)+": "+strings.Join(snippetNames(to.Snippets), ", "))
+	}
+	expected := "init: tables\nrecv: auth, api, redirect, cleanup\nfetch: broken\ndeliver: headers"
+	if got := strings.Join(types, "\n"); got != expected {
+		t.Errorf("bad order:\n%s\nexpected:\n%s", got, expected)
+	}
+
+	if got := snippetNames(o.Snippets(SnippetTypeRecv)); strings.Join(got, ",") != "auth,api,redirect,cleanup" {
+		t.Errorf("bad recv snippets: %v", got)
+	}
+	if got := o.Snippets(SnippetTypeHit); got != nil {
+		t.Errorf("expected no hit snippets, got %v", snippetNames(got))
+	}
+
+	var warnings []string
+	for _, w := range o.Warnings {
+		warnings = append(warnings, string(w.Kind)+": "+w.Message)
+	}
+	expected = strings.Join([]string{
+		"duplicate_priority: recv snippets api, redirect share priority 100 and run in name order",
+		"unreachable: recv snippet api always returns, so redirect, cleanup never run",
+		"conflicting_returns: recv snippets return different actions: pass (auth), lookup (api)",
+		"invalid: fetch snippet broken cannot be parsed: snippet::broken:1:18: unexpected \";\", expected expression",
+	}, "\n")
+	if got := strings.Join(warnings, "\n"); got != expected {
+		t.Errorf("bad warnings:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestOrderSnippets_ifElse(t *testing.T) {
+	t.Parallel()
+
+	o := OrderSnippets([]*Snippet{
+		{Name: "a", Type: SnippetTypeRecv, Priority: 1, Content: `if (req.http.A) { return(pass); } else { return(pass); }`},
+		{Name: "b", Type: SnippetTypeRecv, Priority: 2, Content: `set req.http.B = "1";`},
+		{Name: "dynamic", Type: SnippetTypeRecv, Priority: 3, Dynamic: 1},
+	})
+
+	if len(o.Warnings) != 1 {
+		t.Fatalf("expected 1 warning, got %d", len(o.Warnings))
+	}
+	w := o.Warnings[0]
+	if w.Kind != SnippetWarningUnreachable || strings.Join(w.Snippets, ",") != "a,b,dynamic" {
+		t.Errorf("bad warning: %s %v", w.Kind, w.Snippets)
+	}
+}
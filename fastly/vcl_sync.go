@@ -0,0 +1,266 @@
+package fastly
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fastly/go-fastly/v3/fastly/vcl"
+)
+
+// VCLFileExtension is the extension of the files read by SyncVCLs.
+const VCLFileExtension = ".vcl"
+
+// SyncVCLsInput is used as input to the SyncVCLs function.
+type SyncVCLsInput struct {
+	// ServiceID is the ID of the service (required).
+	ServiceID string
+
+	// ServiceVersion is the editable configuration version (required).
+	ServiceVersion int
+
+	// Dir is a directory holding the VCL files. Every file with the .vcl
+	// extension is uploaded, named after the file without the extension.
+	// Subdirectories are ignored.
+	Dir string
+
+	// Files are VCLs to upload in addition to those in Dir, by name.
+	Files map[string]string
+
+	// Main is the name of the VCL to mark as main (required).
+	Main string
+
+	// DryRun computes the changes without applying them.
+	DryRun bool
+}
+
+// SyncVCLsResult reports the changes made by SyncVCLs. Names are sorted.
+type SyncVCLsResult struct {
+	Created   []string
+	Updated   []string
+	Deleted   []string
+	Unchanged []string
+
+	// MainChanged reports whether Main was not the main VCL before.
+	MainChanged bool
+
+	// Unreferenced are the uploaded VCLs which Main does not include,
+	// directly or indirectly.
+	Unreferenced []string
+}
+
+// SyncVCLs makes the VCLs of a service version match a bundle of files, such
+// as a main VCL and the files it includes.
+//
+// Before anything is uploaded, every file is parsed and the include
+// statements are resolved, so that syntax errors and includes of VCLs missing
+// from the bundle are reported as a vcl.ErrorList. Includes of snippets, such
+// as `include "snippet::name";`, are not checked.
+//
+// VCLs missing from the version are created and those whose content differs
+// are updated, then Main is marked as main and VCLs not in the bundle are
+// deleted.
+func (c *Client) SyncVCLs(i *SyncVCLsInput) (*SyncVCLsResult, error) {
+	if i.ServiceID == "" {
+		return nil, ErrMissingServiceID
+	}
+
+	if i.ServiceVersion == 0 {
+		return nil, ErrMissingServiceVersion
+	}
+
+	if i.Dir == "" && len(i.Files) == 0 {
+		return nil, ErrMissingFiles
+	}
+
+	if i.Main == "" {
+		return nil, ErrMissingMain
+	}
+
+	files, err := readVCLFiles(i.Dir, i.Files)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := files[i.Main]; !ok {
+		return nil, NewFieldError("Main").Message(fmt.Sprintf("VCL %q is not in the bundle", i.Main))
+	}
+
+	result := &SyncVCLsResult{}
+	result.Unreferenced, err = resolveVCLIncludes(files, i.Main)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := c.ListVCLs(&ListVCLsInput{ServiceID: i.ServiceID, ServiceVersion: i.ServiceVersion})
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]*VCL, len(current))
+	mainChanged := true
+	for _, v := range current {
+		existing[v.Name] = v
+		if v.Name == i.Main && v.Main {
+			mainChanged = false
+		}
+	}
+
+	for _, name := range sortedKeys(files) {
+		v, ok := existing[name]
+		switch {
+		case !ok:
+			result.Created = append(result.Created, name)
+		case v.Content != files[name]:
+			result.Updated = append(result.Updated, name)
+		default:
+			result.Unchanged = append(result.Unchanged, name)
+		}
+	}
+	for _, v := range current {
+		if _, ok := files[v.Name]; !ok {
+			result.Deleted = append(result.Deleted, v.Name)
+		}
+	}
+	sort.Strings(result.Deleted)
+	result.MainChanged = mainChanged
+
+	if i.DryRun {
+		return result, nil
+	}
+
+	for _, name := range result.Created {
+		_, err := c.CreateVCL(&CreateVCLInput{
+			ServiceID:      i.ServiceID,
+			ServiceVersion: i.ServiceVersion,
+			Name:           name,
+			Content:        files[name],
+			Main:           name == i.Main,
+		})
+		if err != nil {
+			return result, fmt.Errorf("creating VCL %q: %s", name, err)
+		}
+		if name == i.Main {
+			mainChanged = false
+		}
+	}
+
+	for _, name := range result.Updated {
+		_, err := c.UpdateVCL(&UpdateVCLInput{
+			ServiceID:      i.ServiceID,
+			ServiceVersion: i.ServiceVersion,
+			Name:           name,
+			Content:        String(files[name]),
+		})
+		if err != nil {
+			return result, fmt.Errorf("updating VCL %q: %s", name, err)
+		}
+	}
+
+	if mainChanged {
+		_, err := c.ActivateVCL(&ActivateVCLInput{
+			ServiceID:      i.ServiceID,
+			ServiceVersion: i.ServiceVersion,
+			Name:           i.Main,
+		})
+		if err != nil {
+			return result, fmt.Errorf("marking VCL %q as main: %s", i.Main, err)
+		}
+	}
+
+	for _, name := range result.Deleted {
+		err := c.DeleteVCL(&DeleteVCLInput{
+			ServiceID:      i.ServiceID,
+			ServiceVersion: i.ServiceVersion,
+			Name:           name,
+		})
+		if err != nil {
+			return result, fmt.Errorf("deleting VCL %q: %s", name, err)
+		}
+	}
+	return result, nil
+}
+
+// readVCLFiles returns the VCL files of dir, if set, and files by name.
+func readVCLFiles(dir string, files map[string]string) (map[string]string, error) {
+	all := make(map[string]string, len(files))
+	if dir != "" {
+		infos, err := ioutil.ReadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, fi := range infos {
+			if !fi.Mode().IsRegular() || filepath.Ext(fi.Name()) != VCLFileExtension {
+				continue
+			}
+			b, err := ioutil.ReadFile(filepath.Join(dir, fi.Name()))
+			if err != nil {
+				return nil, err
+			}
+			all[strings.TrimSuffix(fi.Name(), VCLFileExtension)] = string(b)
+		}
+	}
+	for name, content := range files {
+		all[name] = content
+	}
+	return all, nil
+}
+
+// resolveVCLIncludes parses files and checks that every include names one of
+// them. It returns the files not reachable from main.
+func resolveVCLIncludes(files map[string]string, main string) ([]string, error) {
+	var errs vcl.ErrorList
+	includes := make(map[string][]string, len(files))
+	for _, name := range sortedKeys(files) {
+		f, err := vcl.Parse(name, files[name])
+		if err != nil {
+			errs = append(errs, err.(vcl.ErrorList)...)
+		}
+		vcl.Inspect(f, func(n vcl.Node) bool {
+			inc, ok := n.(*vcl.IncludeDecl)
+			if !ok || strings.HasPrefix(inc.Path, "snippet::") {
+				return true
+			}
+			if _, ok := files[inc.Path]; !ok {
+				errs = append(errs, &vcl.Error{File: name, Pos: inc.At, Msg: fmt.Sprintf("include of unknown VCL %q", inc.Path)})
+			}
+			includes[name] = append(includes[name], inc.Path)
+			return true
+		})
+	}
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+
+	reached := map[string]bool{main: true}
+	queue := []string{main}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		for _, inc := range includes[name] {
+			if !reached[inc] {
+				reached[inc] = true
+				queue = append(queue, inc)
+			}
+		}
+	}
+
+	var unreferenced []string
+	for _, name := range sortedKeys(files) {
+		if !reached[name] {
+			unreferenced = append(unreferenced, name)
+		}
+	}
+	return unreferenced, nil
+}
+
+// sortedKeys returns the keys of m in order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
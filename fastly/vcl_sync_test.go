@@ -0,0 +1,153 @@
+package fastly
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fastly/go-fastly/v3/fastly/vcl"
+)
+
+var testVCLBundle = map[string]string{
+	"main.vcl":      "include \"shared\";\ninclude \"redirects\";\nsub vcl_recv {\n#FASTLY recv\n  call normalize;\n  return(lookup);\n}\n",
+	"shared.vcl":    "sub normalize {\n  set req.url = std.tolower(req.url);\n}\n",
+	"redirects.vcl": "table redirects {\n  \"/old\": \"/new\",\n}\n",
+	"README.md":     "not VCL",
+}
+
+func TestClient_SyncVCLs(t *testing.T) {
+	t.Parallel()
+
+	dir := writeTestFiles(t, testVCLBundle)
+
+	var result *SyncVCLsResult
+	var err error
+	record(t, "vcls/sync", func(c *Client) {
+		result, err = c.SyncVCLs(&SyncVCLsInput{
+			ServiceID:      testServiceID,
+			ServiceVersion: 5,
+			Dir:            dir,
+			Main:           "main",
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &SyncVCLsResult{
+		Created:     []string{"redirects"},
+		Updated:     []string{"main"},
+		Deleted:     []string{"legacy"},
+		Unchanged:   []string{"shared"},
+		MainChanged: true,
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("bad result: %+v", result)
+	}
+}
+
+func TestClient_SyncVCLs_dryRun(t *testing.T) {
+	t.Parallel()
+
+	var result *SyncVCLsResult
+	var err error
+	record(t, "vcls/sync_dry_run", func(c *Client) {
+		result, err = c.SyncVCLs(&SyncVCLsInput{
+			ServiceID:      testServiceID,
+			ServiceVersion: 5,
+			Files: map[string]string{
+				"main":   "include \"shared\";\ninclude \"snippet::extra\";\n",
+				"shared": "sub normalize {\n  set req.url = std.tolower(req.url);\n}\n",
+				"unused": "sub unused { }\n",
+			},
+			Main:   "main",
+			DryRun: true,
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &SyncVCLsResult{
+		Created:      []string{"unused"},
+		Updated:      []string{"main"},
+		Deleted:      []string{"legacy"},
+		Unchanged:    []string{"shared"},
+		MainChanged:  true,
+		Unreferenced: []string{"unused"},
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("bad result: %+v", result)
+	}
+}
+
+func TestClient_SyncVCLs_validation(t *testing.T) {
+	var err error
+	_, err = testClient.SyncVCLs(&SyncVCLsInput{
+		ServiceID: "",
+	})
+	if err != ErrMissingServiceID {
+		t.Errorf("bad error: %s", err)
+	}
+
+	_, err = testClient.SyncVCLs(&SyncVCLsInput{
+		ServiceID:      "foo",
+		ServiceVersion: 0,
+	})
+	if err != ErrMissingServiceVersion {
+		t.Errorf("bad error: %s", err)
+	}
+
+	_, err = testClient.SyncVCLs(&SyncVCLsInput{
+		ServiceID:      "foo",
+		ServiceVersion: 1,
+	})
+	if err != ErrMissingFiles {
+		t.Errorf("bad error: %s", err)
+	}
+
+	_, err = testClient.SyncVCLs(&SyncVCLsInput{
+		ServiceID:      "foo",
+		ServiceVersion: 1,
+		Files:          map[string]string{"main": ""},
+	})
+	if err != ErrMissingMain {
+		t.Errorf("bad error: %s", err)
+	}
+
+	_, err = testClient.SyncVCLs(&SyncVCLsInput{
+		ServiceID:      "foo",
+		ServiceVersion: 1,
+		Files:          map[string]string{"boot": ""},
+		Main:           "main",
+	})
+	if _, ok := err.(*FieldError); !ok {
+		t.Errorf("bad error: %s", err)
+	}
+
+	// Syntax errors and unresolved includes are reported before any request.
+	_, err = testClient.SyncVCLs(&SyncVCLsInput{
+		ServiceID:      "foo",
+		ServiceVersion: 1,
+		Files: map[string]string{
+			"main":   "include \"shared\";\ninclude \"missing\";\n",
+			"shared": "sub broken {\n  set req.url;\n}\n",
+		},
+		Main: "main",
+	})
+	el, ok := err.(vcl.ErrorList)
+	if !ok {
+		t.Fatalf("expected a vcl.ErrorList, got %v", err)
+	}
+	want := []string{
+		`main:2:1: include of unknown VCL "missing"`,
+		`shared:2:14: unexpected ";", expected assignment operator`,
+	}
+	var got []string
+	for _, e := range el {
+		got = append(got, e.Error())
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("bad errors:\n%s", strings.Join(got, "\n"))
+	}
+}
@@ -2,7 +2,10 @@ package fastly
 
 import (
 	"fmt"
+	"regexp"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -315,6 +318,152 @@ func (c *Client) ValidateVersion(i *ValidateVersionInput) (bool, string, error)
 	return r.Ok(), msg, nil
 }
 
+// ValidationMessage is a single error or warning reported when validating a
+// version.
+type ValidationMessage struct {
+	// Message is the text reported by the API.
+	Message string
+
+	// VCL is the name of the VCL the message refers to, if any.
+	VCL string
+
+	// Line and Position locate the problem within VCL, if known.
+	Line     int
+	Position int
+}
+
+// VersionValidation is the structured result of validating a version.
+type VersionValidation struct {
+	Status   string
+	Message  string
+	Errors   []*ValidationMessage
+	Warnings []*ValidationMessage
+}
+
+// Valid reports whether the version passed validation.
+func (v *VersionValidation) Valid() bool {
+	return v.Status == "ok" && len(v.Errors) == 0
+}
+
+// validateResp is the raw response of the validate endpoint. Errors and
+// warnings are usually strings, while messages are objects carrying a type.
+type validateResp struct {
+	Status   string                   `mapstructure:"status"`
+	Msg      string                   `mapstructure:"msg"`
+	Errors   []interface{}            `mapstructure:"errors"`
+	Warnings []interface{}            `mapstructure:"warnings"`
+	Messages []map[string]interface{} `mapstructure:"messages"`
+}
+
+// vclLocationRegexp matches the location suffix the VCL compiler appends to
+// its messages, e.g. "('main.vcl' Line 3 Pos 5)".
+var vclLocationRegexp = regexp.MustCompile(`'([^']*)' Line (\d+) Pos (\d+)`)
+
+// newValidationMessage builds a ValidationMessage from a raw API value,
+// extracting the VCL location when the message contains one.
+func newValidationMessage(v interface{}) *ValidationMessage {
+	var msg string
+	switch t := v.(type) {
+	case string:
+		msg = t
+	case map[string]interface{}:
+		for _, k := range []string{"msg", "message", "detail"} {
+			if s, ok := t[k].(string); ok && s != "" {
+				msg = s
+				break
+			}
+		}
+	default:
+		msg = fmt.Sprint(v)
+	}
+
+	m := &ValidationMessage{Message: strings.TrimSpace(msg)}
+	if loc := vclLocationRegexp.FindStringSubmatch(msg); loc != nil {
+		m.VCL = loc[1]
+		m.Line, _ = strconv.Atoi(loc[2])
+		m.Position, _ = strconv.Atoi(loc[3])
+	} else if t, ok := v.(map[string]interface{}); ok {
+		// Typed messages may carry the location as separate fields.
+		m.VCL, _ = t["vcl"].(string)
+		if line, ok := t["line"].(float64); ok {
+			m.Line = int(line)
+		}
+		if pos, ok := t["pos"].(float64); ok {
+			m.Position = int(pos)
+		}
+	}
+	return m
+}
+
+// validationMessageKey identifies a ValidationMessage when removing
+// duplicates: the same message may be reported at several locations.
+type validationMessageKey struct {
+	message string
+	vcl     string
+	line    int
+}
+
+// newVersionValidation converts the raw response into a VersionValidation,
+// merging typed entries from "messages" into the error and warning lists.
+func newVersionValidation(r *validateResp) *VersionValidation {
+	v := &VersionValidation{
+		Status:  r.Status,
+		Message: r.Msg,
+	}
+
+	seenErrors := make(map[validationMessageKey]bool)
+	seenWarnings := make(map[validationMessageKey]bool)
+	add := func(list *[]*ValidationMessage, seen map[validationMessageKey]bool, raw interface{}) {
+		m := newValidationMessage(raw)
+		key := validationMessageKey{message: m.Message, vcl: m.VCL, line: m.Line}
+		if m.Message == "" || seen[key] {
+			return
+		}
+		seen[key] = true
+		*list = append(*list, m)
+	}
+
+	for _, e := range r.Errors {
+		add(&v.Errors, seenErrors, e)
+	}
+	for _, w := range r.Warnings {
+		add(&v.Warnings, seenWarnings, w)
+	}
+	for _, m := range r.Messages {
+		switch t, _ := m["type"].(string); strings.ToLower(t) {
+		case "error":
+			add(&v.Errors, seenErrors, m)
+		case "warning":
+			add(&v.Warnings, seenWarnings, m)
+		}
+	}
+	return v
+}
+
+// ValidateVersionDetailed validates the given version and returns the errors
+// and warnings reported by the API as separate lists.
+func (c *Client) ValidateVersionDetailed(i *ValidateVersionInput) (*VersionValidation, error) {
+	if i.ServiceID == "" {
+		return nil, ErrMissingServiceID
+	}
+
+	if i.ServiceVersion == 0 {
+		return nil, ErrMissingServiceVersion
+	}
+
+	path := fmt.Sprintf("/service/%s/version/%d/validate", i.ServiceID, i.ServiceVersion)
+	resp, err := c.Get(path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var r *validateResp
+	if err := decodeBodyMap(resp.Body, &r); err != nil {
+		return nil, err
+	}
+	return newVersionValidation(r), nil
+}
+
 // LockVersionInput is the input to the LockVersion function.
 type LockVersionInput struct {
 	// ServiceID is the ID of the service (required).
@@ -1,7 +1,9 @@
 package fastly
 
 import (
+	"fmt"
 	"sort"
+	"strings"
 	"testing"
 )
 
@@ -258,6 +260,92 @@ func TestClient_ValidateVersion_validation(t *testing.T) {
 	}
 }
 
+func TestClient_ValidateVersionDetailed(t *testing.T) {
+	t.Parallel()
+
+	var err error
+	var v *VersionValidation
+	record(t, "versions/validate_detailed", func(c *Client) {
+		v, err = c.ValidateVersionDetailed(&ValidateVersionInput{
+			ServiceID:      testServiceID,
+			ServiceVersion: 17,
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Valid() {
+		t.Errorf("bad valid: %t", v.Valid())
+	}
+	if len(v.Errors) != 1 {
+		t.Fatalf("bad errors: %#v", v.Errors)
+	}
+	if e := v.Errors[0]; e.VCL != "main.vcl" || e.Line != 3 || e.Position != 5 {
+		t.Errorf("bad error location: %#v", e)
+	}
+	if len(v.Warnings) != 2 {
+		t.Fatalf("bad warnings: %#v", v.Warnings)
+	}
+	if w := v.Warnings[1].Message; w != "Unused ACL 'internal'" {
+		t.Errorf("bad warning: %q", w)
+	}
+}
+
+func TestNewVersionValidation(t *testing.T) {
+	t.Parallel()
+
+	v := newVersionValidation(&validateResp{
+		Status:   "error",
+		Errors:   []interface{}{"Unknown variable ('main.vcl' Line 3 Pos 5)"},
+		Warnings: []interface{}{"Deprecated function"},
+		Messages: []map[string]interface{}{
+			// Repeats the error above.
+			{"type": "error", "msg": "Unknown variable ('main.vcl' Line 3 Pos 5)"},
+			// The same messages at other lines, and an error also reported
+			// as a warning.
+			{"type": "warning", "msg": "Deprecated function", "vcl": "main.vcl", "line": float64(4), "pos": float64(1)},
+			{"type": "warning", "msg": "Deprecated function", "vcl": "main.vcl", "line": float64(9), "pos": float64(1)},
+			{"type": "warning", "msg": "Deprecated function", "vcl": "main.vcl", "line": float64(9), "pos": float64(1)},
+			{"type": "warning", "msg": "Unknown variable ('main.vcl' Line 3 Pos 5)"},
+		},
+	})
+
+	if len(v.Errors) != 1 {
+		t.Fatalf("bad errors: %#v", v.Errors)
+	}
+	var warnings []string
+	for _, w := range v.Warnings {
+		warnings = append(warnings, fmt.Sprintf("%s %s:%d:%d", w.Message, w.VCL, w.Line, w.Position))
+	}
+	expected := []string{
+		"Deprecated function :0:0",
+		"Deprecated function main.vcl:4:1",
+		"Deprecated function main.vcl:9:1",
+		"Unknown variable ('main.vcl' Line 3 Pos 5) main.vcl:3:5",
+	}
+	if strings.Join(warnings, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("bad warnings: %q", warnings)
+	}
+}
+
+func TestClient_ValidateVersionDetailed_validation(t *testing.T) {
+	var err error
+	_, err = testClient.ValidateVersionDetailed(&ValidateVersionInput{
+		ServiceID: "",
+	})
+	if err != ErrMissingServiceID {
+		t.Errorf("bad error: %s", err)
+	}
+
+	_, err = testClient.ValidateVersionDetailed(&ValidateVersionInput{
+		ServiceID:      "foo",
+		ServiceVersion: 0,
+	})
+	if err != ErrMissingServiceVersion {
+		t.Errorf("bad error: %s", err)
+	}
+}
+
 func TestClient_LockVersion_validation(t *testing.T) {
 	var err error
 	_, err = testClient.LockVersion(&LockVersionInput{